@@ -0,0 +1,22 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS apps (
+    id BIGINT NOT NULL PRIMARY KEY,
+    client_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)),
+    updated_at BIGINT NOT NULL DEFAULT (CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)),
+
+    -- ensure that this only one record that not deleted
+    deleted_at BIGINT NOT NULL DEFAULT 0,
+
+    -- client_id is always saved in lower case, and the default collation is case-insensitive,
+    -- so LOWER(client_id) index like in postgres is not needed.
+    UNIQUE KEY idx_unique_apps_client_id_deleted (client_id, deleted_at)
+);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS apps;
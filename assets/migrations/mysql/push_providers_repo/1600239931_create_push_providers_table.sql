@@ -0,0 +1,22 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Unlike postgres, this table is not partitioned by app_id. MySQL cannot add list partition on the fly,
+-- so the index (app_id, provider, label) is used instead.
+CREATE TABLE IF NOT EXISTS push_providers (
+    id BIGINT NOT NULL,
+    app_id BIGINT NOT NULL,
+    provider VARCHAR(64) NOT NULL, -- fcm, apns, email
+    label VARCHAR(255) NOT NULL, -- name of service provider config, i.e: app-driver, app-consumer
+    credential_json JSON NOT NULL, -- credential based on service_provider type
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)),
+    updated_at BIGINT NOT NULL DEFAULT (CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)),
+
+    CONSTRAINT push_providers_pkey PRIMARY KEY (id, app_id),
+    INDEX idx_push_providers_app_provider_label (app_id, provider, label)
+);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS push_providers;
@@ -0,0 +1,13 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- day is the start of the day in unix microsecond UTC, total is the number of send request on that day
+CREATE TABLE IF NOT EXISTS app_daily_usages (
+    app_id BIGINT NOT NULL,
+    day BIGINT NOT NULL,
+    total BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (app_id, day)
+);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS app_daily_usages;
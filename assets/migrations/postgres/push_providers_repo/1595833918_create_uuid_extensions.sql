@@ -4,4 +4,4 @@ CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 -- +migrate Down
 -- SQL section 'Down' is executed when this migration is rolled back
-DROP EXTENSION IF NOT EXISTS "uuid-ossp";
+-- the extension is not dropped, because it is shared by other services in the same database
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- providers is soft deleted together with the app, so it can be restored within the retention window
+ALTER TABLE push_providers ADD COLUMN IF NOT EXISTS deleted_at BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE push_providers DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,22 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS message_templates (
+    id BIGINT NOT NULL PRIMARY KEY,
+    app_id BIGINT NOT NULL,
+    name VARCHAR NOT NULL,
+    engine VARCHAR NOT NULL DEFAULT 'text', -- text or html, refer to Go text/template or html/template
+    default_locale VARCHAR NOT NULL DEFAULT 'en',
+    variants_json JSONB NOT NULL DEFAULT '{}', -- locale => provider => payload skeleton
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (EXTRACT(EPOCH FROM now()) * 1000000),
+    updated_at BIGINT NOT NULL DEFAULT (EXTRACT(EPOCH FROM now()) * 1000000),
+    deleted_at BIGINT NOT NULL DEFAULT 0
+);
+
+-- only one not deleted template with the same name per app
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_message_templates_app_name_deleted ON message_templates (app_id, LOWER(name), deleted_at);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS message_templates;
@@ -0,0 +1,19 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS topic_subscriptions (
+    id BIGINT NOT NULL PRIMARY KEY,
+    app_id BIGINT NOT NULL,
+    topic VARCHAR NOT NULL,
+    member_type VARCHAR NOT NULL, -- user: resolved using device registry, recipient: provider specific recipient
+    provider VARCHAR NOT NULL DEFAULT '', -- empty for member_type user
+    member VARCHAR NOT NULL, -- user id or recipient, i.e: fcm token, email address, webhook url
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (EXTRACT(EPOCH FROM now()) * 1000000)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_topic_subscriptions_member ON topic_subscriptions (app_id, LOWER(topic), member_type, provider, member);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS topic_subscriptions;
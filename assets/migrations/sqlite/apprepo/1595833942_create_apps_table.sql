@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS apps (
+    id BIGINT NOT NULL PRIMARY KEY,
+    client_id VARCHAR NOT NULL COLLATE NOCASE,
+    name VARCHAR NOT NULL DEFAULT '',
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000),
+    updated_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000),
+
+    -- ensure that this only one record that not deleted
+    deleted_at BIGINT NOT NULL DEFAULT 0
+);
+
+-- client_id is case-insensitive using NOCASE collation, so it can be used as upsert conflict target
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_apps_client_id_deleted ON apps (client_id, deleted_at);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS apps;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- suspended app (enabled = false) cannot send message until it is resumed
+ALTER TABLE apps ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE apps ADD COLUMN suspended_reason VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE apps ADD COLUMN suspended_at BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE apps DROP COLUMN suspended_at;
+ALTER TABLE apps DROP COLUMN suspended_reason;
+ALTER TABLE apps DROP COLUMN enabled;
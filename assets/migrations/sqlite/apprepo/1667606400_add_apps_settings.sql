@@ -0,0 +1,8 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- settings is apprepo.Settings in JSON, empty object means no setting is defined
+ALTER TABLE apps ADD COLUMN settings TEXT NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE apps DROP COLUMN settings;
@@ -0,0 +1,39 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS callbacks (
+    id BIGINT NOT NULL PRIMARY KEY,
+    app_id BIGINT NOT NULL,
+    url VARCHAR NOT NULL,
+    secret VARCHAR NOT NULL, -- used to sign the payload using HMAC SHA256
+    events VARCHAR NOT NULL, -- comma separated event types, i.e: task.completed,provider.failed
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000),
+    updated_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_callbacks_app ON callbacks (app_id);
+
+CREATE TABLE IF NOT EXISTS callback_deliveries (
+    id BIGINT NOT NULL PRIMARY KEY,
+    callback_id BIGINT NOT NULL REFERENCES callbacks (id) ON DELETE CASCADE,
+    app_id BIGINT NOT NULL,
+    event VARCHAR NOT NULL,
+    task_id VARCHAR NOT NULL DEFAULT '',
+    payload TEXT NOT NULL, -- JSON body sent to the callback url
+    status VARCHAR NOT NULL, -- pending, success, failed
+    attempts INT NOT NULL DEFAULT 0,
+    response_code INT NOT NULL DEFAULT 0, -- HTTP status code of the last attempt
+    last_error TEXT NOT NULL DEFAULT '',
+
+    -- using unix microsecond to make it easier to migrate between db
+    created_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000),
+    updated_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_callback_deliveries_app ON callback_deliveries (app_id, id);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS callback_deliveries;
+DROP TABLE IF EXISTS callbacks;
@@ -0,0 +1,24 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS devices (
+    id BIGINT NOT NULL PRIMARY KEY,
+    app_id BIGINT NOT NULL,
+    user_id VARCHAR NOT NULL, -- external user id, defined by the app
+    provider VARCHAR NOT NULL, -- fcm, apns, email
+    token VARCHAR NOT NULL, -- provider specific recipient, i.e: fcm registration token
+    platform VARCHAR NOT NULL DEFAULT '', -- android, ios, web
+    locale VARCHAR NOT NULL DEFAULT '',
+
+    -- using unix microsecond to make it easier to migrate between db
+    last_seen_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000),
+    created_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000),
+    updated_at BIGINT NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000000)
+);
+
+-- one token only belongs to one user in the same app and provider, re-register will move the token to the new user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_devices_app_provider_token ON devices (app_id, provider, token);
+CREATE INDEX IF NOT EXISTS idx_devices_app_user ON devices (app_id, user_id);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS devices;
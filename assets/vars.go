@@ -10,4 +10,8 @@ const ServiceName = "ngendika"
 var (
 	//go:embed swaggerui/*
 	SwaggerUI embed.FS
+
+	// Migrations contains SQL migration per driver and repository, i.e: migrations/postgres/apprepo/*.sql
+	//go:embed migrations
+	Migrations embed.FS
 )
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"firebase.google.com/go/v4/messaging"
 	"fmt"
 	"github.com/yusufsyaifudin/ngendika/backend"
 	"github.com/yusufsyaifudin/ngendika/pkg/fcm"
@@ -13,11 +14,16 @@ import (
 	"net/http"
 )
 
+// MaxMulticastTokens is the maximum registration tokens in one FCM multicast message.
+const MaxMulticastTokens = 500
+
 type Backend struct {
 	Client fcm.Client
 }
 
 var _ backend.Sender = (*Backend)(nil)
+var _ backend.RecipientLimiter = (*Backend)(nil)
+var _ backend.RecipientExtractor = (*Backend)(nil)
 
 func NewBE(httpRoundTripper http.RoundTripper) (*Backend, error) {
 	if httpRoundTripper == nil {
@@ -80,12 +86,20 @@ func (b *Backend) Send(ctx context.Context, workerID int, serviceProvider backen
 		return
 	}
 
+	invalidRecipients := make([]string, 0)
+	for _, resp := range out.BatchResponse.Responses {
+		if resp.InvalidToken {
+			invalidRecipients = append(invalidRecipients, resp.DeviceToken)
+		}
+	}
+
 	report = &backend.Report{
-		ReferenceID:    msg.ReferenceID,
-		WorkerID:       workerID,
-		SuccessCount:   out.BatchResponse.SuccessCount,
-		FailureCount:   out.BatchResponse.FailureCount,
-		NativeResponse: out,
+		ReferenceID:       msg.ReferenceID,
+		WorkerID:          workerID,
+		SuccessCount:      out.BatchResponse.SuccessCount,
+		FailureCount:      out.BatchResponse.FailureCount,
+		NativeResponse:    out,
+		InvalidRecipients: invalidRecipients,
 	}
 
 	return
@@ -142,10 +156,60 @@ func (b *Backend) ValidateMsg(ctx context.Context, msg *backend.Message) (messag
 		return
 	}
 
+	fcmMsg.Tokens = append(fcmMsg.Tokens, msg.Recipients...)
+
+	// only android config has time to live in the multicast message
+	if msg.TTL > 0 && (fcmMsg.Android == nil || fcmMsg.Android.TTL == nil) {
+		if fcmMsg.Android == nil {
+			fcmMsg.Android = &messaging.AndroidConfig{}
+		}
+
+		ttl := msg.TTL
+		fcmMsg.Android.TTL = &ttl
+	}
+
 	message = fcmMsg
 	return
 }
 
+func (b *Backend) MaxRecipients() int {
+	return MaxMulticastTokens
+}
+
+// ExtractRecipients move the payload tokens out, so it is merged with the resolved recipients
+// and each multicast message never exceed MaxMulticastTokens.
+func (b *Backend) ExtractRecipients(_ context.Context, rawPayload any) (recipients []string, payload any, err error) {
+	dataByte, err := json.Marshal(rawPayload)
+	if err != nil {
+		err = fmt.Errorf("we assume you input payload as json valid object, but it failed to marshal: %w", err)
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(dataByte, &fields)
+	if err != nil {
+		err = fmt.Errorf("malformed fcm multicast payload: %w", err)
+		return
+	}
+
+	payload = rawPayload
+
+	tokens, ok := fields["tokens"]
+	if !ok {
+		return
+	}
+
+	err = json.Unmarshal(tokens, &recipients)
+	if err != nil {
+		err = fmt.Errorf("malformed fcm multicast tokens: %w", err)
+		return
+	}
+
+	delete(fields, "tokens")
+	payload = fields
+	return
+}
+
 func (b *Backend) Example(_ context.Context) (credNative, message any) {
 	credNative = fcm.ServiceAccountKey{
 		Type:                    "",
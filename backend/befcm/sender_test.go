@@ -1,9 +1,12 @@
 package befcm
 
 import (
+	"context"
 	"firebase.google.com/go/v4/messaging"
+	"github.com/yusufsyaifudin/ngendika/backend"
 	"github.com/yusufsyaifudin/ngendika/pkg/fcm"
 	"testing"
+	"time"
 )
 
 func BenchmarkGolangTypeCoercion(b *testing.B) {
@@ -57,3 +60,23 @@ func BenchmarkGolangTypeCoercionSwitch(b *testing.B) {
 
 	}
 }
+
+func TestBackend_ValidateMsgTTL(t *testing.T) {
+	b := &Backend{}
+	validate := func(payload map[string]any) *time.Duration {
+		message, err := b.ValidateMsg(context.Background(), &backend.Message{ReferenceID: "1", RawPayload: payload, TTL: time.Hour})
+		if err != nil {
+			t.Fatalf("validate message error: %s", err)
+		}
+
+		return message.(fcm.MulticastMessage).Android.TTL
+	}
+
+	if ttl := validate(map[string]any{"data": map[string]string{"k": "v"}}); ttl == nil || *ttl != time.Hour {
+		t.Errorf("default ttl is not applied, got %v", ttl)
+	}
+
+	if ttl := validate(map[string]any{"android": map[string]any{"ttl": "60s"}}); ttl == nil || *ttl != time.Minute {
+		t.Errorf("payload ttl is overridden, got %v", ttl)
+	}
+}
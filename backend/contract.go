@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 var (
@@ -25,6 +26,23 @@ type Sender interface {
 	Example(ctx context.Context) (credNative, message any)
 }
 
+// RecipientLimiter is an optional interface for Sender that limits the number of Message.Recipients in one Send,
+// i.e: FCM multicast only accept 500 tokens. The caller must split the recipients into multiple messages.
+type RecipientLimiter interface {
+	MaxRecipients() int
+}
+
+// RecipientExtractor is an optional interface for Sender to move the recipients defined in the payload
+// (i.e: FCM tokens) out of it, so the caller can merge them with Message.Recipients before splitting by RecipientLimiter.
+type RecipientExtractor interface {
+	ExtractRecipients(ctx context.Context, rawPayload any) (recipients []string, payload any, err error)
+}
+
+// HealthChecker is an optional interface for Sender to check its own dependencies, i.e: upstream API reachability.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
 // SenderMux used by internal application to route to the specific Sender based on provider passed in the params.
 type SenderMux interface {
 
@@ -42,11 +60,30 @@ type SenderMux interface {
 
 	// ListProviders will return all available providers registered in global Backend SenderMux
 	ListProviders(ctx context.Context) (providers []string)
+
+	// MaxRecipients return maximum recipients in one message for the provider, zero means unlimited.
+	MaxRecipients(ctx context.Context, provider string) (max int)
+
+	// ExtractRecipients return the recipients defined in the payload and the payload without them.
+	// Provider that not implement RecipientExtractor return the payload as is.
+	ExtractRecipients(ctx context.Context, provider string, rawPayload any) (recipients []string, payload any, err error)
+
+	// HealthCheck run self-check of the provider, nil error means healthy.
+	// Registered provider that not implement HealthChecker is always healthy.
+	HealthCheck(ctx context.Context, provider string) (err error)
 }
 
 type Message struct {
 	ReferenceID string      `validate:"required"`
 	RawPayload  interface{} `validate:"required"`
+
+	// Recipients is provider specific recipients (i.e: FCM registration token) resolved by ngendika.
+	// Sender must merge this into the recipients defined in RawPayload.
+	Recipients []string `validate:"-"`
+
+	// TTL is the default time to live from the app settings, zero means not set.
+	// Sender must only use it when RawPayload has no its own time to live.
+	TTL time.Duration `validate:"-"`
 }
 
 // Report is a struct that hold the report
@@ -56,6 +93,9 @@ type Report struct {
 	SuccessCount   int    `json:"success_count"`
 	FailureCount   int    `json:"failure_count"`
 	NativeResponse any    `json:"native_response"`
+
+	// InvalidRecipients is recipients reported by the provider as no longer valid, i.e: unregistered FCM token.
+	InvalidRecipients []string `json:"invalid_recipients,omitempty"`
 }
 
 type Example struct {
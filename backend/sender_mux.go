@@ -167,3 +167,60 @@ func (s *SenderMultiplexer) ListProviders(_ context.Context) (providers []string
 
 	return
 }
+
+func (s *SenderMultiplexer) MaxRecipients(_ context.Context, provider string) (max int) {
+	beMux.lock.RLock()
+	defer beMux.lock.RUnlock()
+
+	client, exist := s.sender[provider]
+	if !exist {
+		return
+	}
+
+	limiter, ok := client.(RecipientLimiter)
+	if !ok {
+		return
+	}
+
+	max = limiter.MaxRecipients()
+	return
+}
+
+func (s *SenderMultiplexer) ExtractRecipients(ctx context.Context, provider string, rawPayload any) (recipients []string, payload any, err error) {
+	beMux.lock.RLock()
+	defer beMux.lock.RUnlock()
+
+	payload = rawPayload
+
+	client, exist := s.sender[provider]
+	if !exist {
+		return
+	}
+
+	extractor, ok := client.(RecipientExtractor)
+	if !ok {
+		return
+	}
+
+	recipients, payload, err = extractor.ExtractRecipients(ctx, rawPayload)
+	return
+}
+
+func (s *SenderMultiplexer) HealthCheck(ctx context.Context, provider string) (err error) {
+	beMux.lock.RLock()
+	defer beMux.lock.RUnlock()
+
+	client, exist := s.sender[provider]
+	if !exist {
+		err = fmt.Errorf("sender for provider '%s' is not registered", provider)
+		return
+	}
+
+	checker, ok := client.(HealthChecker)
+	if !ok {
+		return
+	}
+
+	err = checker.HealthCheck(ctx)
+	return
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"github.com/mitchellh/cli"
 	"github.com/yusufsyaifudin/ngendika/container"
 	"github.com/yusufsyaifudin/ngendika/extd"
 	"github.com/yusufsyaifudin/ylog"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -19,23 +21,36 @@ const (
 type Cmd struct {
 	appName    string
 	appVersion string
+	configFile string
 }
 
-func NewCmd(appName, appVersion string) func() (cli.Command, error) {
+// NewCmd configFile is the default config file path, it can be changed using -c or --config flag.
+func NewCmd(appName, appVersion, configFile string) func() (cli.Command, error) {
 	return func() (cli.Command, error) {
 		cmd := &Cmd{
 			appName:    appName,
 			appVersion: appVersion,
+			configFile: configFile,
 		}
 		return cmd, nil
 	}
 }
 
 var _ cli.Command = (*Cmd)(nil)
-var _ cli.CommandFactory = NewCmd("", "")
+var _ cli.CommandFactory = NewCmd("", "", "")
 
 func (c *Cmd) Help() string {
-	return `API will start server using HTTP or gRPC`
+	return strings.TrimSpace(`
+Usage: ngendika api [options]
+
+  API will start server using HTTP or gRPC
+
+Options:
+
+  -c, --config=path  Path to YAML config file, default config.yml.
+                     Every config key can be overridden using NGENDIKA_* environment variable,
+                     i.e: NGENDIKA_TRANSPORT_HTTP_PORT=8080
+`)
 }
 
 func (c *Cmd) Run(args []string) int {
@@ -43,7 +58,15 @@ func (c *Cmd) Run(args []string) int {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cfg, err := container.LoadConfig()
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	flags.StringVar(&c.configFile, "c", c.configFile, "path to config file")
+	flags.StringVar(&c.configFile, "config", c.configFile, "path to config file")
+	flags.Usage = func() { log.Println(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return ExitErr
+	}
+
+	cfg, err := container.LoadConfig(c.configFile)
 	if err != nil {
 		err = fmt.Errorf("error load config: %w", err)
 		log.Println(err)
@@ -22,6 +22,13 @@ func AppCreate(ctx context.Context, components openapi3.Components, path map[str
 	reqStruct := handlerapp.CreateAppReq{
 		Name:     "My App",
 		ClientID: "myapp",
+		Settings: httptyped2.AppSettingsEntity{
+			DefaultLabel:     "default",
+			AllowedProviders: []string{"fcm"},
+			DailySendQuota:   10000,
+			DefaultTTLs:      map[string]int64{"fcm": 3600},
+			ContactEmail:     "admin@example.com",
+		},
 	}
 
 	// generate request
@@ -7,7 +7,6 @@ import (
 	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlerapp"
 	httptyped2 "github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
-	"math"
 	"net/http"
 	"time"
 )
@@ -20,8 +19,9 @@ func AppGetList(ctx context.Context, components openapi3.Components, path map[st
 	const pathRoute = "/api/v1/apps"
 
 	// --- Response schema
+	total := int64(1)
 	respStruct := handlerapp.ListAppsResp{
-		Total: 1,
+		Total: &total,
 		Limit: 100,
 		Items: []httptyped2.AppEntity{
 			{
@@ -32,6 +32,9 @@ func AppGetList(ctx context.Context, components openapi3.Components, path map[st
 				UpdatedAt: time.Now(),
 			},
 		},
+		Links: httptyped2.PageLinks{
+			Next: "/api/v1/apps?cursor=eyJkIjoibmV4dCIsInMiOiJpZCIsImkiOjEyM30&limit=100",
+		},
 	}
 
 	// generate response and add to components
@@ -47,15 +50,35 @@ func AppGetList(ctx context.Context, components openapi3.Components, path map[st
 	paramLimit.Example = 100
 	paramLimit.Required = false
 
-	paramBeforeID := openapi3.NewQueryParameter("max_id").WithDescription("Maximum of app id to be fetched")
-	paramBeforeID.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "number"}}
-	paramBeforeID.Example = fmt.Sprintf("%d", math.MaxInt64)
-	paramBeforeID.Required = false
+	paramCursor := openapi3.NewQueryParameter("cursor").WithDescription("Opaque cursor from the next or prev links of previous response")
+	paramCursor.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}}
+	paramCursor.Required = false
+
+	paramSort := openapi3.NewQueryParameter("sort").WithDescription("Sort by id, name or created_at, prefixed with '-' for descending")
+	paramSort.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}}
+	paramSort.Example = "-created_at"
+	paramSort.Required = false
+
+	paramName := openapi3.NewQueryParameter("name").WithDescription("Filter app which name starting with it (case-insensitive)")
+	paramName.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}}
+	paramName.Required = false
+
+	paramClientID := openapi3.NewQueryParameter("client_id").WithDescription("Filter app which client id starting with it (case-insensitive)")
+	paramClientID.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}}
+	paramClientID.Required = false
+
+	paramWithTotal := openapi3.NewQueryParameter("with_total").WithDescription("Count total app matching the filter")
+	paramWithTotal.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "boolean"}}
+	paramWithTotal.Example = false
+	paramWithTotal.Required = false
+
+	paramMinID := openapi3.NewQueryParameter("min_id").WithDescription("Get the apps after this id, cannot be used with cursor and sort other than id")
+	paramMinID.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "number"}}
+	paramMinID.Required = false
 
-	paramAfterID := openapi3.NewQueryParameter("min_id").WithDescription("Minimum of app id to be fetched")
-	paramAfterID.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "number"}}
-	paramAfterID.Example = 0
-	paramAfterID.Required = false
+	paramMaxID := openapi3.NewQueryParameter("max_id").WithDescription("Get the apps before this id, cannot be used with cursor and sort other than id")
+	paramMaxID.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "number"}}
+	paramMaxID.Required = false
 
 	// --- final spec
 	op := openapi3.NewOperation()
@@ -63,8 +86,13 @@ func AppGetList(ctx context.Context, components openapi3.Components, path map[st
 	op.Summary = routeName
 	op.OperationID = scopedSchemaName
 	op.AddParameter(paramLimit)
-	op.AddParameter(paramBeforeID)
-	op.AddParameter(paramAfterID)
+	op.AddParameter(paramCursor)
+	op.AddParameter(paramSort)
+	op.AddParameter(paramName)
+	op.AddParameter(paramClientID)
+	op.AddParameter(paramWithTotal)
+	op.AddParameter(paramMinID)
+	op.AddParameter(paramMaxID)
 
 	op.AddResponse(http.StatusOK, openapi3.NewResponse().WithJSONSchemaRef(
 		&openapi3.SchemaRef{
@@ -0,0 +1,64 @@
+package genapidoc
+
+import (
+	"context"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlerapp"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
+	"net/http"
+	"time"
+)
+
+// AppRestore
+// POST /api/v1/apps/{client_id}/restore
+func AppRestore(ctx context.Context, components openapi3.Components, path map[string]*openapi3.PathItem) {
+	const scopedSchemaName = "AppRestore"
+	const routeName = "Restore Deleted Application"
+	const pathRoute = "/api/v1/apps/{client_id}/restore"
+
+	// --- Response schema
+	respStruct := handlerapp.RestoreAppByClientIDResp{
+		App: httptyped.AppEntity{
+			ID:        123,
+			ClientID:  "myapp",
+			Name:      "My App",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+	}
+
+	// generate response and add to components
+	resp := respbuilder.Success(ctx, respStruct)
+	outResp := MustNewSchemaGenerator(ctx, scopedSchemaName+".Resp200.", resp)
+	for s, ref := range outResp.Schemas {
+		components.Schemas[s] = ref
+	}
+
+	// --- params
+	paramAppClientID := openapi3.NewPathParameter("client_id").WithDescription("AppRepo Client ID")
+	paramAppClientID.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "string"}}
+	paramAppClientID.Example = "myapp"
+
+	// --- final spec
+	op := openapi3.NewOperation()
+	op.Tags = []string{"Application"}
+	op.Summary = routeName
+	op.Description = "Restore the app and its push notification providers which deleted within the retention window."
+	op.OperationID = scopedSchemaName
+	op.AddParameter(paramAppClientID)
+
+	op.AddResponse(http.StatusOK, openapi3.NewResponse().WithJSONSchemaRef(
+		&openapi3.SchemaRef{
+			Ref: fmt.Sprintf("#/components/schemas/%s", outResp.ParentSchemaName),
+		},
+	).WithDescription("desc"))
+
+	_, exist := path[pathRoute]
+	if !exist {
+		path[pathRoute] = &openapi3.PathItem{}
+	}
+
+	path[pathRoute].Post = op
+}
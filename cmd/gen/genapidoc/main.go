@@ -91,6 +91,8 @@ func (a *ApiDoc) Run(args []string) int {
 	AppCreate(ctx, components, paths)
 	AppCreateOrReplace(ctx, components, paths)
 	AppDelete(ctx, components, paths)
+	AppRestore(ctx, components, paths)
+	AppPutSettings(ctx, components, paths)
 	AppGetList(ctx, components, paths)
 	AppGetOne(ctx, components, paths)
 	PnpCreate(ctx, components, paths)
@@ -0,0 +1,203 @@
+package migrate
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/mitchellh/cli"
+	"github.com/yusufsyaifudin/ngendika/container"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	ExitSuccess = 0
+	ExitErr     = -1
+)
+
+const serviceAll = "all"
+
+type Cmd struct {
+	configFile string
+}
+
+// NewCmd configFile is the default config file path, it can be changed using -c or --config flag.
+func NewCmd(configFile string) func() (cli.Command, error) {
+	return func() (cli.Command, error) {
+		return &Cmd{configFile: configFile}, nil
+	}
+}
+
+var _ cli.Command = (*Cmd)(nil)
+var _ cli.CommandFactory = NewCmd("")
+
+func (c *Cmd) Help() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+Usage: ngendika migrate [options] <service> <up|down|status|redo>
+
+  Run the embedded SQL migrations of the service into the database configured in services.<service>.dbLabel.
+  Applied migrations is saved in table %s of each database resource.
+
+Services:
+
+  %s or %s
+
+Commands:
+
+  up      Apply all pending migrations.
+  down    Rollback the latest applied migration.
+  status  Show the applied time of each migration.
+  redo    Rollback the latest applied migration then apply it again.
+
+Options:
+
+  -c, --config=path  Path to YAML config file, default config.yml.
+  -n, --limit=N      Maximum number of migration to apply (up) or rollback (down).
+`, migration.DefaultTable, strings.Join(container.MigrationServices(), ", "), serviceAll))
+}
+
+func (c *Cmd) Synopsis() string {
+	return `Run the embedded SQL migrations`
+}
+
+func (c *Cmd) Run(args []string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var limit int
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.StringVar(&c.configFile, "c", c.configFile, "path to config file")
+	flags.StringVar(&c.configFile, "config", c.configFile, "path to config file")
+	flags.IntVar(&limit, "n", 0, "maximum number of migration")
+	flags.IntVar(&limit, "limit", 0, "maximum number of migration")
+	flags.Usage = func() { log.Println(c.Help()) }
+	if err := flags.Parse(args); err != nil {
+		return ExitErr
+	}
+
+	if flags.NArg() != 2 {
+		log.Println(c.Help())
+		return ExitErr
+	}
+
+	service, command := flags.Arg(0), strings.ToLower(flags.Arg(1))
+	switch command {
+	case "up", "down", "status", "redo":
+	default:
+		log.Printf("unknown migrate command '%s'\n", command)
+		return ExitErr
+	}
+
+	services := []string{service}
+	if service == serviceAll {
+		services = container.MigrationServices()
+
+		// rollback in reverse order, since latter service may depend on the former
+		if command == "down" || command == "redo" {
+			for i, j := 0, len(services)-1; i < j; i, j = i+1, j-1 {
+				services[i], services[j] = services[j], services[i]
+			}
+		}
+	}
+
+	cfg, err := container.LoadConfig(c.configFile)
+	if err != nil {
+		log.Printf("error load config: %s\n", err)
+		return ExitErr
+	}
+
+	// cache is not needed to run the migrations
+	repositories, err := container.SetupRepositories(cfg.DatabaseResources, nil)
+	if err != nil {
+		log.Printf("error connecting database: %s\n", err)
+		return ExitErr
+	}
+
+	defer func() {
+		if _err := repositories.Close(); _err != nil {
+			log.Printf("error closing database: %s\n", _err)
+		}
+	}()
+
+	for _, svc := range services {
+		err = c.migrate(ctx, cfg, repositories, svc, command, limit)
+		if err != nil {
+			log.Printf("migrate %s %s: %s\n", svc, command, err)
+			return ExitErr
+		}
+	}
+
+	return ExitSuccess
+}
+
+func (c *Cmd) migrate(ctx context.Context, cfg container.Config, repositories *container.RepositoryImpl, service, command string, limit int) error {
+	dbLabel, err := cfg.Services.DBLabel(service)
+	if err != nil {
+		return err
+	}
+
+	migrator, err := repositories.Migrator(service, dbLabel)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s: applied %d migration(s) on %s\n", service, len(applied), dbLabel)
+		for _, version := range applied {
+			fmt.Printf("  + %s\n", version)
+		}
+
+	case "down":
+		rolledBack, err := migrator.Down(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s: rolled back %d migration(s) on %s\n", service, len(rolledBack), dbLabel)
+		for _, version := range rolledBack {
+			fmt.Printf("  - %s\n", version)
+		}
+
+	case "redo":
+		version, err := migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s: redo %s on %s\n", service, version, dbLabel)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s (%s):\n", service, dbLabel)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "  VERSION\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			_, _ = fmt.Fprintf(w, "  %s\t%s\n", status.Version, appliedAt)
+		}
+
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
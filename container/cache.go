@@ -0,0 +1,182 @@
+package container
+
+import (
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
+	"io"
+)
+
+// setupCaches connect all enabled cache resources, the returned closers must be closed when caches is no longer used.
+// Redis is prepared first, so the in-memory cache can publish the evicted keys using it.
+func setupCaches(conf ConfigCacheResources) (caches map[string]cache.Cache, closers []io.Closer, err error) {
+	caches = make(map[string]cache.Cache)
+	closers = make([]io.Closer, 0)
+	redisClients := make(map[string]redis.UniversalClient)
+	for label, resource := range conf {
+		if resource.Disable || resource.Driver != "redis" {
+			continue
+		}
+
+		client := newRedisClient(resource.Redis)
+		closers = append(closers, client)
+		redisClients[label] = client
+
+		caches[label], err = cache.NewRedis(cache.RedisConfig{DB: client})
+		if err != nil {
+			err = fmt.Errorf("cannot prepare redis cache '%s': %w", label, err)
+			return
+		}
+	}
+
+	for label, resource := range conf {
+		if resource.Disable {
+			continue
+		}
+
+		switch resource.Driver {
+		case "redis":
+			// already prepared
+
+		case "inmemory":
+			var local *cache.InMemory
+			local, err = newInMemory(label, resource.InMemory.MaxBytes)
+			if err != nil {
+				return
+			}
+
+			caches[label] = local
+			if resource.InMemory.EvictVia == "" {
+				continue
+			}
+
+			client, ok := redisClients[resource.InMemory.EvictVia]
+			if !ok {
+				err = fmt.Errorf("unknown redis cache '%s' to evict in-memory cache '%s'", resource.InMemory.EvictVia, label)
+				return
+			}
+
+			var broadcast *cache.Broadcast
+			broadcast, err = cache.NewBroadcast(cache.BroadcastConfig{
+				Local:   local,
+				PubSub:  client,
+				Channel: fmt.Sprintf("ngendika:evict:%s", label),
+			})
+			if err != nil {
+				err = fmt.Errorf("cannot prepare in-memory cache '%s' eviction: %w", label, err)
+				return
+			}
+
+			// must be closed before the redis client
+			closers = append([]io.Closer{broadcast}, closers...)
+			caches[label] = broadcast
+
+		case "tiered":
+			var local *cache.InMemory
+			local, err = newInMemory(label, resource.Tiered.MaxBytes)
+			if err != nil {
+				return
+			}
+
+			client, ok := redisClients[resource.Tiered.Redis]
+			if !ok {
+				err = fmt.Errorf("unknown redis cache '%s' as L2 of tiered cache '%s'", resource.Tiered.Redis, label)
+				return
+			}
+
+			var tiered *cache.Tiered
+			tiered, err = cache.NewTiered(cache.TieredConfig{
+				Local:    local,
+				Redis:    client,
+				Channel:  fmt.Sprintf("ngendika:evict:%s", label),
+				LocalTTL: resource.Tiered.LocalTTL,
+			})
+			if err != nil {
+				err = fmt.Errorf("cannot prepare tiered cache '%s': %w", label, err)
+				return
+			}
+
+			// must be closed before the redis client
+			closers = append([]io.Closer{tiered}, closers...)
+			caches[label] = tiered
+
+		default:
+			err = fmt.Errorf("not supported cache driver '%s' on label '%s'", resource.Driver, label)
+			return
+		}
+	}
+
+	return
+}
+
+// newInMemory create in-memory cache and export its hit, miss and eviction stats.
+func newInMemory(label string, maxBytes int) (local *cache.InMemory, err error) {
+	opts := make([]cache.InMemoryOpt, 0)
+	if maxBytes > 0 {
+		opts = append(opts, cache.WithMaxBytes(maxBytes))
+	}
+
+	local, err = cache.NewInMemory(opts...)
+	if err != nil {
+		err = fmt.Errorf("cannot prepare in-memory cache '%s': %w", label, err)
+		return
+	}
+
+	err = metric.RegisterCacheStats(label, func() metric.CacheStats {
+		return metric.CacheStats(local.Stats())
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot register cache stats metric '%s': %w", label, err)
+		return
+	}
+
+	return
+}
+
+// newRedisClient does not connect to the server, the connection is made on the first command.
+func newRedisClient(conf ConfigRedis) redis.UniversalClient {
+	switch conf.Mode {
+	case "sentinel":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    conf.MasterName,
+			SentinelAddrs: conf.Addrs,
+			Username:      conf.Username,
+			Password:      conf.Password,
+			DB:            conf.DB,
+			PoolSize:      conf.PoolSize,
+		})
+
+	case "cluster":
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    conf.Addrs,
+			Username: conf.Username,
+			Password: conf.Password,
+			PoolSize: conf.PoolSize,
+		})
+
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:     conf.Addrs[0],
+			Username: conf.Username,
+			Password: conf.Password,
+			DB:       conf.DB,
+			PoolSize: conf.PoolSize,
+		})
+	}
+}
+
+// getCache return nil cache when the cache label is empty.
+func (r *RepositoryImpl) getCache(cacheLabel string) (c cache.Cache, err error) {
+	if cacheLabel == "" {
+		return
+	}
+
+	c, ok := r.caches[cacheLabel]
+	if !ok {
+		err = fmt.Errorf("unknown or disabled cache key %s", cacheLabel)
+		return
+	}
+
+	return
+}
@@ -2,77 +2,426 @@ package container
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
 // ConfigHTTPServer struct for HTTP ConfigTransport configuration
 type ConfigHTTPServer struct {
-	Port int `yaml:"port"`
+	Port int `yaml:"port" validate:"required,min=1,max=65535"`
+
+	// StreamTimeout is deadline of the message stream route, which is not bounded by the 30 seconds request timeout.
+	StreamTimeout time.Duration `yaml:"streamTimeout" validate:"min=0"` // default 5m
+}
+
+// ConfigGRPCServer struct for gRPC ConfigTransport configuration, port 0 means gRPC server is not started.
+type ConfigGRPCServer struct {
+	Port int `yaml:"port" validate:"omitempty,min=1,max=65535"`
 }
 
 // ConfigTransport is a configuration for Admin ConfigTransport: HTTP, gRPC or anything
 type ConfigTransport struct {
 	HTTP ConfigHTTPServer `yaml:"http"`
+	GRPC ConfigGRPCServer `yaml:"grpc"`
+}
+
+// ConfigAPIKey is a static API key and the scopes it is allowed to use, i.e: messages:send, pnp:read.
+// The scopes must be the same as apikey.Scopes, so typo is reported instead of locking out the key.
+type ConfigAPIKey struct {
+	Name   string   `yaml:"name" validate:"required"`
+	Key    string   `yaml:"key" validate:"required"`
+	Scopes []string `yaml:"scopes" validate:"required,min=1,dive,oneof=* apps:read apps:write apps:admin pnp:read pnp:write pnp:admin templates:read templates:write devices:read devices:write topics:read topics:write messages:send callbacks:read callbacks:write"`
+}
+
+// ConfigAuth when no API key is defined, all routes can be accessed without any key.
+type ConfigAuth struct {
+	APIKeys []ConfigAPIKey `yaml:"apiKeys" validate:"dive"`
+}
+
+// ConfigTracing select where the spans is exported.
+type ConfigTracing struct {
+	Exporter           string            `yaml:"exporter" validate:"omitempty,oneof=otlp-grpc otlp-http jaeger stdout none"`
+	Endpoint           string            `yaml:"endpoint"`
+	Insecure           bool              `yaml:"insecure"`
+	Headers            map[string]string `yaml:"headers"`
+	SamplingRatio      *float64          `yaml:"samplingRatio" validate:"omitempty,min=0,max=1"` // default 1 (sample all)
+	ResourceAttributes map[string]string `yaml:"resourceAttributes"`
+	Propagators        []string          `yaml:"propagators" validate:"dive,oneof=tracecontext baggage jaeger ot"`
 }
 
 type ConfigGoSqlDb struct {
 	Debug bool   `yaml:"debug"`
 	DSN   string `yaml:"dsn"` // Data Source Name
+
+	// Replicas is DSN of read replicas, read query is routed to the healthy replicas and fallback to the primary.
+	// Read query inside the unit of work use the transaction of the primary.
+	Replicas             []string      `yaml:"replicas"`
+	ReplicaCheckInterval time.Duration `yaml:"replicaCheckInterval" validate:"min=0"` // default 5s
+
+	// Connection pool of the primary and each replica, zero means using database/sql default
+	MaxOpenConns    int           `yaml:"maxOpenConns" validate:"min=0"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" validate:"min=0"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" validate:"min=0"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime" validate:"min=0"`
+
+	// StatementTimeout abort the query running longer than it, not supported on sqlite.
+	// On mysql it is max_execution_time which only applies to SELECT.
+	StatementTimeout time.Duration `yaml:"statementTimeout" validate:"min=0"`
 }
 
 type ConfigDatabaseResource struct {
 	Disable bool   `yaml:"disable"`
-	Driver  string `yaml:"driver"` // mysql, postgres, etc
+	Driver  string `yaml:"driver" validate:"required_unless=Disable true,omitempty,oneof=postgres mysql sqlite"` // mysql, postgres, sqlite
 
 	// per driver configuration
-	// Mysql    ConfigGoSqlDb `yaml:"mysql"` TODO: only example if we want to add another driver
 	Postgres ConfigGoSqlDb `yaml:"postgres"`
+	Mysql    ConfigGoSqlDb `yaml:"mysql"`
+	Sqlite   ConfigGoSqlDb `yaml:"sqlite"`
 }
 
 // ConfigDatabaseResources redefine config
 type ConfigDatabaseResources map[string]ConfigDatabaseResource
 
+// ConfigRedis mode standalone use the first address, sentinel use all addresses as sentinel nodes and
+// cluster use all addresses as cluster nodes.
+type ConfigRedis struct {
+	Mode       string   `yaml:"mode" validate:"omitempty,oneof=standalone sentinel cluster"` // default standalone
+	Addrs      []string `yaml:"addrs"`
+	MasterName string   `yaml:"masterName"` // required on sentinel mode
+	Username   string   `yaml:"username"`
+	Password   string   `yaml:"password"`
+	DB         int      `yaml:"db" validate:"min=0"`       // ignored on cluster mode
+	PoolSize   int      `yaml:"poolSize" validate:"min=0"` // 0 means using go-redis default
+}
+
+// ConfigInMemory when evictVia is set, every evicted key is published using the Redis cache resource,
+// so the key is also evicted from the in-memory cache of the other nodes.
+type ConfigInMemory struct {
+	MaxBytes int    `yaml:"maxBytes" validate:"omitempty,min=33554432"` // default and minimum 32MB, oldest entries is overwritten when full
+	EvictVia string `yaml:"evictVia"`                                   // cache label of redis driver in cacheResources
+}
+
+// ConfigTiered is in-memory cache (L1) in front of the Redis cache resource (L2),
+// every set and delete is published via Redis to evict the L1 of the other nodes.
+type ConfigTiered struct {
+	Redis    string        `yaml:"redis"`                                      // cache label of redis driver in cacheResources
+	LocalTTL time.Duration `yaml:"localTTL"`                                   // maximum expiry in L1
+	MaxBytes int           `yaml:"maxBytes" validate:"omitempty,min=33554432"` // L1 size, default and minimum 32MB
+}
+
+type ConfigCacheResource struct {
+	Disable bool   `yaml:"disable"`
+	Driver  string `yaml:"driver" validate:"required_unless=Disable true,omitempty,oneof=redis inmemory tiered"`
+
+	// per driver configuration
+	Redis    ConfigRedis    `yaml:"redis"`
+	InMemory ConfigInMemory `yaml:"inmemory"`
+	Tiered   ConfigTiered   `yaml:"tiered"`
+}
+
+// ConfigCacheResources cache label => cache connection, the connection is shared between services using the same label.
+type ConfigCacheResources map[string]ConfigCacheResource
+
+// ConfigServiceCache select the cache used by the service repository, empty cacheLabel means no cache.
+type ConfigServiceCache struct {
+	CacheLabel string        `yaml:"cacheLabel"`
+	Expiry     time.Duration `yaml:"expiry" validate:"required_with=CacheLabel"`
+	Prefix     string        `yaml:"prefix" validate:"required_with=CacheLabel,omitempty,alphanum"`
+}
+
+// ConfigServiceAppDeletion deleted app and its push notification providers can be restored within the retention.
+// After that, it is purged by the background job when purge is set.
+type ConfigServiceAppDeletion struct {
+	Retention     time.Duration `yaml:"retention" validate:"min=0"`                    // default to 7 days
+	Purge         string        `yaml:"purge" validate:"omitempty,oneof=drop archive"` // leave empty to disable the purge job
+	PurgeInterval time.Duration `yaml:"purgeInterval" validate:"required_with=Purge"`
+}
+
 type ConfigServiceApp struct {
-	DBLabel string `yaml:"dbLabel"`
+	DBLabel  string                   `yaml:"dbLabel" validate:"required"`
+	Cache    ConfigServiceCache       `yaml:"cache"`
+	Deletion ConfigServiceAppDeletion `yaml:"deletion"`
 }
 
 type ConfigServicePushProvider struct {
-	DBLabel string `yaml:"dbLabel"`
+	DBLabel string             `yaml:"dbLabel" validate:"required"`
+	Cache   ConfigServiceCache `yaml:"cache"`
+}
+
+type ConfigServiceTemplate struct {
+	DBLabel string `yaml:"dbLabel" validate:"required"`
+}
+
+type ConfigServiceDevice struct {
+	DBLabel string `yaml:"dbLabel" validate:"required"`
+}
+
+type ConfigServiceTopic struct {
+	DBLabel string `yaml:"dbLabel" validate:"required"`
 }
 
 type ConfigServiceMessaging struct {
 	DBLabel     string `yaml:"dbLabel"`
-	MaxBuffer   int    `yaml:"maxBuffer"`
-	MaxParallel int    `yaml:"maxParallel"`
+	MaxBuffer   int    `yaml:"maxBuffer" validate:"required,min=1"`
+	MaxParallel int    `yaml:"maxParallel" validate:"required,min=1"`
+}
+
+type ConfigServiceCallback struct {
+	DBLabel     string        `yaml:"dbLabel" validate:"required"`
+	MaxBuffer   int           `yaml:"maxBuffer" validate:"required,min=1"`
+	MaxWorker   int           `yaml:"maxWorker" validate:"required,min=1"`
+	MaxAttempts int           `yaml:"maxAttempts" validate:"required,min=1"`
+	Backoff     time.Duration `yaml:"backoff" validate:"required"` // wait time before first retry, doubled on each retry
+	Timeout     time.Duration `yaml:"timeout" validate:"required"` // timeout of each callback request
+
+	// AllowPrivateNetwork allow callback url to private, loopback or link-local address, only for local development.
+	AllowPrivateNetwork bool `yaml:"allowPrivateNetwork"`
 }
 
 type ConfigServices struct {
 	App             ConfigServiceApp          `yaml:"app"`
 	ServiceProvider ConfigServicePushProvider `yaml:"serviceProvider"`
+	Template        ConfigServiceTemplate     `yaml:"template"`
+	Device          ConfigServiceDevice       `yaml:"device"`
+	Topic           ConfigServiceTopic        `yaml:"topic"`
 	Messaging       ConfigServiceMessaging    `yaml:"messaging"`
+	Callback        ConfigServiceCallback     `yaml:"callback"`
 }
 
 // Config contains application config
 type Config struct {
 	Transport         ConfigTransport         `yaml:"transport"`
-	DatabaseResources ConfigDatabaseResources `yaml:"databaseResources"`
+	Auth              ConfigAuth              `yaml:"auth"`
+	Tracing           ConfigTracing           `yaml:"tracing"`
+	DatabaseResources ConfigDatabaseResources `yaml:"databaseResources" validate:"required,dive"`
+	CacheResources    ConfigCacheResources    `yaml:"cacheResources" validate:"dive"`
 	Services          ConfigServices          `yaml:"services"`
 }
 
-// LoadConfig need config file name and pointer to struct to hold the configuration value.
-// It only supports YAML file content.
-func LoadConfig() (cfg Config, err error) {
-	const configFileName = "config.yml"
-	fileContent, err := os.ReadFile(configFileName)
+// DefaultConfigFile is used when no config file path is passed.
+const DefaultConfigFile = "config.yml"
+
+// LoadConfig read the YAML config file, then override the value using NGENDIKA_* environment variables
+// (see EnvPrefix) and validate the result.
+// When path is empty, DefaultConfigFile is used and it is allowed to not exist, so all config can be passed via env.
+func LoadConfig(path string) (cfg Config, err error) {
+	optional := path == ""
+	if optional {
+		path = DefaultConfigFile
+	}
+
+	fileContent, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		dec := yaml.NewDecoder(bytes.NewReader(fileContent))
+		dec.KnownFields(false)
+		err = dec.Decode(&cfg)
+		if err != nil && !errors.Is(err, io.EOF) {
+			err = fmt.Errorf("error parse file config %s: %w", path, err)
+			return
+		}
+
+	case optional && errors.Is(err, fs.ErrNotExist):
+		// all config come from environment variables
+
+	default:
+		err = fmt.Errorf("error read file config %s: %w", path, err)
+		return
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, EnvPrefix+"_") {
+			env[k] = v
+		}
+	}
+
+	err = applyEnv(&cfg, env)
 	if err != nil {
-		err = fmt.Errorf("error read file config %s: %w", configFileName, err)
+		err = fmt.Errorf("error override config from env: %w", err)
 		return
 	}
 
-	dec := yaml.NewDecoder(bytes.NewReader(fileContent))
-	dec.KnownFields(false)
-	err = dec.Decode(&cfg)
+	err = cfg.Validate()
 	return
 }
+
+// Validate return error contains the yaml key path of invalid value, i.e: services.app.dbLabel.
+func (c Config) Validate() error {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	err := v.Struct(c)
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		msgs := make([]string, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			// remove the root struct name: Config.services.app.dbLabel => services.app.dbLabel
+			key := fieldErr.Namespace()
+			if idx := strings.Index(key, "."); idx >= 0 {
+				key = key[idx+1:]
+			}
+
+			rule := fieldErr.Tag()
+			if fieldErr.Param() != "" {
+				rule = fmt.Sprintf("%s=%s", rule, fieldErr.Param())
+			}
+
+			msgs = append(msgs, fmt.Sprintf("key '%s' with value '%v' is not valid: %s", key, fieldErr.Value(), rule))
+		}
+
+		return fmt.Errorf("invalid config: %s", strings.Join(msgs, "; "))
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if c.Transport.GRPC.Port == c.Transport.HTTP.Port {
+		return fmt.Errorf("invalid config: key 'transport.grpc.port' must be different with 'transport.http.port' %d", c.Transport.HTTP.Port)
+	}
+
+	for label, resource := range c.DatabaseResources {
+		if resource.Disable {
+			continue
+		}
+
+		if resource.Driver == "postgres" && resource.Postgres.DSN == "" {
+			return fmt.Errorf("invalid config: key 'databaseResources[%s].postgres.dsn' is required", label)
+		}
+
+		if resource.Driver == "mysql" && resource.Mysql.DSN == "" {
+			return fmt.Errorf("invalid config: key 'databaseResources[%s].mysql.dsn' is required", label)
+		}
+
+		if resource.Driver == "sqlite" && resource.Sqlite.DSN == "" {
+			return fmt.Errorf("invalid config: key 'databaseResources[%s].sqlite.dsn' is required", label)
+		}
+
+		if resource.Driver == "sqlite" && resource.Sqlite.StatementTimeout > 0 {
+			return fmt.Errorf("invalid config: key 'databaseResources[%s].sqlite.statementTimeout' is not supported", label)
+		}
+	}
+
+	for label, resource := range c.CacheResources {
+		if resource.Disable {
+			continue
+		}
+
+		if resource.Driver == "inmemory" && resource.InMemory.EvictVia != "" {
+			evictVia, exist := c.CacheResources[resource.InMemory.EvictVia]
+			if !exist || evictVia.Disable || evictVia.Driver != "redis" {
+				return fmt.Errorf("invalid config: key 'cacheResources[%s].inmemory.evictVia' must refer to enabled redis cacheResources '%s'", label, resource.InMemory.EvictVia)
+			}
+		}
+
+		if resource.Driver == "tiered" {
+			l2, exist := c.CacheResources[resource.Tiered.Redis]
+			if !exist || l2.Disable || l2.Driver != "redis" {
+				return fmt.Errorf("invalid config: key 'cacheResources[%s].tiered.redis' must refer to enabled redis cacheResources '%s'", label, resource.Tiered.Redis)
+			}
+
+			if resource.Tiered.LocalTTL <= 0 {
+				return fmt.Errorf("invalid config: key 'cacheResources[%s].tiered.localTTL' is required", label)
+			}
+		}
+
+		if resource.Driver != "redis" {
+			continue
+		}
+
+		if len(resource.Redis.Addrs) <= 0 {
+			return fmt.Errorf("invalid config: key 'cacheResources[%s].redis.addrs' is required", label)
+		}
+
+		if resource.Redis.Mode == "sentinel" && resource.Redis.MasterName == "" {
+			return fmt.Errorf("invalid config: key 'cacheResources[%s].redis.masterName' is required on sentinel mode", label)
+		}
+	}
+
+	cacheLabels := map[string]string{
+		"services.app.cache.cacheLabel":             c.Services.App.Cache.CacheLabel,
+		"services.serviceProvider.cache.cacheLabel": c.Services.ServiceProvider.Cache.CacheLabel,
+	}
+
+	for _, key := range sortedKeys(cacheLabels) {
+		cacheLabel := cacheLabels[key]
+		if cacheLabel == "" {
+			continue
+		}
+
+		resource, exist := c.CacheResources[cacheLabel]
+		if !exist || resource.Disable {
+			return fmt.Errorf("invalid config: key '%s' refer to unknown or disabled cacheResources '%s'", key, cacheLabel)
+		}
+	}
+
+	dbLabels := map[string]string{
+		"services.app.dbLabel":             c.Services.App.DBLabel,
+		"services.serviceProvider.dbLabel": c.Services.ServiceProvider.DBLabel,
+		"services.template.dbLabel":        c.Services.Template.DBLabel,
+		"services.device.dbLabel":          c.Services.Device.DBLabel,
+		"services.topic.dbLabel":           c.Services.Topic.DBLabel,
+		"services.callback.dbLabel":        c.Services.Callback.DBLabel,
+	}
+
+	for _, key := range sortedKeys(dbLabels) {
+		resource, exist := c.DatabaseResources[dbLabels[key]]
+		if !exist {
+			return fmt.Errorf("invalid config: key '%s' refer to unknown databaseResources '%s'", key, dbLabels[key])
+		}
+
+		// only app and serviceProvider has mysql repository, the rest must use postgres or sqlite database
+		drivers, limited := serviceDrivers[key]
+		if limited && !containsString(drivers, resource.Driver) {
+			return fmt.Errorf("invalid config: key '%s' refer to databaseResources '%s' with driver '%s', must be one of %s",
+				key, dbLabels[key], resource.Driver, drivers)
+		}
+	}
+
+	return nil
+}
+
+// serviceDrivers is the supported database driver of the service that not implement all drivers.
+var serviceDrivers = map[string][]string{
+	"services.template.dbLabel": {"postgres", "sqlite"},
+	"services.device.dbLabel":   {"postgres", "sqlite"},
+	"services.topic.dbLabel":    {"postgres", "sqlite"},
+	"services.callback.dbLabel": {"postgres", "sqlite"},
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,322 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EnvPrefix is prefix of all environment variables to override the config value.
+// The name is the yaml key path in upper snake case joined with underscore,
+// i.e: transport.http.port => NGENDIKA_TRANSPORT_HTTP_PORT,
+// databaseResources.allInOneDB.postgres.dsn => NGENDIKA_DATABASE_RESOURCES_ALLINONEDB_POSTGRES_DSN,
+// auth.apiKeys[0].key => NGENDIKA_AUTH_API_KEYS_0_KEY.
+// List of string is comma separated (a,b) and map of string is comma separated key=value (a=1,b=2).
+const EnvPrefix = "NGENDIKA"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv override cfg using environment variables env, env is map of environment variable name => value.
+func applyEnv(cfg *Config, env map[string]string) error {
+	return envOverride(reflect.ValueOf(cfg).Elem(), EnvPrefix, env)
+}
+
+func envOverride(val reflect.Value, name string, env map[string]string) error {
+	switch val.Kind() {
+	case reflect.Pointer:
+		if val.IsNil() {
+			if !hasEnvPrefix(env, name) {
+				return nil
+			}
+
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+
+		return envOverride(val.Elem(), name, env)
+
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if key == "" || key == "-" {
+				key = field.Name
+			}
+
+			err := envOverride(val.Field(i), name+"_"+envName(key), env)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+
+		// map of string is parsed from single value, others is parsed per map key
+		if isScalar(val.Type().Elem()) {
+			return setScalarEnv(val, name, env)
+		}
+
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+
+		for _, mapKey := range mapKeysFromEnv(val, name, env) {
+			key := reflect.ValueOf(mapKey).Convert(val.Type().Key())
+			elem := reflect.New(val.Type().Elem()).Elem()
+			if existing := val.MapIndex(key); existing.IsValid() {
+				elem.Set(existing)
+			}
+
+			err := envOverride(elem, name+"_"+strings.ToUpper(mapKey), env)
+			if err != nil {
+				return err
+			}
+
+			val.SetMapIndex(key, elem)
+		}
+
+		return nil
+
+	case reflect.Slice:
+		if isScalar(val.Type().Elem()) {
+			return setScalarEnv(val, name, env)
+		}
+
+		// slice of struct using index, i.e: NGENDIKA_AUTH_API_KEYS_0_KEY
+		size := sliceSizeFromEnv(name, env)
+		if size > val.Len() {
+			grown := reflect.MakeSlice(val.Type(), size, size)
+			reflect.Copy(grown, val)
+			val.Set(grown)
+		}
+
+		for i := 0; i < val.Len(); i++ {
+			err := envOverride(val.Index(i), fmt.Sprintf("%s_%d", name, i), env)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		return setScalarEnv(val, name, env)
+	}
+}
+
+func setScalarEnv(val reflect.Value, name string, env map[string]string) error {
+	raw, ok := env[name]
+	if !ok {
+		return nil
+	}
+
+	err := setScalar(val, raw)
+	if err != nil {
+		return fmt.Errorf("environment variable %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func setScalar(val reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if val.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration value '%s'", raw)
+		}
+
+		val.SetInt(int64(d))
+		return nil
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value '%s'", raw)
+		}
+
+		val.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer value '%s'", raw)
+		}
+
+		val.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer value '%s'", raw)
+		}
+
+		val.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float value '%s'", raw)
+		}
+
+		val.SetFloat(f)
+
+	case reflect.Pointer:
+		elem := reflect.New(val.Type().Elem())
+		if err := setScalar(elem.Elem(), raw); err != nil {
+			return err
+		}
+
+		val.Set(elem)
+
+	case reflect.Slice:
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		slice := reflect.MakeSlice(val.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalar(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+
+		val.Set(slice)
+
+	case reflect.Map:
+		m := reflect.MakeMap(val.Type())
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("invalid map value '%s', must be key=value", item)
+			}
+
+			elem := reflect.New(val.Type().Elem()).Elem()
+			if err := setScalar(elem, v); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)).Convert(val.Type().Key()), elem)
+		}
+
+		val.Set(m)
+
+	default:
+		return fmt.Errorf("not supported type %s", val.Type())
+	}
+
+	return nil
+}
+
+// mapKeysFromEnv return existing map keys and new keys found in env.
+// The map key only contains alphanumeric, so the first segment after the prefix is the map key.
+// Existing key is matched case-insensitively, new key is using lower case.
+func mapKeysFromEnv(val reflect.Value, name string, env map[string]string) []string {
+	keys := make(map[string]string) // upper case => real key
+	for _, k := range val.MapKeys() {
+		keys[strings.ToUpper(k.String())] = k.String()
+	}
+
+	for envKey := range env {
+		if !strings.HasPrefix(envKey, name+"_") {
+			continue
+		}
+
+		segment := strings.SplitN(strings.TrimPrefix(envKey, name+"_"), "_", 2)[0]
+		if _, exist := keys[segment]; !exist && segment != "" {
+			keys[segment] = strings.ToLower(segment)
+		}
+	}
+
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, k)
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// sliceSizeFromEnv return the biggest index + 1 found in env.
+func sliceSizeFromEnv(name string, env map[string]string) int {
+	size := 0
+	for envKey := range env {
+		if !strings.HasPrefix(envKey, name+"_") {
+			continue
+		}
+
+		segment := strings.SplitN(strings.TrimPrefix(envKey, name+"_"), "_", 2)[0]
+		idx, err := strconv.Atoi(segment)
+		if err != nil || idx < 0 {
+			continue
+		}
+
+		if idx+1 > size {
+			size = idx + 1
+		}
+	}
+
+	return size
+}
+
+func hasEnvPrefix(env map[string]string, name string) bool {
+	for envKey := range env {
+		if envKey == name || strings.HasPrefix(envKey, name+"_") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		return false
+	case reflect.Pointer:
+		return isScalar(t.Elem())
+	default:
+		return true
+	}
+}
+
+// envName convert yaml key into upper snake case, i.e: dbLabel => DB_LABEL.
+func envName(key string) string {
+	var sb strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && !unicode.IsUpper(runes[i-1]) {
+			sb.WriteRune('_')
+		}
+
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+
+	return sb.String()
+}
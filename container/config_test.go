@@ -0,0 +1,109 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+)
+
+func TestApplyEnv(t *testing.T) {
+	cfg := Config{
+		DatabaseResources: ConfigDatabaseResources{
+			"allInOneDB": {Driver: "postgres"},
+		},
+	}
+
+	err := applyEnv(&cfg, map[string]string{
+		"NGENDIKA_TRANSPORT_HTTP_PORT":                          "8080",
+		"NGENDIKA_DATABASE_RESOURCES_ALLINONEDB_POSTGRES_DSN":   "user=postgres",
+		"NGENDIKA_DATABASE_RESOURCES_REPLICA_DRIVER":            "postgres",
+		"NGENDIKA_AUTH_API_KEYS_0_KEY":                          "secret",
+		"NGENDIKA_AUTH_API_KEYS_0_SCOPES":                       "messages:send, apps:read",
+		"NGENDIKA_TRACING_SAMPLING_RATIO":                       "0.5",
+		"NGENDIKA_TRACING_RESOURCE_ATTRIBUTES":                  "environment=production",
+		"NGENDIKA_SERVICES_MESSAGING_MAX_BUFFER":                "10",
+		"NGENDIKA_DATABASE_RESOURCES_ALLINONEDB_POSTGRES_DEBUG": "true",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 8080, cfg.Transport.HTTP.Port)
+	assert.Equal(t, "user=postgres", cfg.DatabaseResources["allInOneDB"].Postgres.DSN)
+	assert.True(t, cfg.DatabaseResources["allInOneDB"].Postgres.Debug)
+	assert.Equal(t, "postgres", cfg.DatabaseResources["replica"].Driver)
+	assert.Equal(t, "secret", cfg.Auth.APIKeys[0].Key)
+	assert.Equal(t, []string{"messages:send", "apps:read"}, cfg.Auth.APIKeys[0].Scopes)
+	assert.Equal(t, 0.5, *cfg.Tracing.SamplingRatio)
+	assert.Equal(t, map[string]string{"environment": "production"}, cfg.Tracing.ResourceAttributes)
+	assert.Equal(t, 10, cfg.Services.Messaging.MaxBuffer)
+
+	err = applyEnv(&cfg, map[string]string{"NGENDIKA_TRANSPORT_HTTP_PORT": "abc"})
+	assert.EqualError(t, err, "environment variable NGENDIKA_TRANSPORT_HTTP_PORT: invalid integer value 'abc'")
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("sample config is valid", func(t *testing.T) {
+		_, err := LoadConfig(filepath.Join("..", "config.sample.yml"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("explicit file must exist", func(t *testing.T) {
+		_, err := LoadConfig(filepath.Join(t.TempDir(), "not-exist.yml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key is reported", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "config.yml")
+		err := os.WriteFile(file, []byte(`
+transport:
+  http:
+    port: 0
+`), 0600)
+		assert.NoError(t, err)
+
+		_, err = LoadConfig(file)
+		assert.ErrorContains(t, err, "key 'transport.http.port'")
+	})
+
+	t.Run("unknown cache label is reported", func(t *testing.T) {
+		cfg, err := LoadConfig(filepath.Join("..", "config.sample.yml"))
+		assert.NoError(t, err)
+
+		cfg.Services.App.Cache.CacheLabel = "notExist"
+		assert.EqualError(t, cfg.Validate(),
+			"invalid config: key 'services.app.cache.cacheLabel' refer to unknown or disabled cacheResources 'notExist'")
+	})
+
+	t.Run("mysql on service without mysql repository is reported", func(t *testing.T) {
+		cfg, err := LoadConfig(filepath.Join("..", "config.sample.yml"))
+		assert.NoError(t, err)
+
+		// app and serviceProvider support mysql
+		cfg.Services.App.DBLabel = "mysql1"
+		cfg.Services.ServiceProvider.DBLabel = "mysql1"
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Services.Template.DBLabel = "mysql1"
+		assert.EqualError(t, cfg.Validate(),
+			"invalid config: key 'services.template.dbLabel' refer to databaseResources 'mysql1' with driver 'mysql', must be one of [postgres sqlite]")
+	})
+
+	t.Run("unknown api key scope is reported", func(t *testing.T) {
+		cfg, err := LoadConfig(filepath.Join("..", "config.sample.yml"))
+		assert.NoError(t, err)
+
+		// every declared scope is accepted
+		scopes := make([]string, 0)
+		for _, scope := range apikey.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+
+		cfg.Auth.APIKeys = []ConfigAPIKey{{Name: "admin", Key: "secret", Scopes: scopes}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Auth.APIKeys[0].Scopes = []string{"message:send"}
+		assert.ErrorContains(t, cfg.Validate(), "key 'auth.apiKeys[0].scopes[0]' with value 'message:send' is not valid")
+	})
+}
@@ -0,0 +1,92 @@
+package container
+
+import (
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+	"github.com/yusufsyaifudin/ngendika/pkg/multidb"
+	"path"
+	"sort"
+)
+
+// migrationDirs service label => directory name under assets/migrations/<driver>
+var migrationDirs = map[string]string{
+	"app":             "apprepo",
+	"serviceProvider": "push_providers_repo",
+	"template":        "templaterepo",
+	"device":          "devicerepo",
+	"topic":           "topicrepo",
+	"callback":        "callbackrepo",
+}
+
+// MigrationServices return all service labels that have migrations, sorted by name.
+func MigrationServices() []string {
+	services := make([]string, 0, len(migrationDirs))
+	for service := range migrationDirs {
+		services = append(services, service)
+	}
+
+	sort.Strings(services)
+	return services
+}
+
+// DBLabel return the database label used by the service label.
+func (c ConfigServices) DBLabel(service string) (dbLabel string, err error) {
+	switch service {
+	case "app":
+		dbLabel = c.App.DBLabel
+	case "serviceProvider":
+		dbLabel = c.ServiceProvider.DBLabel
+	case "template":
+		dbLabel = c.Template.DBLabel
+	case "device":
+		dbLabel = c.Device.DBLabel
+	case "topic":
+		dbLabel = c.Topic.DBLabel
+	case "callback":
+		dbLabel = c.Callback.DBLabel
+	default:
+		err = fmt.Errorf("unknown service '%s'", service)
+	}
+
+	return
+}
+
+// Migrator return migration.Migrator of the service using embedded migrations of the database driver.
+// All services in the same database resource share one schema_migrations table.
+func (r *RepositoryImpl) Migrator(service, dbLabel string) (*migration.Migrator, error) {
+	dir, ok := migrationDirs[service]
+	if !ok {
+		return nil, fmt.Errorf("unknown migration for service '%s'", service)
+	}
+
+	repoConnInfo, ok := r.dbResourceMap[dbLabel]
+	if !ok {
+		return nil, fmt.Errorf("unknown database key %s on migration", dbLabel)
+	}
+
+	sqlDriver := repoConnInfo.Driver
+	switch sqlDriver {
+	case "postgres", "mysql", "sqlite":
+		// mysql only have migrations of app and serviceProvider, the other services is not supported yet
+		sqlConn, err := r.dbSqlConn.GetSqlx(multidb.Driver(sqlDriver), dbLabel)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations, err := migration.Load(assets.Migrations, path.Join("migrations", sqlDriver, dir))
+		if err != nil {
+			return nil, err
+		}
+
+		return migration.New(migration.Config{
+			DB:         sqlConn,
+			Dialect:    sqlDriver,
+			Service:    service,
+			Migrations: migrations,
+		})
+
+	default:
+		return nil, fmt.Errorf("not supported db driver '%s' on label '%s'", sqlDriver, dbLabel)
+	}
+}
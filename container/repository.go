@@ -1,12 +1,22 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicerepo"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templaterepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/uow"
 	"io"
 
 	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+	"github.com/yusufsyaifudin/ngendika/pkg/dblock"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
 	"github.com/yusufsyaifudin/ngendika/pkg/multidb"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"go.uber.org/multierr"
@@ -18,14 +28,32 @@ import (
 type Repositories interface {
 	io.Closer
 
-	AppRepo(dbLabel string) (apprepo.Repo, error)
-	PNProviderRepo(dbLabel string) (pnprepo.Repo, error)
+	// AppRepo return apprepo.CachedRepo when the cache label is configured.
+	AppRepo(dbLabel string, cacheConf ConfigServiceCache) (apprepo.Repo, error)
+	// PNProviderRepo return pnprepo.CachedRepo when the cache label is configured.
+	PNProviderRepo(dbLabel string, cacheConf ConfigServiceCache) (pnprepo.Repo, error)
+	TemplateRepo(dbLabel string) (templaterepo.Repo, error)
+	DeviceRepo(dbLabel string) (devicerepo.Repo, error)
+	TopicRepo(dbLabel string) (topicrepo.Repo, error)
+	CallbackRepo(dbLabel string) (callbackrepo.Repo, error)
+
+	// Lock return advisory lock of the database, so the background job only run on one node at a time.
+	Lock(dbLabel string, id int64) (*dblock.Lock, error)
+
+	// UnitOfWork compose the repositories of services using dbLabel into one transaction.
+	UnitOfWork(dbLabel string, svcCfg ConfigServices) (uow.UnitOfWork, error)
+
+	// HealthChecks return ping check for every enabled database, named db:<dbLabel>,
+	// and every cache that implements cache.Pinger, named cache:<cacheLabel>.
+	HealthChecks() map[string]health.CheckFunc
 }
 
 // RepositoryImpl the real implementation of Repositories
 type RepositoryImpl struct {
 	dbResourceMap ConfigDatabaseResources `validate:"required,structonly"`
 	dbSqlConn     multidb.MultiDB         `validate:"required"` // all database connection
+	caches        map[string]cache.Cache  `validate:"-"`        // all cache connection, by cache label
+	cacheClosers  []io.Closer             `validate:"-"`
 }
 
 // Ensure that RepositoryImpl implements RepositoryImpl
@@ -36,13 +64,15 @@ var _ Repositories = (*RepositoryImpl)(nil)
 // This will return RepositoryImpl instead Repositories,
 // the reason is when SetupRepositories called it must be close in deferred mode, any passed value using interface
 // won't let user Close any dependencies during run-time.
-func SetupRepositories(conf ConfigDatabaseResources) (*RepositoryImpl, error) {
+func SetupRepositories(conf ConfigDatabaseResources, cacheConf ConfigCacheResources) (*RepositoryImpl, error) {
 	sqlDbConfig := multidb.DatabaseResources{}
 	for name, conn := range conf {
 		sqlDbConfig[name] = multidb.DatabaseResource{
 			Disable:  conn.Disable,
 			Driver:   multidb.Driver(conn.Driver),
 			Postgres: multidb.GoSqlDb(conn.Postgres),
+			Mysql:    multidb.GoSqlDb(conn.Mysql),
+			Sqlite:   multidb.GoSqlDb(conn.Sqlite),
 		}
 
 	}
@@ -52,9 +82,36 @@ func SetupRepositories(conf ConfigDatabaseResources) (*RepositoryImpl, error) {
 		return nil, err
 	}
 
+	// export connection pool stats of each database and replica
+	for dbLabel, conn := range dbSqlConn.Connections() {
+		if err = metric.RegisterDBStats(dbLabel, conn.DB); err != nil {
+			_ = dbSqlConn.Close()
+			return nil, fmt.Errorf("cannot register db stats metric '%s': %w", dbLabel, err)
+		}
+	}
+
+	for replicaName, conn := range dbSqlConn.Replicas() {
+		if err = metric.RegisterDBStats(replicaName, conn.DB); err != nil {
+			_ = dbSqlConn.Close()
+			return nil, fmt.Errorf("cannot register db stats metric '%s': %w", replicaName, err)
+		}
+	}
+
+	caches, cacheClosers, err := setupCaches(cacheConf)
+	if err != nil {
+		_ = dbSqlConn.Close()
+		for _, closer := range cacheClosers {
+			_ = closer.Close()
+		}
+
+		return nil, err
+	}
+
 	dep := &RepositoryImpl{
 		dbResourceMap: conf,
 		dbSqlConn:     dbSqlConn,
+		caches:        caches,
+		cacheClosers:  cacheClosers,
 	}
 
 	err = validator.Validate(dep)
@@ -67,65 +124,261 @@ func SetupRepositories(conf ConfigDatabaseResources) (*RepositoryImpl, error) {
 
 // AppRepo return apprepo.Repo and return error when connection is closed or nil.
 // This should never have caused panic.
-func (r *RepositoryImpl) AppRepo(dbLabel string) (appRepo apprepo.Repo, err error) {
-	repoConnInfo, ok := r.dbResourceMap[dbLabel]
-	if !ok {
-		err = fmt.Errorf("unknown database key %s on appRepo", dbLabel)
+func (r *RepositoryImpl) AppRepo(dbLabel string, cacheConf ConfigServiceCache) (appRepo apprepo.Repo, err error) {
+	appCache, err := r.getCache(cacheConf.CacheLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get cache on appRepo: %w", err)
 		return
 	}
 
-	// for type postgres use sqlx, for type mongo use mongodb
-	sqlDriver := repoConnInfo.Driver
-	switch sqlDriver {
-	case "postgres":
-		var sqlConn *sqlx.DB
-		sqlConn, err = r.dbSqlConn.GetSqlx(multidb.Postgres, dbLabel)
-		if err != nil {
-			return
-		}
+	driver, conn, reader, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on appRepo: %w", err)
+		return
+	}
 
-		cfg := apprepo.RepoPostgresConfig{
-			Connection: sqlConn,
-		}
+	appRepo, err = newAppRepo(driver, conn, reader, appCache, cacheConf)
+	return
+}
 
-		appRepo, err = apprepo.Postgres(cfg)
+func (r *RepositoryImpl) PNProviderRepo(dbLabel string, cacheConf ConfigServiceCache) (repo pnprepo.Repo, err error) {
+	pnpCache, err := r.getCache(cacheConf.CacheLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get cache on pnpRepo: %w", err)
 		return
+	}
 
-	default:
-		err = fmt.Errorf("not supported db driver '%s' on label '%s'", sqlDriver, dbLabel)
+	driver, conn, reader, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on pnpRepo: %w", err)
+		return
+	}
+
+	repo, err = newPNProviderRepo(driver, conn, reader, pnpCache, cacheConf)
+	return
+}
+
+func (r *RepositoryImpl) TemplateRepo(dbLabel string) (repo templaterepo.Repo, err error) {
+	driver, conn, _, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on templateRepo: %w", err)
+		return
+	}
+
+	repo, err = newTemplateRepo(driver, conn)
+	return
+}
+
+func (r *RepositoryImpl) DeviceRepo(dbLabel string) (repo devicerepo.Repo, err error) {
+	driver, conn, _, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on deviceRepo: %w", err)
+		return
+	}
+
+	repo, err = newDeviceRepo(driver, conn)
+	return
+}
+
+func (r *RepositoryImpl) TopicRepo(dbLabel string) (repo topicrepo.Repo, err error) {
+	driver, conn, _, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on topicRepo: %w", err)
+		return
+	}
+
+	repo, err = newTopicRepo(driver, conn)
+	return
+}
+
+func (r *RepositoryImpl) CallbackRepo(dbLabel string) (repo callbackrepo.Repo, err error) {
+	driver, conn, _, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on callbackRepo: %w", err)
+		return
+	}
+
+	repo, err = newCallbackRepo(driver, conn)
+	return
+}
+
+func (r *RepositoryImpl) Lock(dbLabel string, id int64) (lock *dblock.Lock, err error) {
+	driver, conn, _, err := r.conn(dbLabel)
+	if err != nil {
+		err = fmt.Errorf("cannot get connection on lock: %w", err)
 		return
 	}
 
+	lock, err = dblock.New(dblock.Config{DB: conn, Dialect: driver, ID: id})
+	return
 }
 
-func (r *RepositoryImpl) PNProviderRepo(dbLabel string) (repo pnprepo.Repo, err error) {
+// conn return the driver name, primary connection and read only connection of the database label.
+func (r *RepositoryImpl) conn(dbLabel string) (driver string, conn *sqlx.DB, reader sqlx.QueryerContext, err error) {
 	repoConnInfo, ok := r.dbResourceMap[dbLabel]
 	if !ok {
-		err = fmt.Errorf("unknown database key %s on fcmRepo", dbLabel)
+		err = fmt.Errorf("unknown database key %s", dbLabel)
 		return
 	}
 
-	// for type postgres use sqlx, for type mongo use mongodb
-	sqlDriver := repoConnInfo.Driver
-	switch sqlDriver {
+	driver = repoConnInfo.Driver
+	conn, err = r.dbSqlConn.GetSqlx(multidb.Driver(driver), dbLabel)
+	if err != nil {
+		return
+	}
+
+	reader, err = r.dbSqlConn.GetReader(multidb.Driver(driver), dbLabel)
+	return
+}
+
+// -- repository per driver, conn is either *sqlx.DB or *sqlx.Tx.
+// For type postgres, mysql and sqlite use sqlx, for type mongo use mongodb.
+
+// newAppRepo wrap the repo using apprepo.CachedRepo when appCache is not nil.
+func newAppRepo(driver string, conn sqlx.ExtContext, reader sqlx.QueryerContext, appCache cache.Cache, cacheConf ConfigServiceCache) (repo apprepo.Repo, err error) {
+	switch driver {
 	case "postgres":
-		var sqlConn *sqlx.DB
-		sqlConn, err = r.dbSqlConn.GetSqlx(multidb.Postgres, dbLabel)
-		if err != nil {
-			return nil, err
-		}
+		repo, err = apprepo.Postgres(apprepo.RepoPostgresConfig{
+			Connection: conn,
+			Reader:     reader,
+		})
 
-		cfg := pnprepo.PostgresConfig{
-			Connection: sqlConn,
-		}
+	case "mysql":
+		repo, err = apprepo.MySQL(apprepo.RepoMySQLConfig{
+			Connection: conn,
+			Reader:     reader,
+		})
+
+	case "sqlite":
+		repo, err = apprepo.SQLite(apprepo.RepoSQLiteConfig{
+			Connection: conn,
+		})
 
-		repo, err = pnprepo.NewPostgres(cfg)
+	default:
+		err = fmt.Errorf("not supported db driver '%s' on appRepo", driver)
+	}
+
+	if err != nil || appCache == nil {
 		return
+	}
+
+	repo, err = apprepo.NewCached(apprepo.CachedConfig{
+		Persistent:     repo,
+		CacheExpiry:    cacheConf.Expiry,
+		CachePrefixKey: cacheConf.Prefix,
+		Cache:          appCache,
+	})
+	return
+}
+
+// newPNProviderRepo wrap the repo using pnprepo.CachedRepo when pnpCache is not nil.
+func newPNProviderRepo(driver string, conn sqlx.ExtContext, reader sqlx.QueryerContext, pnpCache cache.Cache, cacheConf ConfigServiceCache) (repo pnprepo.Repo, err error) {
+	switch driver {
+	case "postgres":
+		repo, err = pnprepo.NewPostgres(pnprepo.PostgresConfig{
+			Connection: conn,
+			Reader:     reader,
+		})
+
+	case "mysql":
+		repo, err = pnprepo.NewMySQL(pnprepo.MySQLConfig{
+			Connection: conn,
+			Reader:     reader,
+		})
+
+	case "sqlite":
+		repo, err = pnprepo.NewSQLite(pnprepo.SQLiteConfig{
+			Connection: conn,
+		})
 
 	default:
-		err = fmt.Errorf("not supported db driver '%s' on label '%s'", sqlDriver, dbLabel)
+		err = fmt.Errorf("not supported db driver '%s' on pnpRepo", driver)
+	}
+
+	if err != nil || pnpCache == nil {
 		return
 	}
+
+	repo, err = pnprepo.NewCached(pnprepo.CachedConfig{
+		Persistent:     repo,
+		CacheExpiry:    cacheConf.Expiry,
+		CachePrefixKey: cacheConf.Prefix,
+		Cache:          pnpCache,
+	})
+	return
+}
+
+func newTemplateRepo(driver string, conn sqlx.ExtContext) (repo templaterepo.Repo, err error) {
+	switch driver {
+	case "postgres":
+		repo, err = templaterepo.NewPostgres(templaterepo.PostgresConfig{Connection: conn})
+	case "sqlite":
+		repo, err = templaterepo.NewSQLite(templaterepo.SQLiteConfig{Connection: conn})
+	default:
+		err = fmt.Errorf("not supported db driver '%s' on templateRepo", driver)
+	}
+
+	return
+}
+
+func newDeviceRepo(driver string, conn sqlx.ExtContext) (repo devicerepo.Repo, err error) {
+	switch driver {
+	case "postgres":
+		repo, err = devicerepo.NewPostgres(devicerepo.PostgresConfig{Connection: conn})
+	case "sqlite":
+		repo, err = devicerepo.NewSQLite(devicerepo.SQLiteConfig{Connection: conn})
+	default:
+		err = fmt.Errorf("not supported db driver '%s' on deviceRepo", driver)
+	}
+
+	return
+}
+
+func newTopicRepo(driver string, conn sqlx.ExtContext) (repo topicrepo.Repo, err error) {
+	switch driver {
+	case "postgres":
+		repo, err = topicrepo.NewPostgres(topicrepo.PostgresConfig{Connection: conn})
+	case "sqlite":
+		repo, err = topicrepo.NewSQLite(topicrepo.SQLiteConfig{Connection: conn})
+	default:
+		err = fmt.Errorf("not supported db driver '%s' on topicRepo", driver)
+	}
+
+	return
+}
+
+func newCallbackRepo(driver string, conn sqlx.ExtContext) (repo callbackrepo.Repo, err error) {
+	switch driver {
+	case "postgres":
+		repo, err = callbackrepo.NewPostgres(callbackrepo.PostgresConfig{Connection: conn})
+	case "sqlite":
+		repo, err = callbackrepo.NewSQLite(callbackrepo.SQLiteConfig{Connection: conn})
+	default:
+		err = fmt.Errorf("not supported db driver '%s' on callbackRepo", driver)
+	}
+
+	return
+}
+
+func (r *RepositoryImpl) HealthChecks() map[string]health.CheckFunc {
+	checks := make(map[string]health.CheckFunc)
+	for dbLabel, conn := range r.dbSqlConn.Connections() {
+		conn := conn
+		checks[fmt.Sprintf("db:%s", dbLabel)] = func(ctx context.Context) error {
+			return conn.PingContext(ctx)
+		}
+	}
+
+	for cacheLabel, c := range r.caches {
+		pinger, ok := c.(cache.Pinger)
+		if !ok {
+			continue
+		}
+
+		checks[fmt.Sprintf("cache:%s", cacheLabel)] = pinger.Ping
+	}
+
+	return checks
 }
 
 // Close will close all dependencies.
@@ -143,5 +396,11 @@ func (r *RepositoryImpl) Close() error {
 		err = multierr.Append(err, fmt.Errorf("close db error: %w", _err))
 	}
 
+	for _, closer := range r.cacheClosers {
+		if _err := closer.Close(); _err != nil {
+			err = multierr.Append(err, fmt.Errorf("close cache error: %w", _err))
+		}
+	}
+
 	return err
 }
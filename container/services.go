@@ -1,28 +1,57 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"github.com/sony/sonyflake"
 	"github.com/yusufsyaifudin/ngendika/backend"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/pnpsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/uow"
+	"github.com/yusufsyaifudin/ngendika/pkg/dblock"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+	"github.com/yusufsyaifudin/ngendika/pkg/httplog"
 	"github.com/yusufsyaifudin/ngendika/pkg/uid"
+	"go.uber.org/multierr"
+	"net/http"
 	"time"
 )
 
+// appPurgeLockID is the advisory lock key of the app purge job, it must differ from the migration lock key.
+const appPurgeLockID = 7210431964
+
 type Services interface {
 	UIDGen() uid.UID
 	App() appsvc.Service
 	PushNotificationProvider() pnpsvc.Service
+	Template() templatesvc.Service
+	Device() devicesvc.Service
+	Topic() topicsvc.Service
+	Callback() callbacksvc.Service
 	Message() msgsvc.Service
+
+	// HealthChecks return check of messaging queue saturation and every registered backend.
+	HealthChecks() map[string]health.CheckFunc
+
+	// Close stop the background jobs.
+	Close() error
 }
 
 type ServicesImpl struct {
-	uidGen uid.UID
-	app    appsvc.Service
-	pnp    pnpsvc.Service
-	msg    msgsvc.Service
+	uidGen   uid.UID
+	app      appsvc.Service
+	pnp      pnpsvc.Service
+	tpl      templatesvc.Service
+	device   devicesvc.Service
+	topic    topicsvc.Service
+	callback callbacksvc.Service
+	msg      msgsvc.Service
+	purger   *appsvc.Purger
 }
 
 var _ Services = (*ServicesImpl)(nil)
@@ -43,16 +72,66 @@ func SetupServices(svcCfg ConfigServices, repos Repositories) (svc *ServicesImpl
 		return
 	}
 
+	// ** Prepare push notification provider repository first, app service cascade the deletion to it
+	pnpRepo, err := repos.PNProviderRepo(svcCfg.ServiceProvider.DBLabel, svcCfg.ServiceProvider.Cache)
+	if err != nil {
+		err = fmt.Errorf("services cannot get pnp repo: %w", err)
+		return
+	}
+
+	// ** Prepare repositories of the other data owned by app, it is purged together with the app
+	templateRepo, err := repos.TemplateRepo(svcCfg.Template.DBLabel)
+	if err != nil {
+		err = fmt.Errorf("services cannot get template repo: %w", err)
+		return
+	}
+
+	deviceRepo, err := repos.DeviceRepo(svcCfg.Device.DBLabel)
+	if err != nil {
+		err = fmt.Errorf("services cannot get device repo: %w", err)
+		return
+	}
+
+	topicRepo, err := repos.TopicRepo(svcCfg.Topic.DBLabel)
+	if err != nil {
+		err = fmt.Errorf("services cannot get topic repo: %w", err)
+		return
+	}
+
+	callbackRepo, err := repos.CallbackRepo(svcCfg.Callback.DBLabel)
+	if err != nil {
+		err = fmt.Errorf("services cannot get callback repo: %w", err)
+		return
+	}
+
 	// ** Prepare app service at once
-	appRepo, err := repos.AppRepo(svcCfg.App.DBLabel)
+	appRepo, err := repos.AppRepo(svcCfg.App.DBLabel, svcCfg.App.Cache)
 	if err != nil {
 		err = fmt.Errorf("services cannot get app repo: %w", err)
 		return
 	}
 
+	// app and its providers is only deleted atomically when both is using the same database
+	var appUnitOfWork uow.UnitOfWork
+	if svcCfg.App.DBLabel == svcCfg.ServiceProvider.DBLabel {
+		appUnitOfWork, err = repos.UnitOfWork(svcCfg.App.DBLabel, svcCfg)
+		if err != nil {
+			err = fmt.Errorf("services cannot get app unit of work: %w", err)
+			return
+		}
+	}
+
 	appService, err := appsvc.New(appsvc.DefaultServiceConfig{
-		UIDGen:  uidGen,
-		AppRepo: appRepo,
+		UIDGen:           uidGen,
+		AppRepo:          appRepo,
+		PnpRepo:          pnpRepo,
+		TemplateRepo:     templateRepo,
+		DeviceRepo:       deviceRepo,
+		TopicRepo:        topicRepo,
+		CallbackRepo:     callbackRepo,
+		UnitOfWork:       appUnitOfWork,
+		RestoreRetention: svcCfg.App.Deletion.Retention,
+		PurgeArchive:     svcCfg.App.Deletion.Purge == "archive",
 	})
 	if err != nil {
 		err = fmt.Errorf("services cannot get prepare app service: %w", err)
@@ -60,12 +139,6 @@ func SetupServices(svcCfg ConfigServices, repos Repositories) (svc *ServicesImpl
 	}
 
 	// ** Prepare push notification provider service at once
-	pnpRepo, err := repos.PNProviderRepo(svcCfg.ServiceProvider.DBLabel)
-	if err != nil {
-		err = fmt.Errorf("services cannot get pnp repo: %w", err)
-		return
-	}
-
 	pnpSvc, err := pnpsvc.New(pnpsvc.Config{
 		UIDGen:  uidGen,
 		PnpRepo: pnpRepo,
@@ -75,10 +148,71 @@ func SetupServices(svcCfg ConfigServices, repos Repositories) (svc *ServicesImpl
 		return
 	}
 
+	// ** Prepare message template service at once
+	templateSvc, err := templatesvc.New(templatesvc.Config{
+		UIDGen:       uidGen,
+		TemplateRepo: templateRepo,
+	})
+	if err != nil {
+		err = fmt.Errorf("services cannot get prepare template service: %w", err)
+		return
+	}
+
+	// ** Prepare device registry service at once
+	deviceSvc, err := devicesvc.New(devicesvc.Config{
+		UIDGen:     uidGen,
+		DeviceRepo: deviceRepo,
+	})
+	if err != nil {
+		err = fmt.Errorf("services cannot get prepare device service: %w", err)
+		return
+	}
+
+	// ** Prepare topic subscription service at once
+	topicSvc, err := topicsvc.New(topicsvc.Config{
+		UIDGen:    uidGen,
+		TopicRepo: topicRepo,
+		DeviceSvc: deviceSvc,
+	})
+	if err != nil {
+		err = fmt.Errorf("services cannot get prepare topic service: %w", err)
+		return
+	}
+
+	// ** Prepare callback service at once, the dispatcher workers is started here
+	httpLogOut, err := httplog.New(httplog.WithBase(callbacksvc.NewTransport(svcCfg.Callback.AllowPrivateNetwork)))
+	if err != nil {
+		err = fmt.Errorf("services cannot prepare callback http log: %w", err)
+		return
+	}
+
+	callbackSvc, err := callbacksvc.New(callbacksvc.Config{
+		UIDGen:       uidGen,
+		CallbackRepo: callbackRepo,
+		HTTPClient: &http.Client{
+			Transport: httpLogOut,
+			Timeout:   svcCfg.Callback.Timeout,
+		},
+		MaxBuffer:   svcCfg.Callback.MaxBuffer,
+		MaxWorker:   svcCfg.Callback.MaxWorker,
+		MaxAttempts: svcCfg.Callback.MaxAttempts,
+		Backoff:     svcCfg.Callback.Backoff,
+
+		AllowPrivateNetwork: svcCfg.Callback.AllowPrivateNetwork,
+	})
+	if err != nil {
+		err = fmt.Errorf("services cannot get prepare callback service: %w", err)
+		return
+	}
+
 	// ** prepare message service
 	msgSvc, err := msgsvc.New(msgsvc.SvcSyncConfig{
 		AppSvc:        appService,
 		PNProviderSvc: pnpSvc,
+		TemplateSvc:   templateSvc,
+		DeviceSvc:     deviceSvc,
+		TopicSvc:      topicSvc,
+		CallbackSvc:   callbackSvc,
 		PNSender:      backend.MuxBackend(),
 		MaxBuffer:     svcCfg.Messaging.MaxBuffer,
 		MaxWorker:     svcCfg.Messaging.MaxParallel,
@@ -88,11 +222,37 @@ func SetupServices(svcCfg ConfigServices, repos Repositories) (svc *ServicesImpl
 		return
 	}
 
+	// ** start the app purge job last, so it is not leaked when other service failed
+	var appPurger *appsvc.Purger
+	if svcCfg.App.Deletion.Purge != "" {
+		var purgeLock *dblock.Lock
+		purgeLock, err = repos.Lock(svcCfg.App.DBLabel, appPurgeLockID)
+		if err != nil {
+			err = fmt.Errorf("services cannot get app purge lock: %w", err)
+			return
+		}
+
+		appPurger, err = appsvc.NewPurger(appsvc.PurgerConfig{
+			Service:  appService,
+			Interval: svcCfg.App.Deletion.PurgeInterval,
+			Lock:     purgeLock,
+		})
+		if err != nil {
+			err = fmt.Errorf("services cannot get prepare app purger: %w", err)
+			return
+		}
+	}
+
 	svc = &ServicesImpl{
-		uidGen: uidGen,
-		app:    appService,
-		pnp:    pnpSvc,
-		msg:    msgSvc,
+		uidGen:   uidGen,
+		app:      appService,
+		pnp:      pnpSvc,
+		tpl:      templateSvc,
+		device:   deviceSvc,
+		topic:    topicSvc,
+		callback: callbackSvc,
+		msg:      msgSvc,
+		purger:   appPurger,
 	}
 
 	return svc, nil
@@ -110,6 +270,47 @@ func (s *ServicesImpl) PushNotificationProvider() pnpsvc.Service {
 	return s.pnp
 }
 
+func (s *ServicesImpl) Template() templatesvc.Service {
+	return s.tpl
+}
+
+func (s *ServicesImpl) Device() devicesvc.Service {
+	return s.device
+}
+
+func (s *ServicesImpl) Topic() topicsvc.Service {
+	return s.topic
+}
+
+func (s *ServicesImpl) Callback() callbacksvc.Service {
+	return s.callback
+}
+
 func (s *ServicesImpl) Message() msgsvc.Service {
 	return s.msg
 }
+
+func (s *ServicesImpl) HealthChecks() map[string]health.CheckFunc {
+	checks := map[string]health.CheckFunc{
+		"messaging:queue": s.msg.HealthCheck,
+	}
+
+	mux := backend.MuxBackend()
+	for _, provider := range mux.ListProviders(context.Background()) {
+		provider := provider
+		checks[fmt.Sprintf("backend:%s", provider)] = func(ctx context.Context) error {
+			return mux.HealthCheck(ctx, provider)
+		}
+	}
+
+	return checks
+}
+
+func (s *ServicesImpl) Close() (err error) {
+	if s.purger != nil {
+		err = multierr.Append(err, s.purger.Close())
+	}
+
+	err = multierr.Append(err, s.callback.Close())
+	return
+}
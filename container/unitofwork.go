@@ -0,0 +1,199 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicerepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templaterepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/uow"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+	"github.com/yusufsyaifudin/ngendika/pkg/multidb"
+	"github.com/yusufsyaifudin/ylog"
+)
+
+// UnitOfWork return uow.UnitOfWork of the database label.
+// Only repository of the service in svcCfg that is using the same database label can be used in the transaction.
+func (r *RepositoryImpl) UnitOfWork(dbLabel string, svcCfg ConfigServices) (uow.UnitOfWork, error) {
+	driver, conn, _, err := r.conn(dbLabel)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get connection on unit of work: %w", err)
+	}
+
+	return &unitOfWork{
+		repos:   r,
+		dbLabel: dbLabel,
+		driver:  driver,
+		conn:    conn,
+		svcCfg:  svcCfg,
+	}, nil
+}
+
+type unitOfWork struct {
+	repos   *RepositoryImpl
+	dbLabel string
+	driver  string
+	conn    *sqlx.DB
+	svcCfg  ConfigServices
+}
+
+var _ uow.UnitOfWork = (*unitOfWork)(nil)
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos uow.Repositories) error) error {
+	txRepos := &txRepositories{uow: u}
+	err := multidb.InTx(ctx, u.conn, func(conn sqlx.ExtContext) error {
+		txRepos.conn = conn
+		return fn(ctx, txRepos)
+	})
+	if err != nil {
+		return err
+	}
+
+	// use new context, so the eviction still run when ctx is canceled right after commit
+	evictCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, c := range txRepos.caches {
+		c.flush(evictCtx)
+	}
+
+	return nil
+}
+
+// txRepositories create the repository on demand using the transaction connection.
+type txRepositories struct {
+	uow    *unitOfWork
+	conn   sqlx.ExtContext
+	caches []*txCache
+}
+
+var _ uow.Repositories = (*txRepositories)(nil)
+
+func (t *txRepositories) AppRepo() (apprepo.Repo, error) {
+	cacheConf := t.uow.svcCfg.App.Cache
+	appCache, err := t.cache("app", t.uow.svcCfg.App.DBLabel, cacheConf)
+	if err != nil {
+		return nil, err
+	}
+
+	// no reader, so the read query use the transaction on the primary and see the write before it is committed
+	return newAppRepo(t.uow.driver, t.conn, nil, appCache, cacheConf)
+}
+
+func (t *txRepositories) PNProviderRepo() (pnprepo.Repo, error) {
+	cacheConf := t.uow.svcCfg.ServiceProvider.Cache
+	pnpCache, err := t.cache("serviceProvider", t.uow.svcCfg.ServiceProvider.DBLabel, cacheConf)
+	if err != nil {
+		return nil, err
+	}
+
+	// no reader, same as AppRepo
+	return newPNProviderRepo(t.uow.driver, t.conn, nil, pnpCache, cacheConf)
+}
+
+func (t *txRepositories) TemplateRepo() (templaterepo.Repo, error) {
+	if err := t.sameDB("template", t.uow.svcCfg.Template.DBLabel); err != nil {
+		return nil, err
+	}
+
+	return newTemplateRepo(t.uow.driver, t.conn)
+}
+
+func (t *txRepositories) DeviceRepo() (devicerepo.Repo, error) {
+	if err := t.sameDB("device", t.uow.svcCfg.Device.DBLabel); err != nil {
+		return nil, err
+	}
+
+	return newDeviceRepo(t.uow.driver, t.conn)
+}
+
+func (t *txRepositories) TopicRepo() (topicrepo.Repo, error) {
+	if err := t.sameDB("topic", t.uow.svcCfg.Topic.DBLabel); err != nil {
+		return nil, err
+	}
+
+	return newTopicRepo(t.uow.driver, t.conn)
+}
+
+func (t *txRepositories) CallbackRepo() (callbackrepo.Repo, error) {
+	if err := t.sameDB("callback", t.uow.svcCfg.Callback.DBLabel); err != nil {
+		return nil, err
+	}
+
+	return newCallbackRepo(t.uow.driver, t.conn)
+}
+
+func (t *txRepositories) sameDB(service, dbLabel string) error {
+	if dbLabel != t.uow.dbLabel {
+		return fmt.Errorf("service '%s' is using database '%s', not '%s' of the unit of work", service, dbLabel, t.uow.dbLabel)
+	}
+
+	return nil
+}
+
+// cache return nil when the service is not using cache.
+func (t *txRepositories) cache(service, dbLabel string, cacheConf ConfigServiceCache) (c cache.Cache, err error) {
+	if err = t.sameDB(service, dbLabel); err != nil {
+		return
+	}
+
+	c, err = t.uow.repos.getCache(cacheConf.CacheLabel)
+	if err != nil || c == nil {
+		return
+	}
+
+	txc := &txCache{cache: c, keys: make(map[string]struct{})}
+	t.caches = append(t.caches, txc)
+	c = txc
+	return
+}
+
+// txCache never read nor write the cache during transaction, so the repository always query the transaction.
+// Every written key is evicted after commit instead, and nothing is evicted on rollback.
+type txCache struct {
+	cache cache.Cache
+	lock  sync.Mutex
+	keys  map[string]struct{}
+}
+
+var _ cache.Cache = (*txCache)(nil)
+
+func (t *txCache) GetAs(_ context.Context, _ string, _ interface{}) error {
+	return cache.ErrKeyNotExist
+}
+
+func (t *txCache) SetExp(_ context.Context, key string, _ interface{}, _ time.Duration) error {
+	t.add(key)
+	return nil
+}
+
+func (t *txCache) Delete(_ context.Context, key string) error {
+	t.add(key)
+	return nil
+}
+
+func (t *txCache) add(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.keys[key] = struct{}{}
+}
+
+// flush only log the error, since the transaction is already committed.
+func (t *txCache) flush(ctx context.Context) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for key := range t.keys {
+		if err := t.cache.Delete(ctx, key); err != nil {
+			ylog.Error(ctx, fmt.Sprintf("cannot evict cache %s after commit", key), ylog.KV("error", err))
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicerepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templaterepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/uow"
+)
+
+func TestUnitOfWork_Do(t *testing.T) {
+	ctx := context.Background()
+	dsn := "file:" + filepath.Join(t.TempDir(), "ngendika.db") + "?_foreign_keys=on"
+	repos, err := SetupRepositories(
+		ConfigDatabaseResources{"db1": {Driver: "sqlite", Sqlite: ConfigGoSqlDb{DSN: dsn}}},
+		ConfigCacheResources{"local": {Driver: "inmemory"}},
+	)
+	assert.NoError(t, err)
+	defer repos.Close()
+
+	migrator, err := repos.Migrator("app", "db1")
+	assert.NoError(t, err)
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+
+	svcCfg := ConfigServices{App: ConfigServiceApp{
+		DBLabel: "db1",
+		Cache:   ConfigServiceCache{CacheLabel: "local", Expiry: time.Minute, Prefix: "app"},
+	}}
+
+	appRepo, err := repos.AppRepo("db1", svcCfg.App.Cache)
+	assert.NoError(t, err)
+
+	_, err = appRepo.Create(ctx, apprepo.InputCreate{App: apprepo.App{ID: 1, ClientID: "app1", Name: "app 1", CreatedAt: 1, UpdatedAt: 1}})
+	assert.NoError(t, err)
+
+	unitOfWork, err := repos.UnitOfWork("db1", svcCfg)
+	assert.NoError(t, err)
+
+	upsert := func(name string, fail error) error {
+		return unitOfWork.Do(ctx, func(ctx context.Context, txRepos uow.Repositories) error {
+			txAppRepo, err := txRepos.AppRepo()
+			if err != nil {
+				return err
+			}
+
+			_, err = txAppRepo.Upsert(ctx, apprepo.InputUpsert{App: apprepo.App{ID: 2, ClientID: "app1", Name: name, CreatedAt: 2, UpdatedAt: 2}})
+			if err != nil {
+				return err
+			}
+
+			return fail
+		})
+	}
+
+	errFail := errors.New("fail")
+	assert.ErrorIs(t, upsert("rolled back", errFail), errFail)
+
+	out, err := appRepo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "app 1", out.App.Name)
+
+	// cached app is evicted after commit
+	assert.NoError(t, upsert("committed", nil))
+
+	out, err = appRepo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "committed", out.App.Name)
+
+	// template service is not using db1
+	assert.Error(t, unitOfWork.Do(ctx, func(ctx context.Context, txRepos uow.Repositories) error {
+		_, err := txRepos.TemplateRepo()
+		return err
+	}))
+}
+
+type seqUID struct {
+	next uint64
+}
+
+func (s *seqUID) NextID() (uint64, error) {
+	s.next++
+	return s.next, nil
+}
+
+func TestUnitOfWork_AppDeletion(t *testing.T) {
+	ctx := context.Background()
+	dsn := "file:" + filepath.Join(t.TempDir(), "ngendika.db") + "?_foreign_keys=on"
+	repos, err := SetupRepositories(
+		ConfigDatabaseResources{"db1": {Driver: "sqlite", Sqlite: ConfigGoSqlDb{DSN: dsn}}},
+		ConfigCacheResources{"local": {Driver: "inmemory"}},
+	)
+	assert.NoError(t, err)
+	defer repos.Close()
+
+	for _, service := range MigrationServices() {
+		migrator, err := repos.Migrator(service, "db1")
+		assert.NoError(t, err)
+		_, err = migrator.Up(ctx, 0)
+		assert.NoError(t, err)
+	}
+
+	svcCfg := ConfigServices{
+		App: ConfigServiceApp{DBLabel: "db1"},
+		ServiceProvider: ConfigServicePushProvider{
+			DBLabel: "db1",
+			Cache:   ConfigServiceCache{CacheLabel: "local", Expiry: time.Minute, Prefix: "pnp"},
+		},
+	}
+
+	appRepo, err := repos.AppRepo("db1", svcCfg.App.Cache)
+	assert.NoError(t, err)
+
+	pnpRepo, err := repos.PNProviderRepo("db1", svcCfg.ServiceProvider.Cache)
+	assert.NoError(t, err)
+
+	templateRepo, err := repos.TemplateRepo("db1")
+	assert.NoError(t, err)
+	deviceRepo, err := repos.DeviceRepo("db1")
+	assert.NoError(t, err)
+	topicRepo, err := repos.TopicRepo("db1")
+	assert.NoError(t, err)
+	callbackRepo, err := repos.CallbackRepo("db1")
+	assert.NoError(t, err)
+
+	unitOfWork, err := repos.UnitOfWork("db1", svcCfg)
+	assert.NoError(t, err)
+
+	appService, err := appsvc.New(appsvc.DefaultServiceConfig{
+		UIDGen:       &seqUID{},
+		AppRepo:      appRepo,
+		PnpRepo:      pnpRepo,
+		TemplateRepo: templateRepo,
+		DeviceRepo:   deviceRepo,
+		TopicRepo:    topicRepo,
+		CallbackRepo: callbackRepo,
+		UnitOfWork:   unitOfWork,
+	})
+	assert.NoError(t, err)
+
+	created, err := appService.CreateApp(ctx, appsvc.InputCreateApp{ClientID: "app1", Name: "app 1"})
+	assert.NoError(t, err)
+
+	_, err = pnpRepo.Insert(ctx, pnprepo.InputInsert{PnProvider: pnprepo.PushNotificationProvider{
+		ID: 1, AppID: created.App.ID, Provider: "fcm", Label: "driver", CredentialJSON: "{}", CreatedAt: 1, UpdatedAt: 1,
+	}})
+	assert.NoError(t, err)
+
+	getProviders := func() []pnprepo.PushNotificationProvider {
+		out, err := pnpRepo.GetByLabels(ctx, pnprepo.InGetByLabels{AppID: created.App.ID, Provider: "fcm", Labels: []string{"driver"}})
+		assert.NoError(t, err)
+		return out.PnProvider
+	}
+
+	// cache the provider before delete
+	assert.Len(t, getProviders(), 1)
+
+	// suspended app is refused when only enabled app is requested
+	_, err = appService.SuspendApp(ctx, appsvc.InputSuspendApp{ClientID: "app1", Reason: "abuse"})
+	assert.NoError(t, err)
+
+	enabled := true
+	_, err = appService.GetApp(ctx, appsvc.InputGetApp{ClientID: "app1", Enabled: &enabled})
+	assert.ErrorIs(t, err, appsvc.ErrAppSuspended)
+
+	resumed, err := appService.ResumeApp(ctx, appsvc.InputResumeApp{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.True(t, resumed.App.Enabled)
+
+	withSettings, err := appService.PutAppSettings(ctx, appsvc.InputPutAppSettings{
+		ClientID: "app1",
+		Settings: appsvc.AppSettings{DailySendQuota: 1, DefaultTTLs: map[string]time.Duration{"fcm": time.Hour}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, withSettings.App.Settings.DefaultTTLs["fcm"])
+
+	quotaIn := appsvc.InputUseDailyQuota{AppID: created.App.ID, Quota: withSettings.App.Settings.DailySendQuota, Count: 1}
+	_, err = appService.UseDailyQuota(ctx, quotaIn)
+	assert.NoError(t, err)
+
+	_, err = appService.UseDailyQuota(ctx, quotaIn)
+	assert.ErrorIs(t, err, appsvc.ErrQuotaExceeded)
+
+	deleted, err := appService.DelApp(ctx, appsvc.InputDelApp{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.True(t, deleted.Success)
+	assert.Len(t, getProviders(), 0)
+
+	restored, err := appService.RestoreApp(ctx, appsvc.InputRestoreApp{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.Equal(t, created.App.ID, restored.App.ID)
+	assert.Len(t, getProviders(), 1)
+
+	// nothing is deleted before the retention
+	purged, err := appService.PurgeApps(ctx, appsvc.InputPurgeApps{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, purged.Purged)
+
+	_, err = appService.RestoreApp(ctx, appsvc.InputRestoreApp{ClientID: "app1"})
+	assert.Error(t, err)
+
+	// the other data owned by app
+	appID := created.App.ID
+	_, err = templateRepo.Insert(ctx, templaterepo.InputInsert{Template: templaterepo.Template{
+		ID: 1, AppID: appID, Name: "welcome", Engine: "text", DefaultLocale: "en", VariantsJSON: "{}", CreatedAt: 1, UpdatedAt: 1,
+	}})
+	assert.NoError(t, err)
+	_, err = deviceRepo.Upsert(ctx, devicerepo.InputUpsert{Device: devicerepo.Device{
+		ID: 1, AppID: appID, UserID: "user1", Provider: "fcm", Token: "token1", LastSeenAt: 1, CreatedAt: 1, UpdatedAt: 1,
+	}})
+	assert.NoError(t, err)
+	_, err = topicRepo.Subscribe(ctx, topicrepo.InputSubscribe{Subscriptions: []topicrepo.Subscription{{
+		ID: 1, AppID: appID, Topic: "news", MemberType: "user", Member: "user1", CreatedAt: 1,
+	}}})
+	assert.NoError(t, err)
+	_, err = callbackRepo.InsertCallback(ctx, callbackrepo.InputInsertCallback{Callback: callbackrepo.Callback{
+		ID: 1, AppID: appID, URL: "https://example.com", Secret: "secret", Events: "task.completed", CreatedAt: 1, UpdatedAt: 1,
+	}})
+	assert.NoError(t, err)
+	_, err = callbackRepo.InsertDelivery(ctx, callbackrepo.InputInsertDelivery{Delivery: callbackrepo.Delivery{
+		ID: 1, CallbackID: 1, AppID: appID, Event: "task.completed", Payload: "{}", Status: "pending", CreatedAt: 1, UpdatedAt: 1,
+	}})
+	assert.NoError(t, err)
+
+	_, conn, _, err := repos.conn("db1")
+	assert.NoError(t, err)
+
+	tables := []string{"message_templates", "devices", "topic_subscriptions", "callbacks", "callback_deliveries", "app_daily_usages"}
+	countRows := func() map[string]int {
+		counts := make(map[string]int)
+		for _, table := range tables {
+			var count int
+			assert.NoError(t, conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM "+table+" WHERE app_id = ?;", appID))
+			counts[table] = count
+		}
+		return counts
+	}
+
+	for table, count := range countRows() {
+		assert.Equal(t, 1, count, table)
+	}
+
+	// app deleted before the retention is purged with its providers and the other data
+	appService.Config.RestoreRetention = time.Microsecond
+	_, err = appService.DelApp(ctx, appsvc.InputDelApp{ClientID: "app1"})
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	purged, err = appService.PurgeApps(ctx, appsvc.InputPurgeApps{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, purged.Purged)
+
+	for table, count := range countRows() {
+		assert.Equal(t, 0, count, table)
+	}
+
+	_, err = appService.RestoreApp(ctx, appsvc.InputRestoreApp{ClientID: "app1"})
+	assert.Error(t, err)
+}
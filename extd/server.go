@@ -7,20 +7,20 @@ import (
 	"github.com/yusufsyaifudin/ngendika/backend"
 	"github.com/yusufsyaifudin/ngendika/backend/befcm"
 	"github.com/yusufsyaifudin/ngendika/container"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
 	"github.com/yusufsyaifudin/ngendika/pkg/httplog"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/transport/grpcapi"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi"
 	"github.com/yusufsyaifudin/ylog"
-	jaegerPropagator "go.opentelemetry.io/contrib/propagators/jaeger"
-	"go.opentelemetry.io/contrib/propagators/ot"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/jaeger"
-	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -36,26 +36,36 @@ func RunServer(ctx context.Context, cfg container.Config) (err error) {
 
 	ctx = setupLog(ctx)
 
-	exp, err := jaeger.New(
-		jaeger.WithCollectorEndpoint(jaeger.WithEndpoint("http://localhost:14268/api/traces")),
-	)
+	// ** setup tracing exporter
+	samplingRatio := 1.0
+	if cfg.Tracing.SamplingRatio != nil {
+		samplingRatio = *cfg.Tracing.SamplingRatio
+	}
+
+	shutdownTracer, err := tracer.Setup(ctx, tracer.Config{
+		Exporter:           cfg.Tracing.Exporter,
+		Endpoint:           cfg.Tracing.Endpoint,
+		Insecure:           cfg.Tracing.Insecure,
+		Headers:            cfg.Tracing.Headers,
+		SamplingRatio:      samplingRatio,
+		ResourceAttributes: cfg.Tracing.ResourceAttributes,
+		Propagators:        cfg.Tracing.Propagators,
+	})
 	if err != nil {
-		ylog.Error(ctx, "cannot setup jaeger exporter", ylog.KV("error", err))
+		ylog.Error(ctx, "cannot setup tracing", ylog.KV("error", err))
 		return
 	}
 
-	tracer.InitTraceProvider(exp)
-
-	// register ot propagator
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		&ot.OT{},
-		&jaegerPropagator.Jaeger{},
-	))
+	defer func() {
+		if _err := shutdownTracer(context.Background()); _err != nil {
+			ylog.Error(ctx, "closing tracer: failed", ylog.KV("error", _err))
+		}
+	}()
 
 	// ** setup repositories
 	ylog.Info(ctx, "container preparation: starting")
 	var repositories container.Repositories
-	repositories, err = container.SetupRepositories(cfg.DatabaseResources)
+	repositories, err = container.SetupRepositories(cfg.DatabaseResources, cfg.CacheResources)
 	defer func() {
 		ylog.Info(ctx, "closing container: starting")
 		if repositories == nil {
@@ -85,14 +95,59 @@ func RunServer(ctx context.Context, cfg container.Config) (err error) {
 		return
 	}
 
+	defer func() {
+		if _err := services.Close(); _err != nil {
+			ylog.Error(ctx, "closing services: failed", ylog.KV("error", _err))
+		}
+	}()
+
 	// ** HTTP TRANSPORT
 	ylog.Info(ctx, "transport preparation: starting")
+	apiKeys := make([]apikey.APIKey, 0)
+	for _, key := range cfg.Auth.APIKeys {
+		scopes := make([]apikey.Scope, 0)
+		for _, scope := range key.Scopes {
+			scopes = append(scopes, apikey.Scope(scope))
+		}
+
+		apiKeys = append(apiKeys, apikey.APIKey{
+			Name:   key.Name,
+			Key:    key.Key,
+			Scopes: scopes,
+		})
+	}
+
+	if len(apiKeys) <= 0 {
+		ylog.Info(ctx, "http transport: no api key configured, all routes are not protected")
+	}
+
+	// ** readiness checks of all dependencies
+	healthChecks := repositories.HealthChecks()
+	for name, check := range services.HealthChecks() {
+		healthChecks[name] = check
+	}
+
+	healthChecker, err := health.New(health.Config{
+		Checks: healthChecks,
+	})
+	if err != nil {
+		ylog.Error(ctx, "health check preparation: failed", ylog.KV("error", err))
+		return
+	}
+
 	serverConfig := restapi.Config{
-		AppServiceName: "app name",
-		AppVersion:     "1.0.0",
-		AppService:     services.App(),
-		PNPService:     services.PushNotificationProvider(),
-		MsgService:     services.Message(),
+		AppServiceName:  "app name",
+		AppVersion:      "1.0.0",
+		AppService:      services.App(),
+		PNPService:      services.PushNotificationProvider(),
+		TemplateService: services.Template(),
+		DeviceService:   services.Device(),
+		TopicService:    services.Topic(),
+		CallbackService: services.Callback(),
+		MsgService:      services.Message(),
+		Health:          healthChecker,
+		APIKeys:         apiKeys,
+		StreamTimeout:   cfg.Transport.HTTP.StreamTimeout,
 	}
 
 	ylog.Info(ctx, "http transport: starting")
@@ -109,7 +164,39 @@ func RunServer(ctx context.Context, cfg container.Config) (err error) {
 		Handler: h2c.NewHandler(server.Server(), h2s), // HTTP/2 Cleartext handler
 	}
 
-	var apiErrChan = make(chan error, 1)
+	var apiErrChan = make(chan error, 2)
+
+	// ** gRPC TRANSPORT, only when port is configured
+	var grpcServer *grpc.Server
+	if cfg.Transport.GRPC.Port > 0 {
+		ylog.Info(ctx, "grpc transport: starting")
+		grpcTransport, _err := grpcapi.NewGRPCTransport(grpcapi.Config{
+			AppService: services.App(),
+			PNPService: services.PushNotificationProvider(),
+			MsgService: services.Message(),
+			Health:     healthChecker,
+			APIKeys:    apiKeys,
+		})
+		if _err != nil {
+			err = _err
+			ylog.Error(ctx, "grpc transport: failed", ylog.KV("error", err))
+			return
+		}
+
+		grpcListener, _err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Transport.GRPC.Port))
+		if _err != nil {
+			err = fmt.Errorf("grpc transport: cannot listen port %d: %w", cfg.Transport.GRPC.Port, _err)
+			ylog.Error(ctx, "grpc transport: failed", ylog.KV("error", err))
+			return
+		}
+
+		grpcServer = grpcTransport.Server()
+		go func() {
+			ylog.Info(ctx, fmt.Sprintf("grpc transport: done running on port %d", cfg.Transport.GRPC.Port))
+			apiErrChan <- grpcServer.Serve(grpcListener)
+		}()
+	}
+
 	go func() {
 		ylog.Info(ctx, fmt.Sprintf("http transport: done running on port %d", cfg.Transport.HTTP.Port))
 		apiErrChan <- httpServer.ListenAndServe()
@@ -128,9 +215,18 @@ func RunServer(ctx context.Context, cfg container.Config) (err error) {
 			ylog.Error(ctx, "http transport: ", ylog.KV("error", _err))
 		}
 
+		if grpcServer != nil {
+			ylog.Info(ctx, "grpc transport: exiting...")
+			grpcServer.GracefulStop()
+		}
+
 	case err := <-apiErrChan:
 		if err != nil {
-			ylog.Info(ctx, "http transport: error", ylog.KV("error", err))
+			ylog.Info(ctx, "transport: error", ylog.KV("error", err))
+		}
+
+		if grpcServer != nil {
+			grpcServer.Stop()
 		}
 	}
 
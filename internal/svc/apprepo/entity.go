@@ -1,5 +1,11 @@
 package apprepo
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
 // App save all apps and it's connection info relation
 // Json tag is used for caching.
 type App struct {
@@ -7,8 +13,63 @@ type App struct {
 	ClientID string `json:"client_id" db:"client_id" validate:"required"` // unique
 	Name     string `json:"name" db:"name" validate:"required"`
 
+	// Enabled is false when the app is suspended, with the reason and time of suspension
+	Enabled         bool   `json:"enabled" db:"enabled" validate:"-"`
+	SuspendedReason string `json:"suspended_reason" db:"suspended_reason" validate:"-"`
+	SuspendedAt     int64  `json:"suspended_at" db:"suspended_at" validate:"-"`
+
+	Settings Settings `json:"settings" db:"settings" validate:"-"`
+
 	// Timestamp using integer as unix microsecond in UTC
 	CreatedAt int64 `json:"created_at" db:"created_at" validate:"required"`
 	UpdatedAt int64 `json:"updated_at" db:"updated_at" validate:"required"`
 	DeletedAt int64 `json:"deleted_at" db:"deleted_at" validate:"-"`
 }
+
+// Settings is per app settings which saved as JSON column, zero value means not set.
+type Settings struct {
+	DefaultLabel     string   `json:"default_label,omitempty"`
+	AllowedProviders []string `json:"allowed_providers,omitempty"` // empty means all providers are allowed
+	DailySendQuota   int64    `json:"daily_send_quota,omitempty"`  // recipients per day, zero means unlimited
+
+	// DefaultTTLs is default message time to live in seconds per provider, i.e: {"fcm": 3600}
+	DefaultTTLs map[string]int64 `json:"default_ttls,omitempty"`
+
+	ContactEmail string `json:"contact_email,omitempty"`
+}
+
+var _ driver.Valuer = Settings{}
+
+// Value save Settings as JSON string, so it works on JSONB (Postgres), JSON (MySQL) and TEXT (SQLite) column.
+func (s Settings) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal app settings: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// Scan read JSON column, the driver may return it as []byte or string.
+func (s *Settings) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*s = Settings{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan app settings from type %T", src)
+	}
+
+	settings := Settings{}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return fmt.Errorf("cannot unmarshal app settings: %w", err)
+	}
+
+	*s = settings
+	return nil
+}
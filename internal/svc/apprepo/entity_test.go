@@ -13,7 +13,7 @@ func TestNewApp(t *testing.T) {
 		ClientID:  strings.ToLower("abc"),
 		Name:      "abc",
 		Enabled:   true,
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: time.Now().UTC().UnixMicro(),
 	}
 	assert.Equal(t, "abc", app.ClientID)
 }
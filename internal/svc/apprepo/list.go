@@ -0,0 +1,94 @@
+package apprepo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
+)
+
+// ListSortFields is the sort field of List, the first one is the default.
+var ListSortFields = []string{"id", "name", "created_at"}
+
+// listQuery return the query of List and the count query of its filter using "?" bind var,
+// the query is the same for all databases. Empty in.Sort is set to the default sort.
+func listQuery(in *InputList) (query string, args []interface{}, countQuery string, countArgs []interface{}, err error) {
+	if in.Sort, err = pagination.ParseSort(in.Sort.String(), ListSortFields...); err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	if in.Cursor != nil && in.Cursor.Sort != in.Sort.String() {
+		err = fmt.Errorf("%w: %s: cursor is for sort '%s', not '%s'",
+			ErrValidation, pagination.ErrInvalidCursor, in.Cursor.Sort, in.Sort.String())
+		return
+	}
+
+	keyset := pagination.Keyset{Column: in.Sort.Field, IDColumn: "id", Desc: in.Sort.Desc, Cursor: in.Cursor}
+	if in.Cursor != nil {
+		keyset.Key, err = listCursorKey(in.Sort.Field, in.Cursor.Key)
+		if err != nil {
+			return
+		}
+	}
+
+	conds := []string{"deleted_at = 0"}
+	if in.ClientIDPrefix != "" {
+		conds = append(conds, "client_id LIKE ? ESCAPE '"+pagination.LikeEscape+"'")
+		countArgs = append(countArgs, pagination.LikePrefix(strings.ToLower(in.ClientIDPrefix)))
+	}
+
+	if in.NamePrefix != "" {
+		conds = append(conds, "LOWER(name) LIKE ? ESCAPE '"+pagination.LikeEscape+"'")
+		countArgs = append(countArgs, pagination.LikePrefix(strings.ToLower(in.NamePrefix)))
+	}
+
+	countQuery = fmt.Sprintf("SELECT COUNT(*) AS total FROM apps WHERE %s;", strings.Join(conds, " AND "))
+
+	args = append(args, countArgs...)
+	if cond, keysetArgs := keyset.Where(); cond != "" {
+		conds = append(conds, cond)
+		args = append(args, keysetArgs...)
+	}
+
+	// fetch one more row to know whether there is more page
+	args = append(args, in.Limit+1)
+	query = fmt.Sprintf("SELECT * FROM apps WHERE %s ORDER BY %s LIMIT ?;", strings.Join(conds, " AND "), keyset.OrderBy())
+	return
+}
+
+// listCursorKey convert pagination.Cursor Key into the column type of the sort field.
+func listCursorKey(field, key string) (interface{}, error) {
+	switch field {
+	case "created_at":
+		createdAt, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrValidation, pagination.ErrInvalidCursor, err)
+		}
+
+		return createdAt, nil
+	default:
+		return key, nil
+	}
+}
+
+// listOut return the page of apps fetched by listQuery.
+func listOut(apps []App, in InputList) OutList {
+	page := pagination.Paginate(apps, in.Limit, in.Sort, in.Cursor, func(app App) (string, int64) {
+		switch in.Sort.Field {
+		case "name":
+			return app.Name, app.ID
+		case "created_at":
+			return strconv.FormatInt(app.CreatedAt, 10), app.ID
+		default:
+			return "", app.ID
+		}
+	})
+
+	return OutList{
+		Apps: page.Items,
+		Next: page.Next,
+		Prev: page.Prev,
+	}
+}
@@ -3,6 +3,8 @@ package apprepo
 import (
 	"context"
 	"errors"
+
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
 )
 
 var (
@@ -16,6 +18,13 @@ type Repo interface {
 	GetByClientID(ctx context.Context, in InputGetByClientID) (out OutGetByClientID, err error)
 	List(ctx context.Context, in InputList) (out OutList, err error)
 	DelByClientID(ctx context.Context, in InputDelByClientID) (out OutDelByClientID, err error)
+	SetEnabled(ctx context.Context, in InputSetEnabled) (out OutSetEnabled, err error)
+	SetSettings(ctx context.Context, in InputSetSettings) (out OutSetSettings, err error)
+	IncrDailyUsage(ctx context.Context, in InputIncrDailyUsage) (out OutIncrDailyUsage, err error)
+	GetDeletedByClientID(ctx context.Context, in InputGetDeletedByClientID) (out OutGetDeletedByClientID, err error)
+	Restore(ctx context.Context, in InputRestore) (out OutRestore, err error)
+	ListDeleted(ctx context.Context, in InputListDeleted) (out OutListDeleted, err error)
+	Purge(ctx context.Context, in InputPurge) (out OutPurge, err error)
 }
 
 type InputCreate struct {
@@ -34,24 +43,31 @@ type OutUpsert struct {
 	App App
 }
 
+// InputGetByClientID return the app regardless it is enabled or suspended.
 type InputGetByClientID struct {
 	ClientID string `validate:"required,lowercase"`
-	Enabled  *bool  `validate:"-"`
 }
 
 type OutGetByClientID struct {
 	App App
 }
 
+// InputList Sort is one of ListSortFields, the Cursor must be created for the same Sort.
+// NamePrefix and ClientIDPrefix is case-insensitive, and Total is only counted when WithTotal is true.
 type InputList struct {
-	Limit    int64 `validate:"required"`
-	BeforeID int64 `validate:"min=0"`
-	AfterID  int64 `validate:"min=0"`
+	Limit          int64              `validate:"required,min=1"`
+	Sort           pagination.Sort    `validate:"-"`
+	Cursor         *pagination.Cursor `validate:"-"`
+	NamePrefix     string             `validate:"-"`
+	ClientIDPrefix string             `validate:"-"`
+	WithTotal      bool               `validate:"-"`
 }
 
 type OutList struct {
 	Total int64
 	Apps  []App
+	Next  *pagination.Cursor
+	Prev  *pagination.Cursor
 }
 
 type InputDelByClientID struct {
@@ -62,3 +78,93 @@ type InputDelByClientID struct {
 type OutDelByClientID struct {
 	Success bool
 }
+
+// InputSetEnabled suspend the app with the Reason when Enabled is false, or resume it otherwise.
+type InputSetEnabled struct {
+	ClientID  string `validate:"required,lowercase"`
+	Enabled   bool   `validate:"-"`
+	Reason    string `validate:"required_if=Enabled false"`
+	UpdatedAt int64  `validate:"required"`
+}
+
+// suspension return the reason and time of suspension to save.
+func (in InputSetEnabled) suspension() (reason string, suspendedAt int64) {
+	if in.Enabled {
+		return
+	}
+
+	return in.Reason, in.UpdatedAt
+}
+
+type OutSetEnabled struct {
+	Success bool
+	App     App
+}
+
+// InputSetSettings replace all settings of the app.
+type InputSetSettings struct {
+	ClientID  string   `validate:"required,lowercase"`
+	Settings  Settings `validate:"-"`
+	UpdatedAt int64    `validate:"required"`
+}
+
+type OutSetSettings struct {
+	Success bool
+	App     App
+}
+
+// InputIncrDailyUsage add Count into the usage of the app on the Day (start of the day in unix microsecond UTC),
+// only when the total after added is not more than Quota.
+type InputIncrDailyUsage struct {
+	AppID int64 `validate:"required"`
+	Day   int64 `validate:"required"`
+	Count int64 `validate:"required,min=1,ltefield=Quota"`
+	Quota int64 `validate:"required,min=1"`
+}
+
+// OutIncrDailyUsage Total is the usage after added, it is only set when Allowed.
+type OutIncrDailyUsage struct {
+	Allowed bool
+	Total   int64
+}
+
+// InputGetDeletedByClientID get the latest deleted app which deleted at or after DeletedAfter.
+type InputGetDeletedByClientID struct {
+	ClientID     string `validate:"required,lowercase"`
+	DeletedAfter int64  `validate:"required"`
+}
+
+type OutGetDeletedByClientID struct {
+	App App
+}
+
+// InputRestore only restore the app when it is still deleted at DeletedAt.
+type InputRestore struct {
+	ID        int64 `validate:"required"`
+	DeletedAt int64 `validate:"required"`
+	UpdatedAt int64 `validate:"required"`
+}
+
+type OutRestore struct {
+	Success bool
+	App     App
+}
+
+// InputListDeleted list the deleted app which deleted before DeletedBefore, the oldest first.
+type InputListDeleted struct {
+	DeletedBefore int64 `validate:"required"`
+	Limit         int64 `validate:"required"`
+}
+
+type OutListDeleted struct {
+	Apps []App
+}
+
+// InputPurge permanently delete the app and its daily usages, only app that already deleted can be purged.
+type InputPurge struct {
+	ID int64 `validate:"required"`
+}
+
+type OutPurge struct {
+	Success bool
+}
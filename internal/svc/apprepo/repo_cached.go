@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"github.com/yusufsyaifudin/ylog"
 	"time"
@@ -12,10 +13,13 @@ import (
 type CachedConfig struct {
 	Persistent     Repo          `validate:"required"`
 	CacheExpiry    time.Duration `validate:"required"`
-	CachePrefixKey string        `validate:"required,alphanumeric"`
+	CachePrefixKey string        `validate:"required,alphanum"`
 	Cache          cache.Cache   `validate:"required"`
 }
 
+// CachedRepo cache each app by client id, the cache is only filled on read.
+// Every write must evict the key instead of overwrite it, so when Cache is cache.Broadcast
+// the eviction is also published to the other nodes.
 type CachedRepo struct {
 	Config CachedConfig
 }
@@ -53,8 +57,8 @@ func (c *CachedRepo) Create(ctx context.Context, in InputCreate) (out OutCreate,
 		return
 	}
 
-	// if ok, save to cache
-	c.setByClientID(ctx, out.App)
+	// evict instead of save, so when Cache is cache.Broadcast the other nodes also drop the old data
+	c.evictByClientID(ctx, out.App.ClientID)
 	return
 }
 
@@ -71,8 +75,8 @@ func (c *CachedRepo) Upsert(ctx context.Context, in InputUpsert) (out OutUpsert,
 		return
 	}
 
-	// if ok, save to cache
-	c.setByClientID(ctx, out.App)
+	// evict instead of save, so when Cache is cache.Broadcast the other nodes also drop the old data
+	c.evictByClientID(ctx, out.App.ClientID)
 	return
 }
 
@@ -80,9 +84,13 @@ func (c *CachedRepo) GetByClientID(ctx context.Context, in InputGetByClientID) (
 	// Get from cache first
 	app, err := c.getByClientID(ctx, in.ClientID)
 	if err == nil && app.ClientID == in.ClientID {
+		metric.CacheRequests.WithLabelValues(c.Config.CachePrefixKey, metric.CacheHit).Inc()
+		out = OutGetByClientID{App: app}
 		return
 	}
 
+	metric.CacheRequests.WithLabelValues(c.Config.CachePrefixKey, metric.CacheMiss).Inc()
+
 	// If error occurred, then try get from persistent storage
 	if err != nil {
 		ylog.Error(ctx, fmt.Sprintf("app client id %s error get from cache", in.ClientID), ylog.KV("error", err))
@@ -96,7 +104,7 @@ func (c *CachedRepo) GetByClientID(ctx context.Context, in InputGetByClientID) (
 	}
 
 	// Try cache, only log when error
-	c.setByClientID(ctx, app)
+	c.setByClientID(ctx, out.App)
 	return
 }
 
@@ -115,6 +123,58 @@ func (c *CachedRepo) DelByClientID(ctx context.Context, in InputDelByClientID) (
 	return
 }
 
+// SetEnabled evict the cache instead of overwrite it,
+// so when Cache is cache.Broadcast the other nodes also stop using the old status.
+func (c *CachedRepo) SetEnabled(ctx context.Context, in InputSetEnabled) (out OutSetEnabled, err error) {
+	out, err = c.Config.Persistent.SetEnabled(ctx, in)
+	if err != nil {
+		return
+	}
+
+	err = c.delByClientID(ctx, in.ClientID)
+	return
+}
+
+// SetSettings evict the cache like SetEnabled, so the other nodes also use the new settings.
+func (c *CachedRepo) SetSettings(ctx context.Context, in InputSetSettings) (out OutSetSettings, err error) {
+	out, err = c.Config.Persistent.SetSettings(ctx, in)
+	if err != nil {
+		return
+	}
+
+	err = c.delByClientID(ctx, in.ClientID)
+	return
+}
+
+// IncrDailyUsage is not using cache, the usage must be counted atomically by the database.
+func (c *CachedRepo) IncrDailyUsage(ctx context.Context, in InputIncrDailyUsage) (out OutIncrDailyUsage, err error) {
+	return c.Config.Persistent.IncrDailyUsage(ctx, in)
+}
+
+func (c *CachedRepo) GetDeletedByClientID(ctx context.Context, in InputGetDeletedByClientID) (out OutGetDeletedByClientID, err error) {
+	return c.Config.Persistent.GetDeletedByClientID(ctx, in)
+}
+
+func (c *CachedRepo) Restore(ctx context.Context, in InputRestore) (out OutRestore, err error) {
+	out, err = c.Config.Persistent.Restore(ctx, in)
+	if err != nil || !out.Success {
+		return
+	}
+
+	// evict instead of save, so when Cache is cache.Broadcast the other nodes also drop the old data
+	c.evictByClientID(ctx, out.App.ClientID)
+	return
+}
+
+// ListDeleted and Purge is not using cache, because deleted app is already evicted.
+func (c *CachedRepo) ListDeleted(ctx context.Context, in InputListDeleted) (out OutListDeleted, err error) {
+	return c.Config.Persistent.ListDeleted(ctx, in)
+}
+
+func (c *CachedRepo) Purge(ctx context.Context, in InputPurge) (out OutPurge, err error) {
+	return c.Config.Persistent.Purge(ctx, in)
+}
+
 // -- cache
 
 func (c *CachedRepo) genCacheKeyByClientID(clientID string) string {
@@ -143,6 +203,13 @@ func (c *CachedRepo) setByClientID(ctx context.Context, app App) {
 	return
 }
 
+// evictByClientID is delByClientID which only log the error, since the write is already persisted.
+func (c *CachedRepo) evictByClientID(ctx context.Context, clientID string) {
+	if err := c.delByClientID(ctx, clientID); err != nil {
+		ylog.Error(ctx, fmt.Sprintf("cannot evict cache app id %s", clientID), ylog.KV("error", err))
+	}
+}
+
 func (c *CachedRepo) delByClientID(ctx context.Context, clientID string) error {
 	return c.Config.Cache.Delete(ctx, c.genCacheKeyByClientID(clientID))
 }
@@ -0,0 +1,58 @@
+package apprepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+)
+
+// setCounter count the SetExp, so the test can assert write only evict the cache.
+type setCounter struct {
+	cache.Cache
+	sets int
+}
+
+func (s *setCounter) SetExp(ctx context.Context, key string, inValue interface{}, expireDur time.Duration) error {
+	s.sets++
+	return s.Cache.SetExp(ctx, key, inValue, expireDur)
+}
+
+func TestCachedRepo(t *testing.T) {
+	ctx := context.Background()
+
+	local, err := cache.NewInMemory()
+	assert.NoError(t, err)
+
+	counter := &setCounter{Cache: local}
+	repo, err := apprepo.NewCached(apprepo.CachedConfig{
+		Persistent:     newSQLite(t),
+		CacheExpiry:    time.Minute,
+		CachePrefixKey: "app",
+		Cache:          counter,
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.Create(ctx, apprepo.InputCreate{App: apprepo.App{ID: 1, ClientID: "app1", Name: "app 1", CreatedAt: 1, UpdatedAt: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, counter.sets)
+
+	// cache is filled on read
+	got, err := repo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "app 1", got.App.Name)
+	assert.Equal(t, 1, counter.sets)
+
+	// upsert evict the cache, so the next read get the new name from the persistent storage
+	_, err = repo.Upsert(ctx, apprepo.InputUpsert{App: apprepo.App{ID: 1, ClientID: "app1", Name: "app 1 renamed", CreatedAt: 1, UpdatedAt: 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, counter.sets)
+
+	got, err = repo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: "app1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "app 1 renamed", got.App.Name)
+	assert.Equal(t, 2, counter.sets)
+}
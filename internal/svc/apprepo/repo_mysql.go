@@ -0,0 +1,410 @@
+package apprepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// MySQL does not support RETURNING, so every write is followed by select query.
+const (
+	sqlMysqlCreateApp        = `INSERT INTO apps (id, client_id, name, settings, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?);`
+	sqlMysqlGetAppByID       = `SELECT * FROM apps WHERE id = ? LIMIT 1;`
+	sqlMysqlGetAppByClientID = `SELECT * FROM apps WHERE client_id = ? AND deleted_at = 0 LIMIT 1;`
+
+	// conflict only happen on not deleted app, because new app always have deleted_at = 0
+	sqlMysqlUpsertApp = `
+		INSERT INTO apps (id, client_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE
+		    name = new.name,
+		    updated_at = new.updated_at;
+`
+
+	// MySQL cannot select the same table in the sub query of UPDATE, but UPDATE support LIMIT
+	sqlMysqlSoftDeleteApp  = `UPDATE apps SET deleted_at = ? WHERE client_id = ? AND deleted_at = 0 LIMIT 1;`
+	sqlMysqlSetAppEnabled  = `UPDATE apps SET enabled = ?, suspended_reason = ?, suspended_at = ?, updated_at = ? WHERE client_id = ? AND deleted_at = 0 LIMIT 1;`
+	sqlMysqlSetAppSettings = `UPDATE apps SET settings = ?, updated_at = ? WHERE client_id = ? AND deleted_at = 0 LIMIT 1;`
+
+	// the total is not changed when it exceeds the quota, so the affected rows is 0 (1 when inserted, 2 when updated)
+	sqlMysqlIncrAppDailyUsage = `
+		INSERT INTO app_daily_usages (app_id, day, total) VALUES (?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE
+		    total = IF(app_daily_usages.total + new.total <= ?, app_daily_usages.total + new.total, app_daily_usages.total);
+`
+	sqlMysqlGetAppDailyUsage = `SELECT total FROM app_daily_usages WHERE app_id = ? AND day = ? LIMIT 1;`
+
+	sqlMysqlGetDeletedAppByClientID = `SELECT * FROM apps WHERE client_id = ? AND deleted_at >= ? ORDER BY deleted_at DESC LIMIT 1;`
+	sqlMysqlRestoreApp              = `UPDATE apps SET deleted_at = 0, updated_at = ? WHERE id = ? AND deleted_at = ?;`
+	sqlMysqlListDeletedApps         = `SELECT * FROM apps WHERE deleted_at > 0 AND deleted_at < ? ORDER BY deleted_at ASC LIMIT ?;`
+	sqlMysqlPurgeApp                = `DELETE FROM apps WHERE id = ? AND deleted_at > 0;`
+	// sqlMysqlPurgeAppDailyUsages only delete the usages of deleted app, it is deleted before the app so it is not left
+	sqlMysqlPurgeAppDailyUsages = `DELETE FROM app_daily_usages WHERE app_id = ? AND EXISTS (SELECT 1 FROM apps WHERE id = ? AND deleted_at > 0);`
+)
+
+type RepoMySQLConfig struct {
+	Connection sqlx.ExtContext     `validate:"required"`
+	Reader     sqlx.QueryerContext `validate:"-"` // for read only query, default to Connection
+}
+
+type RepoMySQL struct {
+	Config RepoMySQLConfig
+}
+
+var _ Repo = (*RepoMySQL)(nil)
+
+// MySQL return repo interface which implements using MySQL 8
+func MySQL(conf RepoMySQLConfig) (service *RepoMySQL, err error) {
+	err = validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Reader == nil {
+		conf.Reader = conf.Connection
+	}
+
+	service = &RepoMySQL{
+		Config: conf,
+	}
+	return
+}
+
+func (p *RepoMySQL) Create(ctx context.Context, in InputCreate) (out OutCreate, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	app := in.App
+	app.ClientID = strings.TrimSpace(strings.ToLower(app.ClientID))
+
+	_, err = p.Config.Connection.ExecContext(ctx, sqlMysqlCreateApp,
+		in.App.ID, app.ClientID, app.Name, app.Settings, app.CreatedAt, app.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	insertedApp := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &insertedApp, sqlMysqlGetAppByID, in.App.ID)
+	if err != nil {
+		return
+	}
+
+	out = OutCreate{
+		App: insertedApp,
+	}
+	return
+}
+
+func (p *RepoMySQL) Upsert(ctx context.Context, in InputUpsert) (out OutUpsert, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	app := in.App
+	app.ClientID = strings.TrimSpace(strings.ToLower(app.ClientID))
+
+	_, err = p.Config.Connection.ExecContext(ctx, sqlMysqlUpsertApp,
+		in.App.ID, app.ClientID, app.Name, app.CreatedAt, app.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	// when updated, the id is the existing one, not in.App.ID
+	insertedApp := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &insertedApp, sqlMysqlGetAppByClientID, app.ClientID)
+	if err != nil {
+		return
+	}
+
+	out = OutUpsert{
+		App: insertedApp,
+	}
+	return
+}
+
+func (p *RepoMySQL) GetByClientID(ctx context.Context, in InputGetByClientID) (out OutGetByClientID, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "apprepo.GetByClientID")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Reader, &appData, sqlMysqlGetAppByClientID, in.ClientID)
+	if err != nil {
+		return
+	}
+
+	out = OutGetByClientID{
+		App: appData,
+	}
+	return
+}
+
+// List is using keyset pagination, the cursor row is not included in the result.
+func (p *RepoMySQL) List(ctx context.Context, in InputList) (out OutList, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	query, args, countQuery, countArgs, err := listQuery(&in)
+	if err != nil {
+		return
+	}
+
+	appData := make([]App, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Reader, &appData, query, args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of apps: %w", err)
+		return
+	}
+
+	out = listOut(appData, in)
+	if !in.WithTotal {
+		return
+	}
+
+	count := struct {
+		Total int64 `db:"total"`
+	}{}
+	err = sqlx.GetContext(ctx, p.Config.Reader, &count, countQuery, countArgs...)
+	if err != nil {
+		err = fmt.Errorf("cannot count list of apps: %w", err)
+		return
+	}
+
+	out.Total = count.Total
+	return
+}
+
+func (p *RepoMySQL) DelByClientID(ctx context.Context, in InputDelByClientID) (out OutDelByClientID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlMysqlSoftDeleteApp, in.DeletedAt, in.ClientID)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	out = OutDelByClientID{
+		Success: affected == 1,
+	}
+	return
+}
+
+func (p *RepoMySQL) SetEnabled(ctx context.Context, in InputSetEnabled) (out OutSetEnabled, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	reason, suspendedAt := in.suspension()
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlMysqlSetAppEnabled,
+		in.Enabled, reason, suspendedAt, in.UpdatedAt, in.ClientID,
+	)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected != 1 {
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlMysqlGetAppByClientID, in.ClientID)
+	if err != nil {
+		return
+	}
+
+	out = OutSetEnabled{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoMySQL) SetSettings(ctx context.Context, in InputSetSettings) (out OutSetSettings, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlMysqlSetAppSettings, in.Settings, in.UpdatedAt, in.ClientID)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected != 1 {
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlMysqlGetAppByClientID, in.ClientID)
+	if err != nil {
+		return
+	}
+
+	out = OutSetSettings{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoMySQL) IncrDailyUsage(ctx context.Context, in InputIncrDailyUsage) (out OutIncrDailyUsage, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlMysqlIncrAppDailyUsage, in.AppID, in.Day, in.Count, in.Quota)
+	if err != nil {
+		err = fmt.Errorf("cannot increment daily usage of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return // quota exceeded
+	}
+
+	var total int64
+	err = sqlx.GetContext(ctx, p.Config.Connection, &total, sqlMysqlGetAppDailyUsage, in.AppID, in.Day)
+	if err != nil {
+		err = fmt.Errorf("cannot get daily usage of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutIncrDailyUsage{
+		Allowed: true,
+		Total:   total,
+	}
+	return
+}
+
+func (p *RepoMySQL) GetDeletedByClientID(ctx context.Context, in InputGetDeletedByClientID) (out OutGetDeletedByClientID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlMysqlGetDeletedAppByClientID, in.ClientID, in.DeletedAfter)
+	if err != nil {
+		return
+	}
+
+	out = OutGetDeletedByClientID{
+		App: appData,
+	}
+	return
+}
+
+func (p *RepoMySQL) Restore(ctx context.Context, in InputRestore) (out OutRestore, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlMysqlRestoreApp, in.UpdatedAt, in.ID, in.DeletedAt)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected != 1 {
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlMysqlGetAppByID, in.ID)
+	if err != nil {
+		return
+	}
+
+	out = OutRestore{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoMySQL) ListDeleted(ctx context.Context, in InputListDeleted) (out OutListDeleted, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := make([]App, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlMysqlListDeletedApps, in.DeletedBefore, in.Limit)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of deleted apps: %w", err)
+		return
+	}
+
+	out = OutListDeleted{
+		Apps: appData,
+	}
+	return
+}
+
+func (p *RepoMySQL) Purge(ctx context.Context, in InputPurge) (out OutPurge, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	_, err = p.Config.Connection.ExecContext(ctx, sqlMysqlPurgeAppDailyUsages, in.ID, in.ID)
+	if err != nil {
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlMysqlPurgeApp, in.ID)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	out = OutPurge{
+		Success: affected == 1,
+	}
+	return
+}
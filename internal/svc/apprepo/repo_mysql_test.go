@@ -0,0 +1,111 @@
+package apprepo_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+)
+
+// newMySQL return new database in the MySQL 8 server of MYSQL_TEST_DSN, i.e: root:mysql@tcp(localhost:3306)/
+// migrated using the embedded mysql migrations. The test is skipped when MYSQL_TEST_DSN is not defined.
+func newMySQL(t *testing.T) *apprepo.RepoMySQL {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not defined")
+	}
+
+	ctx := context.Background()
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_TEST_DSN: %s", err)
+	}
+
+	server, err := sqlx.Open("mysql", cfg.FormatDSN())
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	cfg.DBName = fmt.Sprintf("ngendika_apprepo_%d", time.Now().UnixNano())
+	_, err = server.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s;", cfg.DBName))
+	if err != nil {
+		t.Fatalf("cannot create test database: %s", err)
+	}
+
+	t.Cleanup(func() { _, _ = server.ExecContext(ctx, fmt.Sprintf("DROP DATABASE %s;", cfg.DBName)) })
+
+	db, err := sqlx.Open("mysql", cfg.FormatDSN())
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := migration.Load(assets.Migrations, "migrations/mysql/apprepo")
+	assert.NoError(t, err)
+
+	migrator, err := migration.New(migration.Config{DB: db, Dialect: "mysql", Service: "app", Migrations: migrations})
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+
+	repo, err := apprepo.MySQL(apprepo.RepoMySQLConfig{Connection: db})
+	assert.NoError(t, err)
+	return repo
+}
+
+func TestMySQL(t *testing.T) {
+	testRepo(t, newMySQL(t))
+}
+
+func TestMySQL_List(t *testing.T) {
+	testList(t, newMySQL(t))
+}
+
+// TestMySQL_RowsAffected make sure the MySQL affected rows (changed rows, not matched rows) is handled.
+func TestMySQL_RowsAffected(t *testing.T) {
+	ctx := context.Background()
+	repo := newMySQL(t)
+
+	_, err := repo.Create(ctx, apprepo.InputCreate{App: apprepo.App{ID: 1, ClientID: "app1", Name: "app 1", CreatedAt: 1, UpdatedAt: 1}})
+	assert.NoError(t, err)
+
+	// ON DUPLICATE KEY UPDATE with the same values is not changing the row, but the app is still returned
+	upserted, err := repo.Upsert(ctx, apprepo.InputUpsert{App: apprepo.App{ID: 2, ClientID: "app1", Name: "app 1", CreatedAt: 1, UpdatedAt: 1}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, upserted.App.ID)
+
+	// the first usage is inserted (1 affected row), the next is updated (2 affected rows)
+	usageIn := apprepo.InputIncrDailyUsage{AppID: 1, Day: 1, Count: 2, Quota: 3}
+	usage, err := repo.IncrDailyUsage(ctx, usageIn)
+	assert.NoError(t, err)
+	assert.True(t, usage.Allowed)
+	assert.EqualValues(t, 2, usage.Total)
+
+	// exceeding the quota is not changing the row (0 affected row)
+	usage, err = repo.IncrDailyUsage(ctx, usageIn)
+	assert.NoError(t, err)
+	assert.False(t, usage.Allowed)
+
+	usageIn.Count = 1
+	usage, err = repo.IncrDailyUsage(ctx, usageIn)
+	assert.NoError(t, err)
+	assert.True(t, usage.Allowed)
+	assert.EqualValues(t, 3, usage.Total)
+
+	// unknown app is not updated
+	out, err := repo.SetSettings(ctx, apprepo.InputSetSettings{ClientID: "unknown", Settings: apprepo.Settings{DailySendQuota: 1}, UpdatedAt: 2})
+	assert.NoError(t, err)
+	assert.False(t, out.Success)
+
+	settings := apprepo.Settings{DefaultLabel: "default", AllowedProviders: []string{"fcm"}, DailySendQuota: 10}
+	out, err = repo.SetSettings(ctx, apprepo.InputSetSettings{ClientID: "app1", Settings: settings, UpdatedAt: 2})
+	assert.NoError(t, err)
+	assert.True(t, out.Success)
+	assert.Equal(t, settings, out.App.Settings)
+}
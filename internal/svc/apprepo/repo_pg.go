@@ -13,7 +13,7 @@ import (
 )
 
 const (
-	sqlCreateApp        = `INSERT INTO apps (id, client_id, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING *;`
+	sqlCreateApp        = `INSERT INTO apps (id, client_id, name, settings, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;`
 	sqlGetAppByClientID = `SELECT * FROM apps WHERE LOWER(client_id) = $1 AND deleted_at = 0 LIMIT 1;`
 
 	sqlUpsertApp = `
@@ -26,20 +26,40 @@ const (
 		RETURNING *;
 `
 
-	sqlListAppsCount        = `SELECT COUNT(*) as total FROM apps WHERE deleted_at = 0;`
-	sqlListAppsWithoutRange = `SELECT * FROM apps WHERE deleted_at = 0 ORDER BY id ASC LIMIT $1;`
-	sqlListAppsWithRange    = `SELECT * FROM apps WHERE (id > $1 AND id < $2) AND deleted_at = 0 ORDER BY id ASC LIMIT $3;`
-	sqlListAppsAfterID      = `SELECT * FROM apps WHERE id > $1 AND deleted_at = 0 ORDER BY id ASC LIMIT $2;`
+	sqlSoftDeleteApp = `UPDATE apps SET deleted_at = $1 WHERE id = (SELECT id FROM apps WHERE LOWER(apps.client_id) = $2 AND apps.deleted_at = 0 LIMIT 1) RETURNING *;`
 
-	// sorting to ASC, this to ensure for example we have limit 5 and before_id 12
-	// we may have [11, 10, 9, 8, 7] from database (DESC)
-	// to make it consistent, we reverse to ASC order [7, 8, 9, 10, 11]
-	sqlListAppsBeforeID = `SELECT * FROM (SELECT * FROM apps WHERE id < $1 AND deleted_at = 0 ORDER BY id DESC LIMIT $2) AS tmp ORDER BY tmp.id ASC;`
-	sqlSoftDeleteApp    = `UPDATE apps SET deleted_at = $1 WHERE id = (SELECT id FROM apps WHERE LOWER(apps.client_id) = $2 AND apps.deleted_at = 0 LIMIT 1) RETURNING *;`
+	sqlSetAppEnabled = `
+		UPDATE apps SET enabled = $1, suspended_reason = $2, suspended_at = $3, updated_at = $4
+		WHERE id = (SELECT id FROM apps WHERE LOWER(apps.client_id) = $5 AND apps.deleted_at = 0 LIMIT 1)
+		RETURNING *;
+`
+
+	sqlSetAppSettings = `
+		UPDATE apps SET settings = $1, updated_at = $2
+		WHERE id = (SELECT id FROM apps WHERE LOWER(apps.client_id) = $3 AND apps.deleted_at = 0 LIMIT 1)
+		RETURNING *;
+`
+
+	// no row is returned when the total exceeds the quota
+	sqlIncrAppDailyUsage = `
+		INSERT INTO app_daily_usages (app_id, day, total) VALUES ($1, $2, $3)
+		ON CONFLICT (app_id, day)
+		DO UPDATE SET total = app_daily_usages.total + EXCLUDED.total
+		WHERE app_daily_usages.total + EXCLUDED.total <= $4
+		RETURNING total;
+`
+
+	sqlGetDeletedAppByClientID = `SELECT * FROM apps WHERE LOWER(client_id) = $1 AND deleted_at >= $2 ORDER BY deleted_at DESC LIMIT 1;`
+	sqlRestoreApp              = `UPDATE apps SET deleted_at = 0, updated_at = $1 WHERE id = $2 AND deleted_at = $3 RETURNING *;`
+	sqlListDeletedApps         = `SELECT * FROM apps WHERE deleted_at > 0 AND deleted_at < $1 ORDER BY deleted_at ASC LIMIT $2;`
+	sqlPurgeApp                = `DELETE FROM apps WHERE id = $1 AND deleted_at > 0;`
+	// sqlPurgeAppDailyUsages only delete the usages of deleted app, it is deleted before the app so it is not left
+	sqlPurgeAppDailyUsages = `DELETE FROM app_daily_usages WHERE app_id = $1 AND EXISTS (SELECT 1 FROM apps WHERE id = $1 AND deleted_at > 0);`
 )
 
 type RepoPostgresConfig struct {
-	Connection sqlx.QueryerContext `validate:"required"`
+	Connection sqlx.ExtContext     `validate:"required"`
+	Reader     sqlx.QueryerContext `validate:"-"` // for read only query, default to Connection
 }
 
 type RepoPostgres struct {
@@ -55,6 +75,10 @@ func Postgres(conf RepoPostgresConfig) (service *RepoPostgres, err error) {
 		return nil, err
 	}
 
+	if conf.Reader == nil {
+		conf.Reader = conf.Connection
+	}
+
 	service = &RepoPostgres{
 		Config: conf,
 	}
@@ -73,7 +97,7 @@ func (p *RepoPostgres) Create(ctx context.Context, in InputCreate) (out OutCreat
 
 	insertedApp := App{}
 	err = sqlx.GetContext(ctx, p.Config.Connection, &insertedApp, sqlCreateApp,
-		in.App.ID, app.ClientID, app.Name, app.CreatedAt, app.UpdatedAt,
+		in.App.ID, app.ClientID, app.Name, app.Settings, app.CreatedAt, app.UpdatedAt,
 	)
 
 	if err != nil {
@@ -123,7 +147,7 @@ func (p *RepoPostgres) GetByClientID(ctx context.Context, in InputGetByClientID)
 	}
 
 	appData := App{}
-	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlGetAppByClientID, in.ClientID)
+	err = sqlx.GetContext(ctx, p.Config.Reader, &appData, sqlGetAppByClientID, in.ClientID)
 	if err != nil {
 		return
 	}
@@ -134,7 +158,7 @@ func (p *RepoPostgres) GetByClientID(ctx context.Context, in InputGetByClientID)
 	return
 }
 
-// List all query is exclusive, means that before_id and after_id will not be in the result
+// List is using keyset pagination, the query is written with "?" so it is rebind into "$1".
 func (p *RepoPostgres) List(ctx context.Context, in InputList) (out OutList, err error) {
 	err = validator.Validate(in)
 	if err != nil {
@@ -142,83 +166,153 @@ func (p *RepoPostgres) List(ctx context.Context, in InputList) (out OutList, err
 		return
 	}
 
+	query, args, countQuery, countArgs, err := listQuery(&in)
+	if err != nil {
+		return
+	}
+
+	appData := make([]App, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Reader, &appData, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of apps: %w", err)
+		return
+	}
+
+	out = listOut(appData, in)
+	if !in.WithTotal {
+		return
+	}
+
 	count := struct {
 		Total int64 `db:"total"`
 	}{}
-	err = sqlx.GetContext(ctx, p.Config.Connection, &count, sqlListAppsCount)
+	err = sqlx.GetContext(ctx, p.Config.Reader, &count, sqlx.Rebind(sqlx.DOLLAR, countQuery), countArgs...)
 	if err != nil {
 		err = fmt.Errorf("cannot count list of apps: %w", err)
 		return
 	}
 
-	if count.Total <= 0 {
+	out.Total = count.Total
+	return
+}
+
+func (p *RepoPostgres) DelByClientID(ctx context.Context, in InputDelByClientID) (out OutDelByClientID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
 		return
 	}
 
-	appData := make([]App, 0)
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSoftDeleteApp, in.DeletedAt, in.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutDelByClientID{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	out = OutDelByClientID{
+		Success: appData.ClientID == in.ClientID && appData.DeletedAt == in.DeletedAt,
+	}
+	return
+}
 
-	switch {
-	case in.BeforeID == 0 && in.AfterID == 0:
-		err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlListAppsWithoutRange, in.Limit)
-
-	case in.BeforeID == 0 && in.AfterID != 0:
-		// in: before_id = 0 and after_id = 100
-		// sql: (id > 100)
-		err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlListAppsAfterID, in.AfterID, in.Limit)
-
-	case in.BeforeID != 0 && in.AfterID == 0:
-		// in: before_id = 100 and after_id = 0
-		// sql: (id < 100)
-		err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlListAppsBeforeID, in.BeforeID, in.Limit)
-
-	case in.AfterID > in.BeforeID:
-		// in: before_id = 10 and after_id = 12
-		// cannot do this, because:
-		// before id 10 we have 9, 8, 7, ... 0
-		// after id 12 we have 13, 14, 15, ... inf+
-		// So, the query is error!
-		prevData := in.BeforeID
-		previous := make([]string, 0)
-
-		nextData := in.AfterID
-		next := make([]string, 0)
-		for i := 0; i < 3; i++ {
-			prevData -= 1
-			previous = append(previous, fmt.Sprint(prevData))
-
-			nextData += 1
-			next = append(next, fmt.Sprint(nextData))
+func (p *RepoPostgres) SetEnabled(ctx context.Context, in InputSetEnabled) (out OutSetEnabled, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	reason, suspendedAt := in.suspension()
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSetAppEnabled,
+		in.Enabled, reason, suspendedAt, in.UpdatedAt, in.ClientID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutSetEnabled{
+			Success: false,
 		}
 
-		previous = append(previous, "-inf")
-		next = append(next, "+inf")
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
 
-		err = fmt.Errorf(
-			"cannot do range query: after_id %d we may have %s, and before_id %d we may have %s. "+
-				"From two slices, we may never find the subset",
-			in.AfterID, next,
-			in.BeforeID, previous,
-		)
-	default:
-		// in: before_id = 12 and after_id = 10
-		// sql: (id > 10 AND id < 12)
-		err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlListAppsWithRange, in.AfterID, in.BeforeID, in.Limit)
+	out = OutSetEnabled{
+		Success: true,
+		App:     appData,
 	}
+	return
+}
 
+func (p *RepoPostgres) SetSettings(ctx context.Context, in InputSetSettings) (out OutSetSettings, err error) {
+	err = validator.Validate(in)
 	if err != nil {
-		err = fmt.Errorf("cannot get list of apps: %w", err)
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
 		return
 	}
 
-	out = OutList{
-		Total: count.Total,
-		Apps:  appData,
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSetAppSettings, in.Settings, in.UpdatedAt, in.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutSetSettings{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
 	}
 
+	if err != nil {
+		return
+	}
+
+	out = OutSetSettings{
+		Success: true,
+		App:     appData,
+	}
 	return
 }
 
-func (p *RepoPostgres) DelByClientID(ctx context.Context, in InputDelByClientID) (out OutDelByClientID, err error) {
+func (p *RepoPostgres) IncrDailyUsage(ctx context.Context, in InputIncrDailyUsage) (out OutIncrDailyUsage, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var total int64
+	err = sqlx.GetContext(ctx, p.Config.Connection, &total, sqlIncrAppDailyUsage, in.AppID, in.Day, in.Count, in.Quota)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil // quota exceeded
+		return
+	}
+
+	if err != nil {
+		err = fmt.Errorf("cannot increment daily usage of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutIncrDailyUsage{
+		Allowed: true,
+		Total:   total,
+	}
+	return
+}
+
+func (p *RepoPostgres) GetDeletedByClientID(ctx context.Context, in InputGetDeletedByClientID) (out OutGetDeletedByClientID, err error) {
 	err = validator.Validate(in)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", ErrValidation, err)
@@ -226,9 +320,28 @@ func (p *RepoPostgres) DelByClientID(ctx context.Context, in InputDelByClientID)
 	}
 
 	appData := App{}
-	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSoftDeleteApp, in.DeletedAt, in.ClientID)
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlGetDeletedAppByClientID, in.ClientID, in.DeletedAfter)
+	if err != nil {
+		return
+	}
+
+	out = OutGetDeletedByClientID{
+		App: appData,
+	}
+	return
+}
+
+func (p *RepoPostgres) Restore(ctx context.Context, in InputRestore) (out OutRestore, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlRestoreApp, in.UpdatedAt, in.ID, in.DeletedAt)
 	if errors.Is(err, sql.ErrNoRows) {
-		out = OutDelByClientID{
+		out = OutRestore{
 			Success: false,
 		}
 
@@ -240,8 +353,57 @@ func (p *RepoPostgres) DelByClientID(ctx context.Context, in InputDelByClientID)
 		return
 	}
 
-	out = OutDelByClientID{
-		Success: appData.ClientID == in.ClientID && appData.DeletedAt == in.DeletedAt,
+	out = OutRestore{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoPostgres) ListDeleted(ctx context.Context, in InputListDeleted) (out OutListDeleted, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := make([]App, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlListDeletedApps, in.DeletedBefore, in.Limit)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of deleted apps: %w", err)
+		return
+	}
+
+	out = OutListDeleted{
+		Apps: appData,
+	}
+	return
+}
+
+func (p *RepoPostgres) Purge(ctx context.Context, in InputPurge) (out OutPurge, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	_, err = p.Config.Connection.ExecContext(ctx, sqlPurgeAppDailyUsages, in.ID)
+	if err != nil {
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlPurgeApp, in.ID)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	out = OutPurge{
+		Success: affected == 1,
 	}
 	return
 }
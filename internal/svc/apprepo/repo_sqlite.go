@@ -0,0 +1,403 @@
+package apprepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+// SQLite support RETURNING since version 3.35, client_id column is using NOCASE collation.
+const (
+	sqlSqliteCreateApp        = `INSERT INTO apps (id, client_id, name, settings, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING *;`
+	sqlSqliteGetAppByClientID = `SELECT * FROM apps WHERE client_id = ? AND deleted_at = 0 LIMIT 1;`
+
+	sqlSqliteUpsertApp = `
+		INSERT INTO apps (id, client_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (client_id, deleted_at)
+		DO UPDATE SET
+		    name = excluded.name,
+		    updated_at = excluded.updated_at
+		WHERE apps.deleted_at = 0
+		RETURNING *;
+`
+
+	sqlSqliteSoftDeleteApp = `UPDATE apps SET deleted_at = ? WHERE id = (SELECT id FROM apps WHERE client_id = ? AND deleted_at = 0 LIMIT 1) RETURNING *;`
+
+	sqlSqliteSetAppEnabled = `
+		UPDATE apps SET enabled = ?, suspended_reason = ?, suspended_at = ?, updated_at = ?
+		WHERE id = (SELECT id FROM apps WHERE client_id = ? AND deleted_at = 0 LIMIT 1)
+		RETURNING *;
+`
+
+	sqlSqliteSetAppSettings = `
+		UPDATE apps SET settings = ?, updated_at = ?
+		WHERE id = (SELECT id FROM apps WHERE client_id = ? AND deleted_at = 0 LIMIT 1)
+		RETURNING *;
+`
+
+	// no row is returned when the total exceeds the quota
+	sqlSqliteIncrAppDailyUsage = `
+		INSERT INTO app_daily_usages (app_id, day, total) VALUES (?, ?, ?)
+		ON CONFLICT (app_id, day)
+		DO UPDATE SET total = app_daily_usages.total + excluded.total
+		WHERE app_daily_usages.total + excluded.total <= ?
+		RETURNING total;
+`
+
+	sqlSqliteGetDeletedAppByClientID = `SELECT * FROM apps WHERE client_id = ? AND deleted_at >= ? ORDER BY deleted_at DESC LIMIT 1;`
+	sqlSqliteRestoreApp              = `UPDATE apps SET deleted_at = 0, updated_at = ? WHERE id = ? AND deleted_at = ? RETURNING *;`
+	sqlSqliteListDeletedApps         = `SELECT * FROM apps WHERE deleted_at > 0 AND deleted_at < ? ORDER BY deleted_at ASC LIMIT ?;`
+	sqlSqlitePurgeApp                = `DELETE FROM apps WHERE id = ? AND deleted_at > 0;`
+	// sqlSqlitePurgeAppDailyUsages only delete the usages of deleted app, it is deleted before the app so it is not left
+	sqlSqlitePurgeAppDailyUsages = `DELETE FROM app_daily_usages WHERE app_id = ? AND EXISTS (SELECT 1 FROM apps WHERE id = ? AND deleted_at > 0);`
+)
+
+type RepoSQLiteConfig struct {
+	Connection sqlx.ExtContext `validate:"required"`
+}
+
+type RepoSQLite struct {
+	Config RepoSQLiteConfig
+}
+
+var _ Repo = (*RepoSQLite)(nil)
+
+// SQLite return repo interface which implements using SQLite, suitable for single node and testing
+func SQLite(conf RepoSQLiteConfig) (service *RepoSQLite, err error) {
+	err = validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	service = &RepoSQLite{
+		Config: conf,
+	}
+	return
+}
+
+func (p *RepoSQLite) Create(ctx context.Context, in InputCreate) (out OutCreate, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	app := in.App
+	app.ClientID = strings.TrimSpace(strings.ToLower(app.ClientID))
+
+	insertedApp := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &insertedApp, sqlSqliteCreateApp,
+		in.App.ID, app.ClientID, app.Name, app.Settings, app.CreatedAt, app.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	out = OutCreate{
+		App: insertedApp,
+	}
+	return
+}
+
+func (p *RepoSQLite) Upsert(ctx context.Context, in InputUpsert) (out OutUpsert, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	app := in.App
+	app.ClientID = strings.TrimSpace(strings.ToLower(app.ClientID))
+
+	insertedApp := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &insertedApp, sqlSqliteUpsertApp,
+		in.App.ID, app.ClientID, app.Name, app.CreatedAt, app.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	out = OutUpsert{
+		App: insertedApp,
+	}
+	return
+}
+
+func (p *RepoSQLite) GetByClientID(ctx context.Context, in InputGetByClientID) (out OutGetByClientID, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "apprepo.GetByClientID")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSqliteGetAppByClientID, in.ClientID)
+	if err != nil {
+		return
+	}
+
+	out = OutGetByClientID{
+		App: appData,
+	}
+	return
+}
+
+// List is using keyset pagination, the cursor row is not included in the result.
+func (p *RepoSQLite) List(ctx context.Context, in InputList) (out OutList, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	query, args, countQuery, countArgs, err := listQuery(&in)
+	if err != nil {
+		return
+	}
+
+	appData := make([]App, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, query, args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of apps: %w", err)
+		return
+	}
+
+	out = listOut(appData, in)
+	if !in.WithTotal {
+		return
+	}
+
+	count := struct {
+		Total int64 `db:"total"`
+	}{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &count, countQuery, countArgs...)
+	if err != nil {
+		err = fmt.Errorf("cannot count list of apps: %w", err)
+		return
+	}
+
+	out.Total = count.Total
+	return
+}
+
+func (p *RepoSQLite) DelByClientID(ctx context.Context, in InputDelByClientID) (out OutDelByClientID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSqliteSoftDeleteApp, in.DeletedAt, in.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutDelByClientID{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	out = OutDelByClientID{
+		Success: appData.ClientID == in.ClientID && appData.DeletedAt == in.DeletedAt,
+	}
+	return
+}
+
+func (p *RepoSQLite) SetEnabled(ctx context.Context, in InputSetEnabled) (out OutSetEnabled, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	reason, suspendedAt := in.suspension()
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSqliteSetAppEnabled,
+		in.Enabled, reason, suspendedAt, in.UpdatedAt, in.ClientID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutSetEnabled{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	out = OutSetEnabled{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoSQLite) SetSettings(ctx context.Context, in InputSetSettings) (out OutSetSettings, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSqliteSetAppSettings, in.Settings, in.UpdatedAt, in.ClientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutSetSettings{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	out = OutSetSettings{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoSQLite) IncrDailyUsage(ctx context.Context, in InputIncrDailyUsage) (out OutIncrDailyUsage, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var total int64
+	err = sqlx.GetContext(ctx, p.Config.Connection, &total, sqlSqliteIncrAppDailyUsage, in.AppID, in.Day, in.Count, in.Quota)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil // quota exceeded
+		return
+	}
+
+	if err != nil {
+		err = fmt.Errorf("cannot increment daily usage of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutIncrDailyUsage{
+		Allowed: true,
+		Total:   total,
+	}
+	return
+}
+
+func (p *RepoSQLite) GetDeletedByClientID(ctx context.Context, in InputGetDeletedByClientID) (out OutGetDeletedByClientID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSqliteGetDeletedAppByClientID, in.ClientID, in.DeletedAfter)
+	if err != nil {
+		return
+	}
+
+	out = OutGetDeletedByClientID{
+		App: appData,
+	}
+	return
+}
+
+func (p *RepoSQLite) Restore(ctx context.Context, in InputRestore) (out OutRestore, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := App{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &appData, sqlSqliteRestoreApp, in.UpdatedAt, in.ID, in.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutRestore{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	out = OutRestore{
+		Success: true,
+		App:     appData,
+	}
+	return
+}
+
+func (p *RepoSQLite) ListDeleted(ctx context.Context, in InputListDeleted) (out OutListDeleted, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	appData := make([]App, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &appData, sqlSqliteListDeletedApps, in.DeletedBefore, in.Limit)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of deleted apps: %w", err)
+		return
+	}
+
+	out = OutListDeleted{
+		Apps: appData,
+	}
+	return
+}
+
+func (p *RepoSQLite) Purge(ctx context.Context, in InputPurge) (out OutPurge, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	_, err = p.Config.Connection.ExecContext(ctx, sqlSqlitePurgeAppDailyUsages, in.ID, in.ID)
+	if err != nil {
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlSqlitePurgeApp, in.ID)
+	if err != nil {
+		return
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	out = OutPurge{
+		Success: affected == 1,
+	}
+	return
+}
@@ -0,0 +1,211 @@
+package apprepo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newSQLite(t *testing.T) *apprepo.RepoSQLite {
+	ctx := context.Background()
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	// each connection of :memory: is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := migration.Load(assets.Migrations, "migrations/sqlite/apprepo")
+	assert.NoError(t, err)
+
+	migrator, err := migration.New(migration.Config{DB: db, Dialect: "sqlite", Service: "app", Migrations: migrations})
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+
+	repo, err := apprepo.SQLite(apprepo.RepoSQLiteConfig{Connection: db})
+	assert.NoError(t, err)
+	return repo
+}
+
+func TestSQLite(t *testing.T) {
+	testRepo(t, newSQLite(t))
+}
+
+func TestSQLite_List(t *testing.T) {
+	testList(t, newSQLite(t))
+}
+
+// testRepo is run against every database, the repo must be empty.
+func testRepo(t *testing.T, repo apprepo.Repo) {
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, apprepo.InputCreate{App: apprepo.App{ID: 1, ClientID: "App1", Name: "app 1", CreatedAt: 1, UpdatedAt: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, "app1", created.App.ClientID)
+
+	// conflict on client id update the existing app
+	upserted, err := repo.Upsert(ctx, apprepo.InputUpsert{App: apprepo.App{ID: 2, ClientID: "app1", Name: "app 1 renamed", CreatedAt: 2, UpdatedAt: 2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, upserted.App.ID)
+	assert.Equal(t, "app 1 renamed", upserted.App.Name)
+
+	assert.True(t, upserted.App.Enabled)
+
+	suspended, err := repo.SetEnabled(ctx, apprepo.InputSetEnabled{ClientID: "app1", Enabled: false, Reason: "abuse", UpdatedAt: 2})
+	assert.NoError(t, err)
+	assert.True(t, suspended.Success)
+	assert.False(t, suspended.App.Enabled)
+	assert.Equal(t, "abuse", suspended.App.SuspendedReason)
+	assert.EqualValues(t, 2, suspended.App.SuspendedAt)
+
+	resumed, err := repo.SetEnabled(ctx, apprepo.InputSetEnabled{ClientID: "app1", Enabled: true, UpdatedAt: 2})
+	assert.NoError(t, err)
+	assert.True(t, resumed.App.Enabled)
+	assert.Equal(t, "", resumed.App.SuspendedReason)
+	assert.EqualValues(t, 0, resumed.App.SuspendedAt)
+
+	// upsert keep the settings, it is only replaced by SetSettings
+	assert.Equal(t, apprepo.Settings{}, resumed.App.Settings)
+
+	settings := apprepo.Settings{AllowedProviders: []string{"fcm"}, DailySendQuota: 2, DefaultTTLs: map[string]int64{"fcm": 60}}
+	withSettings, err := repo.SetSettings(ctx, apprepo.InputSetSettings{ClientID: "app1", Settings: settings, UpdatedAt: 3})
+	assert.NoError(t, err)
+	assert.True(t, withSettings.Success)
+	assert.Equal(t, settings, withSettings.App.Settings)
+
+	// usage more than quota is not counted
+	usageIn := apprepo.InputIncrDailyUsage{AppID: 1, Day: 1, Count: 1, Quota: 2}
+	for i := 1; i <= 3; i++ {
+		usage, err := repo.IncrDailyUsage(ctx, usageIn)
+		assert.NoError(t, err)
+		assert.Equal(t, i <= 2, usage.Allowed)
+	}
+
+	usageIn.Day = 2
+	usage, err := repo.IncrDailyUsage(ctx, usageIn)
+	assert.NoError(t, err)
+	assert.True(t, usage.Allowed)
+	assert.EqualValues(t, 1, usage.Total)
+
+	list, err := repo.List(ctx, apprepo.InputList{Limit: 10, WithTotal: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, list.Total)
+
+	deleted, err := repo.DelByClientID(ctx, apprepo.InputDelByClientID{ClientID: "app1", DeletedAt: 3})
+	assert.NoError(t, err)
+	assert.True(t, deleted.Success)
+
+	_, err = repo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: "app1"})
+	assert.Error(t, err)
+
+	// deleted after 2 can be restored
+	deletedApp, err := repo.GetDeletedByClientID(ctx, apprepo.InputGetDeletedByClientID{ClientID: "app1", DeletedAfter: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, deletedApp.App.DeletedAt)
+
+	_, err = repo.GetDeletedByClientID(ctx, apprepo.InputGetDeletedByClientID{ClientID: "app1", DeletedAfter: 4})
+	assert.Error(t, err)
+
+	restored, err := repo.Restore(ctx, apprepo.InputRestore{ID: 1, DeletedAt: 3, UpdatedAt: 4})
+	assert.NoError(t, err)
+	assert.True(t, restored.Success)
+	assert.EqualValues(t, 0, restored.App.DeletedAt)
+
+	restored, err = repo.Restore(ctx, apprepo.InputRestore{ID: 1, DeletedAt: 3, UpdatedAt: 4})
+	assert.NoError(t, err)
+	assert.False(t, restored.Success)
+
+	// only deleted app can be purged
+	purged, err := repo.Purge(ctx, apprepo.InputPurge{ID: 1})
+	assert.NoError(t, err)
+	assert.False(t, purged.Success)
+
+	_, err = repo.DelByClientID(ctx, apprepo.InputDelByClientID{ClientID: "app1", DeletedAt: 5})
+	assert.NoError(t, err)
+
+	deletedApps, err := repo.ListDeleted(ctx, apprepo.InputListDeleted{DeletedBefore: 6, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, deletedApps.Apps, 1)
+
+	purged, err = repo.Purge(ctx, apprepo.InputPurge{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, purged.Success)
+}
+
+// testList is run against every database, the repo must be empty.
+func testList(t *testing.T, repo apprepo.Repo) {
+	ctx := context.Background()
+
+	// name is not unique, so the id is used as tie-breaker
+	names := []string{"beta", "alpha", "beta", "gamma", "100%_app"}
+	for i, name := range names {
+		id := int64(i + 1)
+		_, err := repo.Create(ctx, apprepo.InputCreate{App: apprepo.App{
+			ID: id, ClientID: fmt.Sprintf("app%d", id), Name: name, CreatedAt: id, UpdatedAt: id,
+		}})
+		assert.NoError(t, err)
+	}
+
+	ids := func(apps []apprepo.App) []int64 {
+		out := make([]int64, 0)
+		for _, app := range apps {
+			out = append(out, app.ID)
+		}
+		return out
+	}
+
+	sort := pagination.Sort{Field: "name"}
+	first, err := repo.List(ctx, apprepo.InputList{Limit: 2, Sort: sort})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 2}, ids(first.Apps))
+	assert.Nil(t, first.Prev)
+	assert.EqualValues(t, 0, first.Total)
+
+	second, err := repo.List(ctx, apprepo.InputList{Limit: 2, Sort: sort, Cursor: first.Next})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, ids(second.Apps))
+
+	last, err := repo.List(ctx, apprepo.InputList{Limit: 2, Sort: sort, Cursor: second.Next})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4}, ids(last.Apps))
+	assert.Nil(t, last.Next)
+
+	back, err := repo.List(ctx, apprepo.InputList{Limit: 2, Sort: sort, Cursor: last.Prev})
+	assert.NoError(t, err)
+	assert.Equal(t, ids(second.Apps), ids(back.Apps))
+
+	back, err = repo.List(ctx, apprepo.InputList{Limit: 2, Sort: sort, Cursor: back.Prev})
+	assert.NoError(t, err)
+	assert.Equal(t, ids(first.Apps), ids(back.Apps))
+	assert.Nil(t, back.Prev)
+
+	// cursor of other sort is refused
+	_, err = repo.List(ctx, apprepo.InputList{Limit: 2, Sort: pagination.Sort{Field: "id"}, Cursor: first.Next})
+	assert.ErrorIs(t, err, apprepo.ErrValidation)
+
+	desc, err := repo.List(ctx, apprepo.InputList{Limit: 10, Sort: pagination.Sort{Field: "created_at", Desc: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, ids(desc.Apps))
+
+	// the wildcard in prefix is searched literally
+	filtered, err := repo.List(ctx, apprepo.InputList{Limit: 10, NamePrefix: "100%_", WithTotal: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5}, ids(filtered.Apps))
+	assert.EqualValues(t, 1, filtered.Total)
+
+	filtered, err = repo.List(ctx, apprepo.InputList{Limit: 10, ClientIDPrefix: "APP", NamePrefix: "BE", WithTotal: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, ids(filtered.Apps))
+	assert.EqualValues(t, 2, filtered.Total)
+}
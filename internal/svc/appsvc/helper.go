@@ -7,12 +7,57 @@ import (
 
 func AppFromRepo(app apprepo.App) App {
 	a := App{
-		ID:        app.ID,
-		ClientID:  app.ClientID,
-		Name:      app.Name,
-		CreatedAt: time.UnixMicro(app.CreatedAt).UTC(),
-		UpdatedAt: time.UnixMicro(app.UpdatedAt).UTC(),
-		DeletedAt: time.UnixMicro(app.DeletedAt).UTC(),
+		ID:              app.ID,
+		ClientID:        app.ClientID,
+		Name:            app.Name,
+		CreatedAt:       time.UnixMicro(app.CreatedAt).UTC(),
+		UpdatedAt:       time.UnixMicro(app.UpdatedAt).UTC(),
+		DeletedAt:       time.UnixMicro(app.DeletedAt).UTC(),
+		Enabled:         app.Enabled,
+		SuspendedReason: app.SuspendedReason,
+		Settings:        SettingsFromRepo(app.Settings),
 	}
+
+	if app.SuspendedAt > 0 {
+		a.SuspendedAt = time.UnixMicro(app.SuspendedAt).UTC()
+	}
+
 	return a
 }
+
+func SettingsFromRepo(settings apprepo.Settings) AppSettings {
+	s := AppSettings{
+		DefaultLabel:     settings.DefaultLabel,
+		AllowedProviders: settings.AllowedProviders,
+		DailySendQuota:   settings.DailySendQuota,
+		ContactEmail:     settings.ContactEmail,
+	}
+
+	if len(settings.DefaultTTLs) > 0 {
+		s.DefaultTTLs = make(map[string]time.Duration, len(settings.DefaultTTLs))
+		for provider, ttl := range settings.DefaultTTLs {
+			s.DefaultTTLs[provider] = time.Duration(ttl) * time.Second
+		}
+	}
+
+	return s
+}
+
+// SettingsToRepo save the DefaultTTLs in seconds.
+func SettingsToRepo(settings AppSettings) apprepo.Settings {
+	s := apprepo.Settings{
+		DefaultLabel:     settings.DefaultLabel,
+		AllowedProviders: settings.AllowedProviders,
+		DailySendQuota:   settings.DailySendQuota,
+		ContactEmail:     settings.ContactEmail,
+	}
+
+	if len(settings.DefaultTTLs) > 0 {
+		s.DefaultTTLs = make(map[string]int64, len(settings.DefaultTTLs))
+		for provider, ttl := range settings.DefaultTTLs {
+			s.DefaultTTLs[provider] = int64(ttl / time.Second)
+		}
+	}
+
+	return s
+}
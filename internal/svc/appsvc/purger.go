@@ -0,0 +1,92 @@
+package appsvc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ylog"
+)
+
+// Locker is implemented by dblock.Lock.
+type Locker interface {
+	TryDo(ctx context.Context, fn func(ctx context.Context) error) (ok bool, err error)
+}
+
+type PurgerConfig struct {
+	Service  Service       `validate:"required"`
+	Interval time.Duration `validate:"required"`
+	Lock     Locker        `validate:"required"` // so only one node purge the apps at a time
+}
+
+// Purger call Service.PurgeApps on every interval in the background until Close is called.
+type Purger struct {
+	Config PurgerConfig
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewPurger(cfg PurgerConfig) (*Purger, error) {
+	if err := validator.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	p := &Purger{
+		Config: cfg,
+		stop:   make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.loop()
+
+	return p, nil
+}
+
+func (p *Purger) loop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.purge()
+		}
+	}
+}
+
+func (p *Purger) purge() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.Config.Interval)
+	defer cancel()
+
+	var out OutPurgeApps
+	locked, err := p.Config.Lock.TryDo(ctx, func(ctx context.Context) (err error) {
+		out, err = p.Config.Service.PurgeApps(ctx, InputPurgeApps{})
+		return
+	})
+	if err != nil {
+		ylog.Error(ctx, "purge deleted apps error", ylog.KV("error", err))
+		return
+	}
+
+	// other node is purging the apps
+	if !locked {
+		return
+	}
+
+	if out.Purged > 0 || out.Failed > 0 {
+		ylog.Info(ctx, fmt.Sprintf("purge deleted apps: %d purged, %d failed", out.Purged, out.Failed))
+	}
+}
+
+// Close stop the background purge and wait the running purge to finish.
+func (p *Purger) Close() error {
+	close(p.stop)
+	p.wg.Wait()
+	return nil
+}
@@ -2,9 +2,21 @@ package appsvc
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+var (
+	// ErrAppSuspended is returned when getting enabled app, but the app is suspended.
+	ErrAppSuspended = errors.New("app is suspended")
+
+	// ErrQuotaExceeded is returned when the app already used all of its AppSettings.DailySendQuota today.
+	ErrQuotaExceeded = errors.New("app daily send quota exceeded")
+
+	// ErrProviderNotAllowed is returned when the provider is not in the AppSettings.AllowedProviders.
+	ErrProviderNotAllowed = errors.New("provider is not allowed for the app")
+)
+
 // Service is an interface of final business logic.
 // Any input and output from/to this function should be SAFE for external party to consume,
 // i.e: request or response from HTTP handler
@@ -14,6 +26,12 @@ type Service interface {
 	GetApp(ctx context.Context, input InputGetApp) (out OutGetApp, err error)
 	ListApp(ctx context.Context, input InputListApp) (out OutListApp, err error)
 	DelApp(ctx context.Context, input InputDelApp) (out OutDelApp, err error)
+	SuspendApp(ctx context.Context, input InputSuspendApp) (out OutSuspendApp, err error)
+	ResumeApp(ctx context.Context, input InputResumeApp) (out OutResumeApp, err error)
+	PutAppSettings(ctx context.Context, input InputPutAppSettings) (out OutPutAppSettings, err error)
+	UseDailyQuota(ctx context.Context, input InputUseDailyQuota) (out OutUseDailyQuota, err error)
+	RestoreApp(ctx context.Context, input InputRestoreApp) (out OutRestoreApp, err error)
+	PurgeApps(ctx context.Context, input InputPurgeApps) (out OutPurgeApps, err error)
 }
 
 // App is like appstore.AppRepo but this only use for returning output via external service.
@@ -26,12 +44,47 @@ type App struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt time.Time
+
+	Enabled         bool
+	SuspendedReason string
+	SuspendedAt     time.Time // zero when the app is enabled
+
+	Settings AppSettings
+}
+
+// AppSettings zero value of each field means not set.
+type AppSettings struct {
+	DefaultLabel     string   `validate:"max=255"`       // used when sending message without label
+	AllowedProviders []string `validate:"dive,required"` // empty means all providers are allowed
+	DailySendQuota   int64    `validate:"min=0"`         // maximum sent recipients per day (UTC), zero means unlimited
+
+	// DefaultTTLs is default message time to live per provider, i.e: fcm: 1h.
+	// It is used when the payload has no its own time to live, see backend.Message TTL.
+	DefaultTTLs map[string]time.Duration `validate:"dive,keys,required,endkeys,min=0"`
+
+	ContactEmail string `validate:"omitempty,email"`
+}
+
+// ProviderAllowed return true when the app can send message using the provider.
+func (s AppSettings) ProviderAllowed(provider string) bool {
+	if len(s.AllowedProviders) <= 0 {
+		return true
+	}
+
+	for _, p := range s.AllowedProviders {
+		if p == provider {
+			return true
+		}
+	}
+
+	return false
 }
 
 // InputCreateApp ...
 type InputCreateApp struct {
 	ClientID string `validate:"required,alphanum,lowercase"`
 	Name     string `validate:"required"`
+	Settings AppSettings
 }
 
 type OutCreateApp struct {
@@ -47,6 +100,8 @@ type OutPutApp struct {
 	App App
 }
 
+// InputGetApp when Enabled is true, suspended app return ErrAppSuspended.
+// When Enabled is false, enabled app is returned as not found.
 type InputGetApp struct {
 	ClientID string `validate:"required,lowercase"`
 	Enabled  *bool  `validate:"-"`
@@ -56,16 +111,26 @@ type OutGetApp struct {
 	App App
 }
 
+// InputListApp Cursor is the NextCursor or PrevCursor of the previous OutListApp, the Sort must be the same.
+// Sort is one of id, name and created_at, prefixed with "-" when descending. Empty Sort means "id".
+// NamePrefix and ClientIDPrefix (both case-insensitive) filter the app which starting with it.
 type InputListApp struct {
-	Limit    int64 `validate:"min=0"`
-	BeforeID int64 `validate:"min=0"`
-	AfterID  int64 `validate:"min=0"`
+	Limit          int64  `validate:"min=0"`
+	Cursor         string `validate:"-"`
+	Sort           string `validate:"-"`
+	NamePrefix     string `validate:"max=255"`
+	ClientIDPrefix string `validate:"max=255"`
+	WithTotal      bool   `validate:"-"`
 }
 
+// OutListApp Total is only counted when InputListApp WithTotal is true.
+// NextCursor and PrevCursor is empty when there is no more page.
 type OutListApp struct {
-	Total int64
-	Limit int64
-	Apps  []App
+	Total      int64
+	Limit      int64
+	Apps       []App
+	NextCursor string
+	PrevCursor string
 }
 
 type InputDelApp struct {
@@ -75,3 +140,59 @@ type InputDelApp struct {
 type OutDelApp struct {
 	Success bool
 }
+
+type InputSuspendApp struct {
+	ClientID string `validate:"required,lowercase"`
+	Reason   string `validate:"required,max=255"`
+}
+
+type OutSuspendApp struct {
+	App App
+}
+
+type InputResumeApp struct {
+	ClientID string `validate:"required,lowercase"`
+}
+
+type OutResumeApp struct {
+	App App
+}
+
+// InputPutAppSettings replace all settings of the app.
+type InputPutAppSettings struct {
+	ClientID string `validate:"required,lowercase"`
+	Settings AppSettings
+}
+
+type OutPutAppSettings struct {
+	App App
+}
+
+// InputUseDailyQuota use Count of the app daily Quota, zero Quota means unlimited.
+type InputUseDailyQuota struct {
+	AppID int64 `validate:"required"`
+	Quota int64 `validate:"min=0"`
+	Count int64 `validate:"required,min=1"`
+}
+
+// OutUseDailyQuota Used is today usage including the Count, zero when the quota is unlimited.
+type OutUseDailyQuota struct {
+	Used int64
+}
+
+type InputRestoreApp struct {
+	ClientID string `validate:"required,lowercase"`
+}
+
+type OutRestoreApp struct {
+	App App
+}
+
+type InputPurgeApps struct {
+	Limit int64 `validate:"min=0"`
+}
+
+type OutPurgeApps struct {
+	Purged int64
+	Failed int64
+}
@@ -2,7 +2,16 @@ package appsvc
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicerepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templaterepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/uow"
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"github.com/yusufsyaifudin/ngendika/pkg/uid"
 	"go.opentelemetry.io/otel/trace"
@@ -14,9 +23,26 @@ import (
 	"github.com/yusufsyaifudin/ylog"
 )
 
+// DefaultRestoreRetention is how long the deleted app can be restored when not configured.
+const DefaultRestoreRetention = 7 * 24 * time.Hour
+
 type DefaultServiceConfig struct {
 	UIDGen  uid.UID      `validate:"required"`
 	AppRepo apprepo.Repo `validate:"required"`
+	PnpRepo pnprepo.Repo `validate:"required"`
+
+	// Repositories of the other data owned by the app, it is permanently deleted when the app is purged.
+	TemplateRepo templaterepo.Repo `validate:"required"`
+	DeviceRepo   devicerepo.Repo   `validate:"required"`
+	TopicRepo    topicrepo.Repo    `validate:"required"`
+	CallbackRepo callbackrepo.Repo `validate:"required"`
+
+	// UnitOfWork is set when app and push notification provider is using the same database,
+	// so the app is deleted, restored and purged together with its providers atomically.
+	UnitOfWork uow.UnitOfWork `validate:"-"`
+
+	RestoreRetention time.Duration `validate:"min=0"`
+	PurgeArchive     bool          `validate:"-"` // only detach the Postgres partition of providers when purging app, the other data is deleted
 }
 
 type DefaultService struct {
@@ -30,6 +56,10 @@ func New(dep DefaultServiceConfig) (*DefaultService, error) {
 		return nil, err
 	}
 
+	if dep.RestoreRetention <= 0 {
+		dep.RestoreRetention = DefaultRestoreRetention
+	}
+
 	return &DefaultService{
 		Config: dep,
 	}, nil
@@ -69,6 +99,7 @@ func (d *DefaultService) CreateApp(ctx context.Context, input InputCreateApp) (o
 		ID:        int64(nextID),
 		ClientID:  strings.ToLower(input.ClientID),
 		Name:      input.Name,
+		Settings:  SettingsToRepo(input.Settings),
 		CreatedAt: now.UnixMicro(),
 		UpdatedAt: now.UnixMicro(),
 	}
@@ -133,13 +164,22 @@ func (d *DefaultService) GetApp(ctx context.Context, input InputGetApp) (out Out
 		return
 	}
 
-	outGetApp, err := d.Config.AppRepo.GetByClientID(ctx, apprepo.InputGetByClientID(input))
+	outGetApp, err := d.Config.AppRepo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: input.ClientID})
 	if err != nil {
 		err = fmt.Errorf("not found app client id '%s': %w", input.ClientID, err)
 		return
 	}
 
 	appData := outGetApp.App
+	if input.Enabled != nil && *input.Enabled && !appData.Enabled {
+		err = fmt.Errorf("%w: app client id '%s' is suspended: %s", ErrAppSuspended, input.ClientID, appData.SuspendedReason)
+		return
+	}
+
+	if input.Enabled != nil && !*input.Enabled && appData.Enabled {
+		err = fmt.Errorf("not found suspended app client id '%s': %w", input.ClientID, sql.ErrNoRows)
+		return
+	}
 	out = OutGetApp{
 		App: AppFromRepo(appData),
 	}
@@ -158,10 +198,30 @@ func (d *DefaultService) ListApp(ctx context.Context, in InputListApp) (out OutL
 		in.Limit = 100
 	}
 
+	sort, err := pagination.ParseSort(in.Sort, apprepo.ListSortFields...)
+	if err != nil {
+		err = fmt.Errorf("validation error: %w", err)
+		return
+	}
+
+	var cursor *pagination.Cursor
+	if in.Cursor != "" {
+		c, _err := pagination.Decode(in.Cursor)
+		if _err != nil {
+			err = fmt.Errorf("validation error: %w", _err)
+			return
+		}
+
+		cursor = &c
+	}
+
 	outList, err := d.Config.AppRepo.List(ctx, apprepo.InputList{
-		Limit:    in.Limit,
-		BeforeID: in.BeforeID,
-		AfterID:  in.AfterID,
+		Limit:          in.Limit,
+		Sort:           sort,
+		Cursor:         cursor,
+		NamePrefix:     strings.TrimSpace(in.NamePrefix),
+		ClientIDPrefix: strings.ToLower(strings.TrimSpace(in.ClientIDPrefix)),
+		WithTotal:      in.WithTotal,
 	})
 	if err != nil {
 		err = fmt.Errorf("list apps error: %w", err)
@@ -179,9 +239,19 @@ func (d *DefaultService) ListApp(ctx context.Context, in InputListApp) (out OutL
 		Apps:  apps,
 	}
 
+	if outList.Next != nil {
+		out.NextCursor = outList.Next.Encode()
+	}
+
+	if outList.Prev != nil {
+		out.PrevCursor = outList.Prev.Encode()
+	}
+
 	return
 }
 
+// DelApp soft delete the app and all of its push notification providers,
+// so message in flight cannot resolve the provider credential anymore.
 func (d *DefaultService) DelApp(ctx context.Context, input InputDelApp) (out OutDelApp, err error) {
 	err = validator.Validate(input)
 	if err != nil {
@@ -189,19 +259,368 @@ func (d *DefaultService) DelApp(ctx context.Context, input InputDelApp) (out Out
 		return
 	}
 
-	inDelApp := apprepo.InputDelByClientID{
+	// providers is deleted at the same time as the app, so it is restored together
+	deletedAt := time.Now().UTC().UnixMicro()
+
+	var success bool
+	err = d.inTx(ctx, func(ctx context.Context, appRepo apprepo.Repo, pnpRepo pnprepo.Repo) error {
+		outGetApp, _err := appRepo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: input.ClientID})
+		if errors.Is(_err, sql.ErrNoRows) {
+			return nil
+		}
+
+		if _err != nil {
+			return fmt.Errorf("db get app error '%s': %w", input.ClientID, _err)
+		}
+
+		inDelApp := apprepo.InputDelByClientID{
+			ClientID:  input.ClientID,
+			DeletedAt: deletedAt,
+		}
+
+		outDelApp, _err := appRepo.DelByClientID(ctx, inDelApp)
+		if _err != nil {
+			return fmt.Errorf("db delete error '%s': %w", input.ClientID, _err)
+		}
+
+		if !outDelApp.Success {
+			return nil
+		}
+
+		_, _err = pnpRepo.DelByAppID(ctx, pnprepo.InDelByAppID{
+			AppID:     outGetApp.App.ID,
+			DeletedAt: deletedAt,
+		})
+		if _err != nil {
+			return fmt.Errorf("db delete providers error '%s': %w", input.ClientID, _err)
+		}
+
+		success = true
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	out = OutDelApp{
+		Success: success,
+	}
+	return
+}
+
+// SuspendApp disable the app, so no message can be sent until it is resumed.
+func (d *DefaultService) SuspendApp(ctx context.Context, input InputSuspendApp) (out OutSuspendApp, err error) {
+	err = validator.Validate(input)
+	if err != nil {
+		err = fmt.Errorf("validation error, missing required field: %w", err)
+		return
+	}
+
+	outSetEnabled, err := d.Config.AppRepo.SetEnabled(ctx, apprepo.InputSetEnabled{
 		ClientID:  input.ClientID,
-		DeletedAt: time.Now().UTC().UnixMicro(),
+		Enabled:   false,
+		Reason:    input.Reason,
+		UpdatedAt: time.Now().UTC().UnixMicro(),
+	})
+	if err != nil {
+		err = fmt.Errorf("db suspend error '%s': %w", input.ClientID, err)
+		return
 	}
 
-	outDelApp, err := d.Config.AppRepo.DelByClientID(ctx, inDelApp)
+	if !outSetEnabled.Success {
+		err = fmt.Errorf("not found app client id '%s'", input.ClientID)
+		return
+	}
+
+	out = OutSuspendApp{
+		App: AppFromRepo(outSetEnabled.App),
+	}
+	return
+}
+
+// ResumeApp enable the suspended app and clear the suspension reason.
+func (d *DefaultService) ResumeApp(ctx context.Context, input InputResumeApp) (out OutResumeApp, err error) {
+	err = validator.Validate(input)
 	if err != nil {
-		err = fmt.Errorf("db delete error '%s': %w", input.ClientID, err)
+		err = fmt.Errorf("validation error, missing required field: %w", err)
 		return
 	}
 
-	out = OutDelApp{
-		Success: outDelApp.Success,
+	outSetEnabled, err := d.Config.AppRepo.SetEnabled(ctx, apprepo.InputSetEnabled{
+		ClientID:  input.ClientID,
+		Enabled:   true,
+		UpdatedAt: time.Now().UTC().UnixMicro(),
+	})
+	if err != nil {
+		err = fmt.Errorf("db resume error '%s': %w", input.ClientID, err)
+		return
+	}
+
+	if !outSetEnabled.Success {
+		err = fmt.Errorf("not found app client id '%s'", input.ClientID)
+		return
+	}
+
+	out = OutResumeApp{
+		App: AppFromRepo(outSetEnabled.App),
+	}
+	return
+}
+
+// PutAppSettings replace all settings of the app, unset field is reset to its default.
+func (d *DefaultService) PutAppSettings(ctx context.Context, input InputPutAppSettings) (out OutPutAppSettings, err error) {
+	err = validator.Validate(input)
+	if err != nil {
+		err = fmt.Errorf("validation error, missing required field: %w", err)
+		return
+	}
+
+	outSetSettings, err := d.Config.AppRepo.SetSettings(ctx, apprepo.InputSetSettings{
+		ClientID:  input.ClientID,
+		Settings:  SettingsToRepo(input.Settings),
+		UpdatedAt: time.Now().UTC().UnixMicro(),
+	})
+	if err != nil {
+		err = fmt.Errorf("db put settings error '%s': %w", input.ClientID, err)
+		return
+	}
+
+	if !outSetSettings.Success {
+		err = fmt.Errorf("not found app client id '%s'", input.ClientID)
+		return
+	}
+
+	out = OutPutAppSettings{
+		App: AppFromRepo(outSetSettings.App),
 	}
 	return
 }
+
+// UseDailyQuota count the usage of the app today (UTC) and return ErrQuotaExceeded when it is more than the quota.
+// Request that exceeds the quota is not counted.
+func (d *DefaultService) UseDailyQuota(ctx context.Context, input InputUseDailyQuota) (out OutUseDailyQuota, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "appsvc.UseDailyQuota")
+	defer span.End()
+
+	err = validator.Validate(input)
+	if err != nil {
+		err = fmt.Errorf("validation error, missing required field: %w", err)
+		return
+	}
+
+	if input.Quota <= 0 {
+		return
+	}
+
+	if input.Count > input.Quota {
+		err = fmt.Errorf("%w: count %d is more than quota %d", ErrQuotaExceeded, input.Count, input.Quota)
+		return
+	}
+
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	outIncr, err := d.Config.AppRepo.IncrDailyUsage(ctx, apprepo.InputIncrDailyUsage{
+		AppID: input.AppID,
+		Day:   day.UnixMicro(),
+		Count: input.Count,
+		Quota: input.Quota,
+	})
+	if err != nil {
+		err = fmt.Errorf("db use daily quota error: %w", err)
+		return
+	}
+
+	if !outIncr.Allowed {
+		err = fmt.Errorf("%w: quota %d is used on %s", ErrQuotaExceeded, input.Quota, day.Format("2006-01-02"))
+		return
+	}
+
+	out = OutUseDailyQuota{
+		Used: outIncr.Total,
+	}
+	return
+}
+
+// RestoreApp undo the latest DelApp of the client id within the restore retention,
+// including the push notification providers deleted together with the app.
+func (d *DefaultService) RestoreApp(ctx context.Context, input InputRestoreApp) (out OutRestoreApp, err error) {
+	err = validator.Validate(input)
+	if err != nil {
+		err = fmt.Errorf("validation error, missing required field: %w", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	deletedAfter := now.Add(-d.Config.RestoreRetention).UnixMicro()
+
+	var restoredApp apprepo.App
+	err = d.inTx(ctx, func(ctx context.Context, appRepo apprepo.Repo, pnpRepo pnprepo.Repo) error {
+		// new app may already use the same client id after it is deleted
+		existingApp, _err := appRepo.GetByClientID(ctx, apprepo.InputGetByClientID{ClientID: input.ClientID})
+		if _err == nil {
+			return fmt.Errorf("app with client id '%s' already exist", existingApp.App.ClientID)
+		}
+
+		if !errors.Is(_err, sql.ErrNoRows) {
+			return fmt.Errorf("db get app error '%s': %w", input.ClientID, _err)
+		}
+
+		outDeleted, _err := appRepo.GetDeletedByClientID(ctx, apprepo.InputGetDeletedByClientID{
+			ClientID:     input.ClientID,
+			DeletedAfter: deletedAfter,
+		})
+		if errors.Is(_err, sql.ErrNoRows) {
+			return fmt.Errorf("no app with client id '%s' deleted within the last %s", input.ClientID, d.Config.RestoreRetention)
+		}
+
+		if _err != nil {
+			return fmt.Errorf("db get deleted app error '%s': %w", input.ClientID, _err)
+		}
+
+		deletedApp := outDeleted.App
+		outRestore, _err := appRepo.Restore(ctx, apprepo.InputRestore{
+			ID:        deletedApp.ID,
+			DeletedAt: deletedApp.DeletedAt,
+			UpdatedAt: now.UnixMicro(),
+		})
+		if _err != nil {
+			return fmt.Errorf("db restore error '%s': %w", input.ClientID, _err)
+		}
+
+		if !outRestore.Success {
+			return fmt.Errorf("app with client id '%s' is already restored or purged", input.ClientID)
+		}
+
+		_, _err = pnpRepo.RestoreByAppID(ctx, pnprepo.InRestoreByAppID{
+			AppID:     deletedApp.ID,
+			DeletedAt: deletedApp.DeletedAt,
+			UpdatedAt: now.UnixMicro(),
+		})
+		if _err != nil {
+			return fmt.Errorf("db restore providers error '%s': %w", input.ClientID, _err)
+		}
+
+		restoredApp = outRestore.App
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	out = OutRestoreApp{
+		App: AppFromRepo(restoredApp),
+	}
+	return
+}
+
+// PurgeApps permanently delete the apps that deleted before the restore retention, its providers and the other data it owns.
+// Failed app is only logged, so it does not block the other apps and is retried on the next call.
+func (d *DefaultService) PurgeApps(ctx context.Context, input InputPurgeApps) (out OutPurgeApps, err error) {
+	err = validator.Validate(input)
+	if err != nil {
+		err = fmt.Errorf("validation error, missing required field: %w", err)
+		return
+	}
+
+	// set to the default value
+	if input.Limit <= 0 || input.Limit > 100 {
+		input.Limit = 100
+	}
+
+	outList, err := d.Config.AppRepo.ListDeleted(ctx, apprepo.InputListDeleted{
+		DeletedBefore: time.Now().UTC().Add(-d.Config.RestoreRetention).UnixMicro(),
+		Limit:         input.Limit,
+	})
+	if err != nil {
+		err = fmt.Errorf("list deleted apps error: %w", err)
+		return
+	}
+
+	for _, app := range outList.Apps {
+		app := app
+
+		// the other data may use other database, it is deleted first so the app is retried when it failed
+		_err := d.purgeAppData(ctx, app.ID)
+		if _err != nil {
+			ylog.Error(ctx, fmt.Sprintf("cannot purge data of app id %d", app.ID), ylog.KV("error", _err))
+			out.Failed++
+			continue
+		}
+
+		_err = d.inTx(ctx, func(ctx context.Context, appRepo apprepo.Repo, pnpRepo pnprepo.Repo) error {
+			// provider may be left not deleted when app and provider is not using the same database
+			_, _err := pnpRepo.DelByAppID(ctx, pnprepo.InDelByAppID{AppID: app.ID, DeletedAt: app.DeletedAt})
+			if _err != nil {
+				return fmt.Errorf("db delete providers error: %w", _err)
+			}
+
+			_, _err = pnpRepo.PurgeByAppID(ctx, pnprepo.InPurgeByAppID{AppID: app.ID, Archive: d.Config.PurgeArchive})
+			if _err != nil {
+				return fmt.Errorf("db purge providers error: %w", _err)
+			}
+
+			_, _err = appRepo.Purge(ctx, apprepo.InputPurge{ID: app.ID})
+			if _err != nil {
+				return fmt.Errorf("db purge app error: %w", _err)
+			}
+
+			return nil
+		})
+
+		if _err != nil {
+			ylog.Error(ctx, fmt.Sprintf("cannot purge app id %d", app.ID), ylog.KV("error", _err))
+			out.Failed++
+			continue
+		}
+
+		out.Purged++
+	}
+
+	return
+}
+
+// purgeAppData delete the data of the app which is not deleted by its repository.
+func (d *DefaultService) purgeAppData(ctx context.Context, appID int64) error {
+	if _, err := d.Config.TemplateRepo.PurgeByAppID(ctx, templaterepo.InputPurgeByAppID{AppID: appID}); err != nil {
+		return fmt.Errorf("db purge templates error: %w", err)
+	}
+
+	if _, err := d.Config.DeviceRepo.PurgeByAppID(ctx, devicerepo.InputPurgeByAppID{AppID: appID}); err != nil {
+		return fmt.Errorf("db purge devices error: %w", err)
+	}
+
+	if _, err := d.Config.TopicRepo.PurgeByAppID(ctx, topicrepo.InputPurgeByAppID{AppID: appID}); err != nil {
+		return fmt.Errorf("db purge topic subscriptions error: %w", err)
+	}
+
+	if _, err := d.Config.CallbackRepo.PurgeByAppID(ctx, callbackrepo.InputPurgeByAppID{AppID: appID}); err != nil {
+		return fmt.Errorf("db purge callbacks error: %w", err)
+	}
+
+	return nil
+}
+
+// inTx run fn using the repositories of the unit of work when configured,
+// otherwise fn is using the configured repositories without transaction.
+// The repositories of the unit of work read from the primary, so the check before write is not affected by the replica lag.
+func (d *DefaultService) inTx(ctx context.Context, fn func(ctx context.Context, appRepo apprepo.Repo, pnpRepo pnprepo.Repo) error) error {
+	if d.Config.UnitOfWork == nil {
+		return fn(ctx, d.Config.AppRepo, d.Config.PnpRepo)
+	}
+
+	return d.Config.UnitOfWork.Do(ctx, func(ctx context.Context, repos uow.Repositories) error {
+		appRepo, err := repos.AppRepo()
+		if err != nil {
+			return fmt.Errorf("cannot get app repo in transaction: %w", err)
+		}
+
+		pnpRepo, err := repos.PNProviderRepo()
+		if err != nil {
+			return fmt.Errorf("cannot get pnp repo in transaction: %w", err)
+		}
+
+		return fn(ctx, appRepo, pnpRepo)
+	})
+}
@@ -0,0 +1,127 @@
+package callbackrepo
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Repo is callback (outbound webhook) and its delivery log repository service
+type Repo interface {
+	InsertCallback(ctx context.Context, in InputInsertCallback) (out OutInsertCallback, err error)
+	ListCallbacks(ctx context.Context, in InputListCallbacks) (out OutListCallbacks, err error)
+	DelCallback(ctx context.Context, in InputDelCallback) (out OutDelCallback, err error)
+
+	InsertDelivery(ctx context.Context, in InputInsertDelivery) (out OutInsertDelivery, err error)
+	UpdateDelivery(ctx context.Context, in InputUpdateDelivery) (out OutUpdateDelivery, err error)
+	ListDeliveries(ctx context.Context, in InputListDeliveries) (out OutListDeliveries, err error)
+	PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error)
+}
+
+// Callback is resembles the table structure.
+type Callback struct {
+	ID     int64  `db:"id" validate:"required"`
+	AppID  int64  `db:"app_id" validate:"required"`
+	URL    string `db:"url" validate:"required,url"`
+	Secret string `db:"secret" validate:"required"`
+	Events string `db:"events" validate:"required"` // comma separated event types
+
+	// Timestamp using integer as unix microsecond in UTC
+	CreatedAt int64 `db:"created_at" validate:"required"`
+	UpdatedAt int64 `db:"updated_at" validate:"required"`
+}
+
+// Delivery is resembles the table structure, one row per event per callback.
+type Delivery struct {
+	ID           int64  `db:"id" validate:"required"`
+	CallbackID   int64  `db:"callback_id" validate:"required"`
+	AppID        int64  `db:"app_id" validate:"required"`
+	Event        string `db:"event" validate:"required"`
+	TaskID       string `db:"task_id"`
+	Payload      string `db:"payload" validate:"required"`
+	Status       string `db:"status" validate:"required,oneof=pending success failed"`
+	Attempts     int    `db:"attempts" validate:"min=0"`
+	ResponseCode int    `db:"response_code"`
+	LastError    string `db:"last_error"`
+
+	// Timestamp using integer as unix microsecond in UTC
+	CreatedAt int64 `db:"created_at" validate:"required"`
+	UpdatedAt int64 `db:"updated_at" validate:"required"`
+}
+
+type InputInsertCallback struct {
+	Callback Callback `validate:"required"`
+}
+
+type OutInsertCallback struct {
+	Callback Callback
+}
+
+type InputListCallbacks struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutListCallbacks struct {
+	Callbacks []Callback
+}
+
+type InputDelCallback struct {
+	AppID int64 `validate:"required"`
+	ID    int64 `validate:"required"`
+}
+
+type OutDelCallback struct {
+	Success bool
+}
+
+type InputInsertDelivery struct {
+	Delivery Delivery `validate:"required"`
+}
+
+type OutInsertDelivery struct {
+	Delivery Delivery
+}
+
+// InputUpdateDelivery update the result of the latest attempt.
+type InputUpdateDelivery struct {
+	ID           int64  `validate:"required"`
+	Status       string `validate:"required,oneof=pending success failed"`
+	Attempts     int    `validate:"min=0"`
+	ResponseCode int    `validate:"min=0"`
+	LastError    string `validate:"-"`
+	UpdatedAt    int64  `validate:"required"`
+}
+
+type OutUpdateDelivery struct {
+	Success bool
+}
+
+// InputListDeliveries return the newest deliveries first, CallbackID and BeforeID is optional.
+type InputListDeliveries struct {
+	AppID      int64 `validate:"required"`
+	CallbackID int64 `validate:"min=0"`
+	BeforeID   int64 `validate:"min=0"`
+	Limit      int64 `validate:"required,min=1"`
+}
+
+type OutListDeliveries struct {
+	Deliveries []Delivery
+}
+
+// InputPurgeByAppID permanently delete all callbacks and its deliveries of the purged app.
+type InputPurgeByAppID struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutPurgeByAppID struct {
+	Deleted int64
+}
@@ -0,0 +1,248 @@
+package callbackrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	sqlInsertCallback = `
+INSERT INTO callbacks (id, app_id, url, secret, events, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+`
+
+	sqlListCallbacks = `SELECT * FROM callbacks WHERE app_id = $1 ORDER BY id ASC;`
+	sqlDelCallback   = `DELETE FROM callbacks WHERE id = $1 AND app_id = $2;`
+
+	sqlInsertDelivery = `
+INSERT INTO callback_deliveries (id, callback_id, app_id, event, task_id, payload, status, attempts, response_code, last_error, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+`
+
+	sqlUpdateDelivery = `
+UPDATE callback_deliveries SET
+    status = $2,
+    attempts = $3,
+    response_code = $4,
+    last_error = $5,
+    updated_at = $6
+WHERE id = $1;
+`
+
+	// sqlListDeliveries callback id and before id is optional, zero means no filter
+	sqlListDeliveries = `
+SELECT * FROM callback_deliveries
+WHERE app_id = $1 AND ($2 = 0 OR callback_id = $2) AND ($3 = 0 OR id < $3)
+ORDER BY id DESC LIMIT $4;
+`
+
+	sqlPurgeCallbackDeliveriesByAppID = `DELETE FROM callback_deliveries WHERE app_id = $1;`
+	sqlPurgeCallbacksByAppID          = `DELETE FROM callbacks WHERE app_id = $1;`
+)
+
+type PostgresConfig struct {
+	Connection sqlx.ExtContext `validate:"required"`
+}
+
+type Postgres struct {
+	Config PostgresConfig
+}
+
+var _ Repo = (*Postgres)(nil)
+
+func NewPostgres(cfg PostgresConfig) (repo *Postgres, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	repo = &Postgres{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (p *Postgres) InsertCallback(ctx context.Context, in InputInsertCallback) (out OutInsertCallback, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	c := in.Callback
+	args := []interface{}{
+		c.ID, c.AppID, c.URL, c.Secret, c.Events, c.CreatedAt, c.UpdatedAt,
+	}
+
+	var callback Callback
+	err = sqlx.GetContext(ctx, p.Config.Connection, &callback, sqlInsertCallback, args...)
+	if err != nil {
+		err = fmt.Errorf("insert callback '%s' error: %w", c.URL, err)
+		return
+	}
+
+	out = OutInsertCallback{
+		Callback: callback,
+	}
+
+	return
+}
+
+func (p *Postgres) ListCallbacks(ctx context.Context, in InputListCallbacks) (out OutListCallbacks, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "callbackrepo.ListCallbacks")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	callbacks := make([]Callback, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &callbacks, sqlListCallbacks, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot get callbacks: %w", err)
+		return
+	}
+
+	out = OutListCallbacks{
+		Callbacks: callbacks,
+	}
+
+	return
+}
+
+func (p *Postgres) DelCallback(ctx context.Context, in InputDelCallback) (out OutDelCallback, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlDelCallback, in.ID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("delete callback id %d error: %w", in.ID, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutDelCallback{
+		Success: affected > 0,
+	}
+
+	return
+}
+
+func (p *Postgres) InsertDelivery(ctx context.Context, in InputInsertDelivery) (out OutInsertDelivery, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	d := in.Delivery
+	args := []interface{}{
+		d.ID, d.CallbackID, d.AppID, d.Event, d.TaskID, d.Payload,
+		d.Status, d.Attempts, d.ResponseCode, d.LastError, d.CreatedAt, d.UpdatedAt,
+	}
+
+	var delivery Delivery
+	err = sqlx.GetContext(ctx, p.Config.Connection, &delivery, sqlInsertDelivery, args...)
+	if err != nil {
+		err = fmt.Errorf("insert delivery of callback id %d error: %w", d.CallbackID, err)
+		return
+	}
+
+	out = OutInsertDelivery{
+		Delivery: delivery,
+	}
+
+	return
+}
+
+func (p *Postgres) UpdateDelivery(ctx context.Context, in InputUpdateDelivery) (out OutUpdateDelivery, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlUpdateDelivery,
+		in.ID, in.Status, in.Attempts, in.ResponseCode, in.LastError, in.UpdatedAt,
+	)
+	if err != nil {
+		err = fmt.Errorf("update delivery id %d error: %w", in.ID, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutUpdateDelivery{
+		Success: affected > 0,
+	}
+
+	return
+}
+
+func (p *Postgres) ListDeliveries(ctx context.Context, in InputListDeliveries) (out OutListDeliveries, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "callbackrepo.ListDeliveries")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	deliveries := make([]Delivery, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &deliveries, sqlListDeliveries,
+		in.AppID, in.CallbackID, in.BeforeID, in.Limit,
+	)
+	if err != nil {
+		err = fmt.Errorf("cannot get callback deliveries: %w", err)
+		return
+	}
+
+	out = OutListDeliveries{
+		Deliveries: deliveries,
+	}
+
+	return
+}
+
+// PurgeByAppID delete the deliveries first, so it is not left when deleting the callbacks failed.
+func (p *Postgres) PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	_, err = p.Config.Connection.ExecContext(ctx, sqlPurgeCallbackDeliveriesByAppID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("purge callback deliveries of app id %d error: %w", in.AppID, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlPurgeCallbacksByAppID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("purge callbacks of app id %d error: %w", in.AppID, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutPurgeByAppID{
+		Deleted: affected,
+	}
+
+	return
+}
@@ -0,0 +1,219 @@
+package callbacksvc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ylog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxBackoff is the maximum wait time between retries.
+const maxBackoff = time.Hour
+
+// updateTimeout is the timeout to save the delivery log after each attempt.
+const updateTimeout = 10 * time.Second
+
+type dispatchJob struct {
+	Callback Callback
+	Delivery Delivery
+	Attempt  int // attempt number of this job, starting from 1
+}
+
+// dispatcher call the callback url using worker pool. Failed attempt is re-queued after the backoff,
+// so the worker is not blocked while waiting. Pending retry is lost when the dispatcher is closed.
+type dispatcher struct {
+	Config Config
+	queue  chan dispatchJob
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func newDispatcher(cfg Config) *dispatcher {
+	d := &dispatcher{
+		Config: cfg,
+		queue:  make(chan dispatchJob, cfg.MaxBuffer),
+		stop:   make(chan struct{}),
+	}
+
+	for i := 1; i <= cfg.MaxWorker; i++ {
+		go d.worker()
+	}
+
+	return d
+}
+
+// close stop the workers and the pending retries, the delivery being sent is finished first.
+func (d *dispatcher) close() {
+	d.once.Do(func() {
+		close(d.stop)
+	})
+}
+
+// enqueue return false when the queue is full, the delivery is marked as failed.
+func (d *dispatcher) enqueue(ctx context.Context, callback Callback, delivery Delivery) bool {
+	select {
+	case d.queue <- dispatchJob{Callback: callback, Delivery: delivery, Attempt: 1}:
+		return true
+	default:
+	}
+
+	metric.CallbackAttempts.WithLabelValues(delivery.Event, callbackrepo.StatusFailed).Inc()
+	d.update(ctx, delivery.ID, callbackrepo.StatusFailed, 0, 0, "dispatcher queue is full")
+	return false
+}
+
+func (d *dispatcher) worker() {
+	for {
+		select {
+		case <-d.stop:
+			return
+		case job := <-d.queue:
+			d.dispatch(job)
+		}
+	}
+}
+
+func (d *dispatcher) dispatch(job dispatchJob) {
+	ctx, span := tracer.StartSpan(context.Background(), "callbacksvc.dispatch")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("callback_id", job.Callback.ID),
+		attribute.Int64("delivery_id", job.Delivery.ID),
+		attribute.Int("attempt", job.Attempt),
+	)
+
+	responseCode, err := d.send(ctx, job)
+	if err == nil {
+		metric.CallbackAttempts.WithLabelValues(job.Delivery.Event, callbackrepo.StatusSuccess).Inc()
+		d.update(ctx, job.Delivery.ID, callbackrepo.StatusSuccess, job.Attempt, responseCode, "")
+		return
+	}
+
+	span.RecordError(err)
+	if job.Attempt >= d.Config.MaxAttempts {
+		metric.CallbackAttempts.WithLabelValues(job.Delivery.Event, callbackrepo.StatusFailed).Inc()
+		d.update(ctx, job.Delivery.ID, callbackrepo.StatusFailed, job.Attempt, responseCode, err.Error())
+		return
+	}
+
+	metric.CallbackAttempts.WithLabelValues(job.Delivery.Event, "retry").Inc()
+	d.update(ctx, job.Delivery.ID, callbackrepo.StatusPending, job.Attempt, responseCode, err.Error())
+
+	next := job
+	next.Attempt++
+	time.AfterFunc(retryBackoff(d.Config.Backoff, job.Attempt), func() {
+		d.retry(next)
+	})
+}
+
+// retry re-queue the job, it waits until the queue has room or the dispatcher is closed.
+// The delivery is kept as pending when the dispatcher is closed, so it can be found in the delivery log.
+func (d *dispatcher) retry(job dispatchJob) {
+	select {
+	case d.queue <- job:
+	case <-d.stop:
+		ylog.Warn(context.Background(), "callback retry is dropped, dispatcher is closed",
+			ylog.KV("delivery_id", job.Delivery.ID),
+			ylog.KV("attempt", job.Attempt),
+		)
+	}
+}
+
+// send return error when the callback url cannot be called or response with non 2xx status code.
+func (d *dispatcher) send(ctx context.Context, job dispatchJob) (responseCode int, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "callbacksvc.send")
+	defer span.End()
+
+	body := []byte(job.Delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.Callback.URL, bytes.NewReader(body))
+	if err != nil {
+		err = fmt.Errorf("cannot create callback request: %w", err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, job.Delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(job.Delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Callback.Secret, timestamp, body))
+
+	resp, err := d.Config.HTTPClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("cannot call callback url: %w", err)
+		return
+	}
+
+	defer func() {
+		// drain the body, so the connection can be reused
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		if _err := resp.Body.Close(); _err != nil {
+			ylog.Error(ctx, "cannot close callback response body", ylog.KV("error", _err))
+		}
+	}()
+
+	responseCode = resp.StatusCode
+	if responseCode < 200 || responseCode >= 300 {
+		err = fmt.Errorf("callback url response with status code %d", responseCode)
+		return
+	}
+
+	return
+}
+
+func (d *dispatcher) update(ctx context.Context, id int64, status string, attempts, responseCode int, lastError string) {
+	// use new context with timeout, since the caller context may already be done
+	updateCtx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+
+	_, err := d.Config.CallbackRepo.UpdateDelivery(updateCtx, callbackrepo.InputUpdateDelivery{
+		ID:           id,
+		Status:       status,
+		Attempts:     attempts,
+		ResponseCode: responseCode,
+		LastError:    lastError,
+		UpdatedAt:    time.Now().UTC().UnixMicro(),
+	})
+	if err != nil {
+		ylog.Error(ctx, "cannot update callback delivery log", ylog.KV("delivery_id", id), ylog.KV("error", err))
+	}
+}
+
+// Sign return the value of HeaderSignature: sha256=<hex of HMAC SHA256 of "<timestamp>.<body>">.
+// The receiver must compute the same value using the callback secret and compare it in constant time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryBackoff return base * 2^(attempt-1), maximum is maxBackoff.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,198 @@
+package callbacksvc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sony/sonyflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1667260800.{"id":"1"}' | openssl dgst -sha256 -hmac 'secret'
+	sig := Sign("secret", 1667260800, []byte(`{"id":"1"}`))
+	assert.Equal(t, "sha256=bb23a19c919330e5d26018ed6287110fd017f5434ba63a84de65da97ee271e11", sig)
+	assert.NotEqual(t, sig, Sign("secret", 1667260801, []byte(`{"id":"1"}`)))
+	assert.NotEqual(t, sig, Sign("other", 1667260800, []byte(`{"id":"1"}`)))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, retryBackoff(5*time.Second, 1))
+	assert.Equal(t, 10*time.Second, retryBackoff(5*time.Second, 2))
+	assert.Equal(t, 40*time.Second, retryBackoff(5*time.Second, 4))
+	assert.Equal(t, maxBackoff, retryBackoff(5*time.Second, 100))
+}
+
+func newTestService(t *testing.T, maxAttempts int, allowPrivateNetwork bool) *ServiceDefault {
+	ctx := context.Background()
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	// each connection of :memory: is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := migration.Load(assets.Migrations, "migrations/sqlite/callbackrepo")
+	assert.NoError(t, err)
+
+	migrator, err := migration.New(migration.Config{DB: db, Dialect: "sqlite", Service: "callback", Migrations: migrations})
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+
+	repo, err := callbackrepo.NewSQLite(callbackrepo.SQLiteConfig{Connection: db})
+	assert.NoError(t, err)
+
+	svc, err := New(Config{
+		UIDGen:       sonyflake.NewSonyflake(sonyflake.Settings{MachineID: func() (uint16, error) { return 1, nil }}),
+		CallbackRepo: repo,
+		HTTPClient:   &http.Client{Transport: NewTransport(allowPrivateNetwork), Timeout: time.Second},
+		MaxBuffer:    10,
+		MaxWorker:    1,
+		MaxAttempts:  maxAttempts,
+		Backoff:      10 * time.Millisecond,
+
+		AllowPrivateNetwork: allowPrivateNetwork,
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() { _ = svc.Close() })
+	return svc
+}
+
+// waitDelivery wait until the only delivery of the app is not pending anymore.
+func waitDelivery(t *testing.T, svc *ServiceDefault, appID int64) (delivery Delivery) {
+	assert.Eventually(t, func() bool {
+		out, err := svc.ListDeliveries(context.Background(), InListDeliveries{AppID: appID})
+		if err != nil || len(out.Deliveries) != 1 {
+			return false
+		}
+
+		delivery = out.Deliveries[0]
+		return delivery.Status != callbackrepo.StatusPending
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return
+}
+
+func TestServiceDefault_EmitRetry(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, 3, true)
+
+	var calls int32
+	var secret string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		body, _ := io.ReadAll(r.Body)
+
+		assert.Equal(t, EventTaskCompleted, r.Header.Get(HeaderEvent))
+		assert.Equal(t, Sign(secret, timestamp, body), r.Header.Get(HeaderSignature))
+
+		// the first attempt is failed, so it is retried
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	outRegister, err := svc.Register(ctx, InRegister{AppID: 1, URL: server.URL, Events: []string{EventTaskCompleted}})
+	assert.NoError(t, err)
+	secret = outRegister.Callback.Secret
+
+	outEmit, err := svc.Emit(ctx, InEmit{
+		AppID:    1,
+		ClientID: "app1",
+		TaskID:   "task1",
+		Events: []InEmitEvent{
+			{Type: EventTaskCompleted, Data: map[string]string{"k": "v"}},
+			{Type: EventTokenInvalid, Data: map[string]string{"k": "v"}}, // not subscribed
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, outEmit.Queued)
+
+	delivery := waitDelivery(t, svc, 1)
+	assert.Equal(t, callbackrepo.StatusSuccess, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestServiceDefault_EmitMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, 2, true)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := svc.Register(ctx, InRegister{AppID: 1, URL: server.URL, Events: []string{EventTaskCompleted}})
+	assert.NoError(t, err)
+
+	_, err = svc.Emit(ctx, InEmit{AppID: 1, ClientID: "app1", Events: []InEmitEvent{{Type: EventTaskCompleted, Data: "done"}}})
+	assert.NoError(t, err)
+
+	delivery := waitDelivery(t, svc, 1)
+	assert.Equal(t, callbackrepo.StatusFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusBadGateway, delivery.ResponseCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestServiceDefault_ForbiddenAddress(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t, 1, false)
+
+	for _, url := range []string{"http://127.0.0.1/hook", "http://10.0.0.1/hook", "http://169.254.169.254/latest", "http://[::1]/hook"} {
+		_, err := svc.Register(ctx, InRegister{AppID: 1, URL: url, Events: []string{EventTaskCompleted}})
+		assert.ErrorIs(t, err, ErrValidation, url)
+		assert.ErrorContains(t, err, ErrForbiddenAddress.Error(), url)
+	}
+
+	// the address is checked again when connecting, i.e: the DNS record is changed after the callback registered
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("forbidden address must not be called")
+	}))
+	defer server.Close()
+
+	_, err := svc.dispatcher.send(ctx, dispatchJob{Callback: Callback{URL: server.URL}, Delivery: Delivery{Payload: "{}"}})
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
+
+func TestDispatcher_RetryAfterClose(t *testing.T) {
+	d := &dispatcher{queue: make(chan dispatchJob, 1), stop: make(chan struct{})}
+	d.queue <- dispatchJob{}
+	d.close()
+
+	// the queue is full, the retry must not block after the dispatcher is closed
+	done := make(chan struct{})
+	go func() {
+		d.retry(dispatchJob{Attempt: 2})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("retry is blocked after the dispatcher is closed")
+	}
+}
@@ -0,0 +1,143 @@
+package callbacksvc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+	ErrQueueFull  = errors.New("callback emit queue is full")
+)
+
+// Event types that can be subscribed by the callback.
+const (
+	// EventTaskCompleted is emitted once after each message task is processed.
+	EventTaskCompleted = "task.completed"
+
+	// EventProviderFailed is emitted per push notification provider that has backend errors.
+	EventProviderFailed = "provider.failed"
+
+	// EventTokenInvalid is emitted per push notification provider that reports invalid recipients,
+	// i.e: unregistered FCM token, so the app can remove it.
+	EventTokenInvalid = "token.invalid"
+)
+
+// Events is all known event types.
+var Events = []string{EventTaskCompleted, EventProviderFailed, EventTokenInvalid}
+
+// Signature headers sent in each callback request.
+const (
+	HeaderEvent     = "X-Ngendika-Event"
+	HeaderDelivery  = "X-Ngendika-Delivery"
+	HeaderTimestamp = "X-Ngendika-Timestamp"
+	HeaderSignature = "X-Ngendika-Signature" // sha256=<hex of HMAC SHA256 of "<timestamp>.<body>" using the callback secret>
+)
+
+// Service manage app callbacks (outbound webhooks) and dispatch the events asynchronously with retries.
+type Service interface {
+	Register(ctx context.Context, in InRegister) (out OutRegister, err error)
+	List(ctx context.Context, in InList) (out OutList, err error)
+	Delete(ctx context.Context, in InDelete) (out OutDelete, err error)
+	ListDeliveries(ctx context.Context, in InListDeliveries) (out OutListDeliveries, err error)
+
+	// Emit queue the events and returns immediately, or ErrQueueFull when the queue is full.
+	// In the background, the delivery log of each callback subscribed to the events is saved,
+	// then the callback url is called by the dispatcher.
+	Emit(ctx context.Context, in InEmit) (out OutEmit, err error)
+
+	// Close stop the dispatcher, the queued events and pending retries is not sent.
+	Close() error
+}
+
+type Callback struct {
+	ID        int64
+	AppID     int64
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Delivery struct {
+	ID           int64
+	CallbackID   int64
+	Event        string
+	TaskID       string
+	Payload      string
+	Status       string // pending, success or failed
+	Attempts     int
+	ResponseCode int
+	LastError    string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Event is the JSON body sent to the callback url.
+type Event struct {
+	ID        string      `json:"id"` // delivery id, use it to ignore duplicate event on retry
+	Type      string      `json:"type"`
+	ClientID  string      `json:"client_id"`
+	TaskID    string      `json:"task_id,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type InRegister struct {
+	AppID  int64    `validate:"required"`
+	URL    string   `validate:"required,url,startswith=http"`
+	Secret string   `validate:"omitempty,min=16"` // generated when empty
+	Events []string `validate:"required,min=1,dive,oneof=task.completed provider.failed token.invalid"`
+}
+
+type OutRegister struct {
+	Callback Callback
+}
+
+type InList struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutList struct {
+	Callbacks []Callback
+}
+
+type InDelete struct {
+	AppID int64 `validate:"required"`
+	ID    int64 `validate:"required"`
+}
+
+type OutDelete struct {
+	Success bool
+}
+
+type InListDeliveries struct {
+	AppID      int64 `validate:"required"`
+	CallbackID int64 `validate:"min=0"`
+	BeforeID   int64 `validate:"min=0"`
+	Limit      int64 `validate:"min=0"` // default and maximum 100
+}
+
+type OutListDeliveries struct {
+	Limit      int64
+	Deliveries []Delivery
+}
+
+type InEmitEvent struct {
+	Type string      `validate:"required,oneof=task.completed provider.failed token.invalid"`
+	Data interface{} `validate:"required"`
+}
+
+type InEmit struct {
+	AppID    int64         `validate:"required"`
+	ClientID string        `validate:"required"`
+	TaskID   string        `validate:"-"`
+	Events   []InEmitEvent `validate:"required,min=1,dive"`
+}
+
+type OutEmit struct {
+	// Queued number of events queued to be saved and dispatched
+	Queued int
+}
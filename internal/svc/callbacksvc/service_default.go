@@ -0,0 +1,391 @@
+package callbacksvc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/segmentio/encoding/json"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/uid"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ylog"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	UIDGen       uid.UID           `validate:"required"`
+	CallbackRepo callbackrepo.Repo `validate:"required"`
+	HTTPClient   *http.Client      `validate:"required"`
+
+	MaxBuffer   int `validate:"required,min=1"` // MaxBuffer number of delivery waiting in dispatcher queue
+	MaxWorker   int `validate:"required,min=1"` // MaxWorker number of go routine calling the callback url
+	MaxAttempts int `validate:"required,min=1"` // MaxAttempts including the first attempt
+
+	// Backoff is the wait time before the first retry, doubled on each retry.
+	Backoff time.Duration `validate:"required"`
+
+	// AllowPrivateNetwork allow registering private, loopback or link-local callback url, i.e: for local development.
+	// HTTPClient must use NewTransport with the same value, so the address is also checked when connecting.
+	AllowPrivateNetwork bool
+}
+
+// emitJob is the events emitted by Emit, saved and dispatched in the background.
+type emitJob struct {
+	In   InEmit
+	Link trace.Link // link to the span of the caller, since the caller span may end before the job is processed
+}
+
+type ServiceDefault struct {
+	Config     Config
+	dispatcher *dispatcher
+	emits      chan emitJob
+}
+
+var _ Service = (*ServiceDefault)(nil)
+
+func New(cfg Config) (svc *ServiceDefault, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svc = &ServiceDefault{
+		Config:     cfg,
+		dispatcher: newDispatcher(cfg),
+		emits:      make(chan emitJob, cfg.MaxBuffer),
+	}
+
+	go svc.emitter()
+	return
+}
+
+// Close stop the dispatcher, the queued events and pending retries is not sent.
+func (s *ServiceDefault) Close() error {
+	s.dispatcher.close()
+	return nil
+}
+
+func (s *ServiceDefault) Register(ctx context.Context, in InRegister) (out OutRegister, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error register callback: %s", ErrValidation, err)
+		return
+	}
+
+	if !s.Config.AllowPrivateNetwork {
+		if err = checkURL(ctx, in.URL); err != nil {
+			err = fmt.Errorf("%w: error register callback: %s", ErrValidation, err)
+			return
+		}
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		secret, err = generateSecret()
+		if err != nil {
+			return
+		}
+	}
+
+	id, err := s.Config.UIDGen.NextID()
+	if err != nil {
+		err = fmt.Errorf("cannot generate uid for new record: %w", err)
+		return
+	}
+
+	now := time.Now().UTC().UnixMicro()
+	outInsert, err := s.Config.CallbackRepo.InsertCallback(ctx, callbackrepo.InputInsertCallback{
+		Callback: callbackrepo.Callback{
+			ID:        int64(id),
+			AppID:     in.AppID,
+			URL:       in.URL,
+			Secret:    secret,
+			Events:    strings.Join(uniqueEvents(in.Events), ","),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot register callback: %w", err)
+		return
+	}
+
+	out = OutRegister{
+		Callback: callbackFromRepo(outInsert.Callback),
+	}
+
+	return
+}
+
+func (s *ServiceDefault) List(ctx context.Context, in InList) (out OutList, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outList, err := s.Config.CallbackRepo.ListCallbacks(ctx, callbackrepo.InputListCallbacks{
+		AppID: in.AppID,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot list callbacks: %w", err)
+		return
+	}
+
+	callbacks := make([]Callback, 0)
+	for _, c := range outList.Callbacks {
+		callbacks = append(callbacks, callbackFromRepo(c))
+	}
+
+	out = OutList{
+		Callbacks: callbacks,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Delete(ctx context.Context, in InDelete) (out OutDelete, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outDel, err := s.Config.CallbackRepo.DelCallback(ctx, callbackrepo.InputDelCallback{
+		AppID: in.AppID,
+		ID:    in.ID,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot delete callback id %d: %w", in.ID, err)
+		return
+	}
+
+	out = OutDelete{
+		Success: outDel.Success,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) ListDeliveries(ctx context.Context, in InListDeliveries) (out OutListDeliveries, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	// set to the default value
+	if in.Limit <= 0 || in.Limit > 100 {
+		in.Limit = 100
+	}
+
+	outList, err := s.Config.CallbackRepo.ListDeliveries(ctx, callbackrepo.InputListDeliveries{
+		AppID:      in.AppID,
+		CallbackID: in.CallbackID,
+		BeforeID:   in.BeforeID,
+		Limit:      in.Limit,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot list callback deliveries: %w", err)
+		return
+	}
+
+	deliveries := make([]Delivery, 0)
+	for _, d := range outList.Deliveries {
+		deliveries = append(deliveries, deliveryFromRepo(d))
+	}
+
+	out = OutListDeliveries{
+		Limit:      in.Limit,
+		Deliveries: deliveries,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Emit(ctx context.Context, in InEmit) (out OutEmit, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "callbacksvc.Emit")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error emit callback events: %s", ErrValidation, err)
+		return
+	}
+
+	select {
+	case s.emits <- emitJob{In: in, Link: trace.LinkFromContext(ctx)}:
+		out.Queued = len(in.Events)
+	default:
+		err = fmt.Errorf("%w: %d events of task '%s' is dropped", ErrQueueFull, len(in.Events), in.TaskID)
+	}
+
+	return
+}
+
+// emitter save and dispatch the emitted events one by one, until the dispatcher is closed.
+func (s *ServiceDefault) emitter() {
+	for {
+		select {
+		case <-s.dispatcher.stop:
+			return
+		case job := <-s.emits:
+			s.emit(job)
+		}
+	}
+}
+
+// emit save the delivery log of each callback subscribed to the events, then queue it to the dispatcher.
+func (s *ServiceDefault) emit(job emitJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "callbacksvc.emit", trace.WithLinks(job.Link))
+	defer span.End()
+
+	in := job.In
+	if err := s.saveDeliveries(ctx, in); err != nil {
+		span.RecordError(err)
+		ylog.Error(ctx, "cannot emit callback events", ylog.KV("task_id", in.TaskID), ylog.KV("error", err))
+	}
+}
+
+func (s *ServiceDefault) saveDeliveries(ctx context.Context, in InEmit) (err error) {
+	outList, err := s.Config.CallbackRepo.ListCallbacks(ctx, callbackrepo.InputListCallbacks{
+		AppID: in.AppID,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot get callbacks to emit: %w", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, c := range outList.Callbacks {
+		callback := callbackFromRepo(c)
+		for _, event := range in.Events {
+			if !callback.Subscribed(event.Type) {
+				continue
+			}
+
+			id, _err := s.Config.UIDGen.NextID()
+			if _err != nil {
+				err = fmt.Errorf("cannot generate uid for new record: %w", _err)
+				return
+			}
+
+			payload, _err := json.Marshal(Event{
+				ID:        strconv.FormatUint(id, 10),
+				Type:      event.Type,
+				ClientID:  in.ClientID,
+				TaskID:    in.TaskID,
+				CreatedAt: now,
+				Data:      event.Data,
+			})
+			if _err != nil {
+				err = fmt.Errorf("cannot marshal event '%s': %w", event.Type, _err)
+				return
+			}
+
+			outInsert, _err := s.Config.CallbackRepo.InsertDelivery(ctx, callbackrepo.InputInsertDelivery{
+				Delivery: callbackrepo.Delivery{
+					ID:         int64(id),
+					CallbackID: callback.ID,
+					AppID:      in.AppID,
+					Event:      event.Type,
+					TaskID:     in.TaskID,
+					Payload:    string(payload),
+					Status:     callbackrepo.StatusPending,
+					CreatedAt:  now.UnixMicro(),
+					UpdatedAt:  now.UnixMicro(),
+				},
+			})
+			if _err != nil {
+				err = fmt.Errorf("cannot save delivery of event '%s': %w", event.Type, _err)
+				return
+			}
+
+			s.dispatcher.enqueue(ctx, callback, deliveryFromRepo(outInsert.Delivery))
+		}
+	}
+
+	return
+}
+
+// Subscribed return true when the callback subscribe the event type.
+func (c Callback) Subscribed(eventType string) bool {
+	for _, e := range c.Events {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate callback secret: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func uniqueEvents(events []string) []string {
+	seen := make(map[string]struct{})
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		if _, ok := seen[e]; ok {
+			continue
+		}
+
+		seen[e] = struct{}{}
+		out = append(out, e)
+	}
+
+	return out
+}
+
+func callbackFromRepo(c callbackrepo.Callback) Callback {
+	events := make([]string, 0)
+	for _, e := range strings.Split(c.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+
+	return Callback{
+		ID:        c.ID,
+		AppID:     c.AppID,
+		URL:       c.URL,
+		Secret:    c.Secret,
+		Events:    events,
+		CreatedAt: time.UnixMicro(c.CreatedAt).UTC(),
+		UpdatedAt: time.UnixMicro(c.UpdatedAt).UTC(),
+	}
+}
+
+func deliveryFromRepo(d callbackrepo.Delivery) Delivery {
+	return Delivery{
+		ID:           d.ID,
+		CallbackID:   d.CallbackID,
+		Event:        d.Event,
+		TaskID:       d.TaskID,
+		Payload:      d.Payload,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		LastError:    d.LastError,
+		CreatedAt:    time.UnixMicro(d.CreatedAt).UTC(),
+		UpdatedAt:    time.UnixMicro(d.UpdatedAt).UTC(),
+	}
+}
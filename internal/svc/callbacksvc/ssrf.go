@@ -0,0 +1,87 @@
+package callbacksvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when the callback url is private, loopback or link-local address,
+// so the callback cannot be used to reach internal services (SSRF), i.e: cloud metadata 169.254.169.254.
+var ErrForbiddenAddress = errors.New("callback url must not be private, loopback or link-local address")
+
+// forbiddenIP return true when the ip is not a public unicast address.
+func forbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// checkURL resolve the callback url host and return ErrForbiddenAddress when one of the address is forbidden.
+func checkURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback url: %w", err)
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if forbiddenIP(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve callback url host '%s': %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if forbiddenIP(addr.IP) {
+			return fmt.Errorf("%w: '%s' resolved to %s", ErrForbiddenAddress, host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// guardAddress is net.Dialer Control, it is called with the resolved address just before connecting.
+func guardAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, err)
+	}
+
+	if ip := net.ParseIP(host); ip == nil || forbiddenIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// NewTransport return the http.Transport to call the callback url.
+// Unless allowPrivateNetwork is true, it refuses to connect to forbidden address after the DNS is resolved,
+// so the host registered as public address cannot be changed later to point to internal services.
+func NewTransport(allowPrivateNetwork bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if allowPrivateNetwork {
+		return transport
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   guardAddress,
+	}
+
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil // the proxy connect to the callback url on our behalf, so the address cannot be guarded
+	return transport
+}
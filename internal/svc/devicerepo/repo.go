@@ -0,0 +1,73 @@
+package devicerepo
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+// Repo is device registry repository service
+type Repo interface {
+	Upsert(ctx context.Context, in InputUpsert) (out OutUpsert, err error)
+	Delete(ctx context.Context, in InputDelete) (out OutDelete, err error)
+	ListByUserIDs(ctx context.Context, in InputListByUserIDs) (out OutListByUserIDs, err error)
+	PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error)
+}
+
+// Device is resembles the table structure.
+type Device struct {
+	ID       int64  `db:"id" validate:"required"`
+	AppID    int64  `db:"app_id" validate:"required"`
+	UserID   string `db:"user_id" validate:"required"`
+	Provider string `db:"provider" validate:"required"`
+	Token    string `db:"token" validate:"required"`
+	Platform string `db:"platform" validate:"-"`
+	Locale   string `db:"locale" validate:"-"`
+
+	// Timestamp using integer as unix microsecond in UTC
+	LastSeenAt int64 `db:"last_seen_at" validate:"required"`
+	CreatedAt  int64 `db:"created_at" validate:"required"`
+	UpdatedAt  int64 `db:"updated_at" validate:"required"`
+}
+
+type InputUpsert struct {
+	Device Device `validate:"required"`
+}
+
+type OutUpsert struct {
+	Device Device
+}
+
+type InputDelete struct {
+	AppID    int64  `validate:"required"`
+	Provider string `validate:"required"`
+	Token    string `validate:"required"`
+}
+
+type OutDelete struct {
+	Success bool
+}
+
+type InputListByUserIDs struct {
+	AppID   int64    `validate:"required"`
+	UserIDs []string `validate:"required,min=1"`
+
+	// Provider is optional, empty means all providers
+	Provider string `validate:"-"`
+}
+
+type OutListByUserIDs struct {
+	Devices []Device
+}
+
+// InputPurgeByAppID permanently delete all devices of the purged app.
+type InputPurgeByAppID struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutPurgeByAppID struct {
+	Deleted int64
+}
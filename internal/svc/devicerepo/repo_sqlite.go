@@ -0,0 +1,184 @@
+package devicerepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SQLite numbered parameter ?NNN is used, so the arguments is in the same order as Postgres.
+const (
+	// sqlSqliteUpsert re-register the same token will move it to the latest user and update last seen
+	sqlSqliteUpsert = `
+INSERT INTO devices (id, app_id, user_id, provider, token, platform, locale, last_seen_at, created_at, updated_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+ON CONFLICT (app_id, provider, token)
+DO UPDATE SET
+    user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    locale = EXCLUDED.locale,
+    last_seen_at = EXCLUDED.last_seen_at,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+`
+
+	sqlSqliteDelete = `DELETE FROM devices WHERE app_id = ?1 AND provider = ?2 AND token = ?3 RETURNING *;`
+
+	sqlSqlitePurgeDevicesByAppID = `DELETE FROM devices WHERE app_id = ?1;`
+)
+
+type SQLiteConfig struct {
+	Connection sqlx.ExtContext `validate:"required"`
+}
+
+type SQLite struct {
+	Config SQLiteConfig
+}
+
+var _ Repo = (*SQLite)(nil)
+
+func NewSQLite(cfg SQLiteConfig) (repo *SQLite, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	repo = &SQLite{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (p *SQLite) Upsert(ctx context.Context, in InputUpsert) (out OutUpsert, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	args := []interface{}{
+		in.Device.ID,
+		in.Device.AppID,
+		in.Device.UserID,
+		in.Device.Provider,
+		in.Device.Token,
+		in.Device.Platform,
+		in.Device.Locale,
+		in.Device.LastSeenAt,
+		in.Device.CreatedAt,
+		in.Device.UpdatedAt,
+	}
+
+	var device Device
+	err = sqlx.GetContext(ctx, p.Config.Connection, &device, sqlSqliteUpsert, args...)
+	if err != nil {
+		err = fmt.Errorf("upsert db error: %w", err)
+		return
+	}
+
+	out = OutUpsert{
+		Device: device,
+	}
+
+	return
+}
+
+func (p *SQLite) Delete(ctx context.Context, in InputDelete) (out OutDelete, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var device Device
+	err = sqlx.GetContext(ctx, p.Config.Connection, &device, sqlSqliteDelete, in.AppID, in.Provider, in.Token)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutDelete{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		err = fmt.Errorf("delete db error: %w", err)
+		return
+	}
+
+	out = OutDelete{
+		Success: device.Token == in.Token,
+	}
+
+	return
+}
+
+func (p *SQLite) ListByUserIDs(ctx context.Context, in InputListByUserIDs) (out OutListByUserIDs, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "devicerepo.ListByUserIDs")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var (
+		query string
+		args  []interface{}
+	)
+
+	if in.Provider == "" {
+		query, args, err = sqlx.In(SqlListByUserIDs, in.AppID, in.UserIDs)
+	} else {
+		query, args, err = sqlx.In(SqlListByUserIDsWithProvider, in.AppID, in.Provider, in.UserIDs)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("cannot generate sql query: %w", err)
+		return
+	}
+
+	// SQLite is using question mark, so no need to rebind
+	devices := make([]Device, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &devices, query, args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get devices by user ids: %w", err)
+		return
+	}
+
+	out = OutListByUserIDs{
+		Devices: devices,
+	}
+
+	return
+}
+
+func (p *SQLite) PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlSqlitePurgeDevicesByAppID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("purge devices of app id %d error: %w", in.AppID, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutPurgeByAppID{
+		Deleted: affected,
+	}
+
+	return
+}
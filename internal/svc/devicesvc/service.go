@@ -0,0 +1,81 @@
+package devicesvc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+// Service is device registry, it maps external user id into provider specific recipients.
+type Service interface {
+	Register(ctx context.Context, in InRegister) (out OutRegister, err error)
+	Unregister(ctx context.Context, in InUnregister) (out OutUnregister, err error)
+	ListByUser(ctx context.Context, in InListByUser) (out OutListByUser, err error)
+
+	// ResolveRecipients return provider => list of recipient token owned by the users.
+	ResolveRecipients(ctx context.Context, in InResolveRecipients) (out OutResolveRecipients, err error)
+}
+
+// Device must not have any json or yaml tag, transport layer must define its own entity.
+type Device struct {
+	ID         int64
+	AppID      int64
+	UserID     string
+	Provider   string
+	Token      string
+	Platform   string
+	Locale     string
+	LastSeenAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type InDevice struct {
+	UserID   string `validate:"required"`
+	Provider string `validate:"required"`
+	Token    string `validate:"required"`
+	Platform string `validate:"omitempty,oneof=android ios web"`
+	Locale   string `validate:"-"`
+}
+
+type InRegister struct {
+	AppID  int64    `validate:"required"`
+	Device InDevice `validate:"required"`
+}
+
+type OutRegister struct {
+	Device Device
+}
+
+type InUnregister struct {
+	AppID    int64  `validate:"required"`
+	Provider string `validate:"required"`
+	Token    string `validate:"required"`
+}
+
+type OutUnregister struct {
+	Success bool
+}
+
+type InListByUser struct {
+	AppID  int64  `validate:"required"`
+	UserID string `validate:"required"`
+}
+
+type OutListByUser struct {
+	Devices []Device
+}
+
+type InResolveRecipients struct {
+	AppID   int64    `validate:"required"`
+	UserIDs []string `validate:"required,min=1"`
+}
+
+type OutResolveRecipients struct {
+	// Recipients provider => unique recipient tokens
+	Recipients map[string][]string
+}
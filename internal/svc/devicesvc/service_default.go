@@ -0,0 +1,201 @@
+package devicesvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/backend"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicerepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/uid"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	UIDGen     uid.UID         `validate:"required"`
+	DeviceRepo devicerepo.Repo `validate:"required"`
+}
+
+type ServiceDefault struct {
+	Config Config
+}
+
+var _ Service = (*ServiceDefault)(nil)
+
+func New(cfg Config) (svc *ServiceDefault, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svc = &ServiceDefault{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Register(ctx context.Context, in InRegister) (out OutRegister, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error register device: %s", ErrValidation, err)
+		return
+	}
+
+	provider := strings.TrimSpace(in.Device.Provider)
+	registered := false
+	for _, p := range backend.MuxBackend().ListProviders(ctx) {
+		if p == provider {
+			registered = true
+			break
+		}
+	}
+
+	if !registered {
+		err = fmt.Errorf("%w: provider '%s' is not registered", ErrValidation, provider)
+		return
+	}
+
+	id, err := s.Config.UIDGen.NextID()
+	if err != nil {
+		err = fmt.Errorf("cannot generate uid for new record: %w", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	outUpsert, err := s.Config.DeviceRepo.Upsert(ctx, devicerepo.InputUpsert{
+		Device: devicerepo.Device{
+			ID:         int64(id),
+			AppID:      in.AppID,
+			UserID:     strings.TrimSpace(in.Device.UserID),
+			Provider:   provider,
+			Token:      strings.TrimSpace(in.Device.Token),
+			Platform:   in.Device.Platform,
+			Locale:     in.Device.Locale,
+			LastSeenAt: now.UnixMicro(),
+			CreatedAt:  now.UnixMicro(),
+			UpdatedAt:  now.UnixMicro(),
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot register device: %w", err)
+		return
+	}
+
+	out = OutRegister{
+		Device: FromRepo(outUpsert.Device),
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Unregister(ctx context.Context, in InUnregister) (out OutUnregister, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error unregister device: %s", ErrValidation, err)
+		return
+	}
+
+	outDel, err := s.Config.DeviceRepo.Delete(ctx, devicerepo.InputDelete{
+		AppID:    in.AppID,
+		Provider: in.Provider,
+		Token:    in.Token,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot unregister device: %w", err)
+		return
+	}
+
+	out = OutUnregister{
+		Success: outDel.Success,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) ListByUser(ctx context.Context, in InListByUser) (out OutListByUser, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outList, err := s.Config.DeviceRepo.ListByUserIDs(ctx, devicerepo.InputListByUserIDs{
+		AppID:   in.AppID,
+		UserIDs: []string{in.UserID},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot list device of user '%s': %w", in.UserID, err)
+		return
+	}
+
+	devices := make([]Device, 0)
+	for _, device := range outList.Devices {
+		devices = append(devices, FromRepo(device))
+	}
+
+	out = OutListByUser{
+		Devices: devices,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) ResolveRecipients(ctx context.Context, in InResolveRecipients) (out OutResolveRecipients, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "devicesvc.ResolveRecipients")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outList, err := s.Config.DeviceRepo.ListByUserIDs(ctx, devicerepo.InputListByUserIDs{
+		AppID:   in.AppID,
+		UserIDs: in.UserIDs,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot resolve recipients: %w", err)
+		return
+	}
+
+	recipients := make(map[string][]string)
+	seen := make(map[string]struct{})
+	for _, device := range outList.Devices {
+		key := device.Provider + ":" + device.Token
+		if _, exist := seen[key]; exist {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		recipients[device.Provider] = append(recipients[device.Provider], device.Token)
+	}
+
+	out = OutResolveRecipients{
+		Recipients: recipients,
+	}
+
+	return
+}
+
+// -- func helper
+
+func FromRepo(d devicerepo.Device) Device {
+	return Device{
+		ID:         d.ID,
+		AppID:      d.AppID,
+		UserID:     d.UserID,
+		Provider:   d.Provider,
+		Token:      d.Token,
+		Platform:   d.Platform,
+		Locale:     d.Locale,
+		LastSeenAt: time.UnixMicro(d.LastSeenAt).UTC(),
+		CreatedAt:  time.UnixMicro(d.CreatedAt).UTC(),
+		UpdatedAt:  time.UnixMicro(d.UpdatedAt).UTC(),
+	}
+}
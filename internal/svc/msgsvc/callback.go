@@ -0,0 +1,111 @@
+package msgsvc
+
+import (
+	"context"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ylog"
+)
+
+// ProviderSummary is the result of one push notification provider in the callback event data.
+type ProviderSummary struct {
+	PnpID         int64  `json:"pnp_id"`
+	Provider      string `json:"provider"`
+	Label         string `json:"label"`
+	SuccessCount  int    `json:"success_count"`
+	FailureCount  int    `json:"failure_count"`
+	BackendErrors int    `json:"backend_errors"`
+}
+
+// TaskCompletedData is the data of callbacksvc.EventTaskCompleted.
+type TaskCompletedData struct {
+	Errors    []string          `json:"errors"`
+	Providers []ProviderSummary `json:"providers"`
+}
+
+// ProviderFailedData is the data of callbacksvc.EventProviderFailed.
+type ProviderFailedData struct {
+	PnpID         int64    `json:"pnp_id"`
+	Provider      string   `json:"provider"`
+	Label         string   `json:"label"`
+	BackendErrors []string `json:"backend_errors"`
+}
+
+// TokenInvalidData is the data of callbacksvc.EventTokenInvalid.
+type TokenInvalidData struct {
+	PnpID      int64    `json:"pnp_id"`
+	Provider   string   `json:"provider"`
+	Label      string   `json:"label"`
+	Recipients []string `json:"recipients"`
+}
+
+// emitCallbacks queue the task result to the app callbacks, the callback url is called in the background.
+// Error is only logged, since the message is already sent.
+func (p *SvcSync) emitCallbacks(ctx context.Context, out *OutProcess) {
+	_, err := p.Config.CallbackSvc.Emit(ctx, callbacksvc.InEmit{
+		AppID:    out.App.ID,
+		ClientID: out.App.ClientID,
+		TaskID:   out.TaskID,
+		Events:   callbackEvents(out),
+	})
+	if err != nil {
+		ylog.Error(ctx, "cannot emit callback events", ylog.KV("task_id", out.TaskID), ylog.KV("error", err))
+	}
+}
+
+// callbackEvents return one task.completed event, then provider.failed and token.invalid event per provider (if any).
+func callbackEvents(out *OutProcess) []callbacksvc.InEmitEvent {
+	errs := out.Errors
+	if errs == nil {
+		errs = make([]string, 0)
+	}
+
+	completed := TaskCompletedData{
+		Errors:    errs,
+		Providers: make([]ProviderSummary, 0, len(out.ReportGroup)),
+	}
+
+	events := make([]callbacksvc.InEmitEvent, 0)
+	for _, group := range out.ReportGroup {
+		summary := ProviderSummary{
+			PnpID:         group.PNP.ID,
+			Provider:      group.PNP.Provider,
+			Label:         group.PNP.Label,
+			BackendErrors: len(group.BackendErrors),
+		}
+
+		invalidRecipients := make([]string, 0)
+		for _, report := range group.BackendReports {
+			summary.SuccessCount += report.SuccessCount
+			summary.FailureCount += report.FailureCount
+			invalidRecipients = append(invalidRecipients, report.InvalidRecipients...)
+		}
+
+		completed.Providers = append(completed.Providers, summary)
+
+		if len(group.BackendErrors) > 0 {
+			events = append(events, callbacksvc.InEmitEvent{
+				Type: callbacksvc.EventProviderFailed,
+				Data: ProviderFailedData{
+					PnpID:         group.PNP.ID,
+					Provider:      group.PNP.Provider,
+					Label:         group.PNP.Label,
+					BackendErrors: group.BackendErrors,
+				},
+			})
+		}
+
+		if len(invalidRecipients) > 0 {
+			events = append(events, callbacksvc.InEmitEvent{
+				Type: callbacksvc.EventTokenInvalid,
+				Data: TokenInvalidData{
+					PnpID:      group.PNP.ID,
+					Provider:   group.PNP.Provider,
+					Label:      group.PNP.Label,
+					Recipients: invalidRecipients,
+				},
+			})
+		}
+	}
+
+	return append([]callbacksvc.InEmitEvent{{Type: callbacksvc.EventTaskCompleted, Data: completed}}, events...)
+}
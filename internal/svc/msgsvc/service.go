@@ -2,10 +2,16 @@ package msgsvc
 
 import (
 	"context"
+	"errors"
 	"github.com/yusufsyaifudin/ngendika/backend"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
 )
 
+var (
+	// ErrValidation is returned when the input is not valid, including the label which cannot be defaulted.
+	ErrValidation = errors.New("validation error")
+)
+
 // Service .
 type Service interface {
 	// Process only contain mux depend on message type, i.e: fcm will go to fcm service, webhook to webhook service.
@@ -13,17 +19,49 @@ type Service interface {
 	// But, this kind of service layering is what microservice do.
 	// Imagine that when you create checkout system, you need to validate user and payment info by calling another service.
 	Process(ctx context.Context, input *InputProcess) (out *OutProcess, err error)
+
+	// HealthCheck return error when the service cannot accept new message, i.e: the queue is full.
+	HealthCheck(ctx context.Context) error
 }
 
 // InputProcess never be as request response payload!
 type InputProcess struct {
 	TaskID   string `validate:"required"`
 	ClientID string `validate:"required"`
-	Label    string `validate:"required"`
+	Label    string `validate:"-"` // default to the app settings default label when empty
 
 	// We can send multiple payload at a time in one providers.
 	// For example: {"email" [{"subject": "1", "recipients": ["a"]}, {"subject": "2" "recipients": ["b"]}]}
-	Payloads map[string][]interface{} `validate:"required"`
+	Payloads map[string][]interface{} `validate:"required_without=TemplateID"`
+
+	// TemplateID when defined, the template is rendered using TemplateVars and Locale,
+	// then each provider payload is appended into Payloads.
+	TemplateID   int64
+	TemplateVars map[string]interface{}
+	Locale       string
+
+	// UserIDs is resolved into registered devices, then the tokens is passed as backend.Message Recipients.
+	// Provider without any registered devices for these users is skipped.
+	UserIDs []string `validate:"-"`
+
+	// Topics is resolved into all topic members (users and recipients), i.e: topic:news or news
+	Topics []string `validate:"-"`
+
+	// OnReport when defined, is called once per push notification provider as soon as all its messages are sent,
+	// before Process returns. The calls are serialized, so it is safe to write into a stream.
+	OnReport func(report ReportGroup) `validate:"-"`
+
+	// OnProgress when defined, is called every time the sender worker done sending one message (payload and recipients chunk)
+	// to the push notification provider, before Process returns. The calls are serialized from one go routine,
+	// so slow OnProgress (i.e: writing to the network) never holds the lock of the sender worker report.
+	OnProgress func(progress Progress) `validate:"-"`
+}
+
+// Progress is the result of one message sent by the sender worker.
+type Progress struct {
+	PNP           backend.PushNotificationProvider `json:"pnp"`
+	BackendError  string                           `json:"backend_error,omitempty"`
+	BackendReport *backend.Report                  `json:"backend_report,omitempty"`
 }
 
 type ReportGroup struct {
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"github.com/yusufsyaifudin/ngendika/backend"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/pnpsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"go.opentelemetry.io/otel/trace"
@@ -14,11 +19,15 @@ import (
 )
 
 type SvcSyncConfig struct {
-	AppSvc        appsvc.Service    `validate:"required"`
-	PNProviderSvc pnpsvc.Service    `validate:"required"`
-	PNSender      backend.SenderMux `validate:"required"`
-	MaxBuffer     int               `validate:"required,min=1"`
-	MaxWorker     int               `validate:"required,min=1"` // MaxWorker number of maximum go routine for all backend type
+	AppSvc        appsvc.Service      `validate:"required"`
+	PNProviderSvc pnpsvc.Service      `validate:"required"`
+	TemplateSvc   templatesvc.Service `validate:"required"`
+	DeviceSvc     devicesvc.Service   `validate:"required"`
+	TopicSvc      topicsvc.Service    `validate:"required"`
+	CallbackSvc   callbacksvc.Service `validate:"required"`
+	PNSender      backend.SenderMux   `validate:"required"`
+	MaxBuffer     int                 `validate:"required,min=1"`
+	MaxWorker     int                 `validate:"required,min=1"` // MaxWorker number of maximum go routine for all backend type
 }
 
 type SvcSync struct {
@@ -39,6 +48,9 @@ func New(cfg SvcSyncConfig) (*SvcSync, error) {
 		MessageQueue: make(chan senderWorkerJob, cfg.MaxBuffer),
 	}
 
+	metric.QueueCapacity.Set(float64(cfg.MaxBuffer))
+	metric.WorkersTotal.Add(float64(cfg.MaxWorker))
+
 	for i := 1; i <= cfg.MaxWorker; i++ {
 		go senderWorker(i, cfg.PNSender, svc.MessageQueue)
 	}
@@ -53,11 +65,13 @@ func (p *SvcSync) Process(ctx context.Context, input *InputProcess) (out *OutPro
 
 	err = validator.Validate(input)
 	if err != nil {
-		err = fmt.Errorf("validation error: %w", err)
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
 		return
 	}
 
-	getAppIn := appsvc.InputGetApp{ClientID: input.ClientID}
+	// suspended app return appsvc.ErrAppSuspended, so nothing is sent
+	enabled := true
+	getAppIn := appsvc.InputGetApp{ClientID: input.ClientID, Enabled: &enabled}
 	getAppOut, err := p.Config.AppSvc.GetApp(ctx, getAppIn)
 	if err != nil {
 		return
@@ -65,23 +79,77 @@ func (p *SvcSync) Process(ctx context.Context, input *InputProcess) (out *OutPro
 
 	app := getAppOut.App
 
-	lock := &sync.Mutex{}
-	wg := &sync.WaitGroup{}
+	label := input.Label
+	if label == "" {
+		label = app.Settings.DefaultLabel
+	}
 
-	errs := make([]string, 0)
-	wgReport := &senderWorkerJobReport{
-		BackendReports: map[int64][]backendReport{},
+	if label == "" {
+		err = fmt.Errorf("%w: label is required when app has no default label", ErrValidation)
+		return
 	}
 
-	allPnpMapByID := make(map[int64]backend.PushNotificationProvider)
+	allPayloads, err := p.renderTemplate(ctx, app, input)
+	if err != nil {
+		return
+	}
 
-	for provider, payloads := range input.Payloads {
+	// app settings is enforced before anything is sent, so the request is either rejected or fanned out entirely
+	for provider := range allPayloads {
+		if !app.Settings.ProviderAllowed(provider) {
+			err = fmt.Errorf("%w: '%s' on app client id '%s'", appsvc.ErrProviderNotAllowed, provider, app.ClientID)
+			return
+		}
+	}
+
+	recipients, err := p.resolveRecipients(ctx, app, input)
+	if err != nil {
+		return
+	}
+
+	errs := make([]string, 0)
+
+	// messages and push notification providers is resolved before anything is sent,
+	// so the daily quota is charged by the number of recipients that will be sent
+	plans := make([]sendPlan, 0)
+	var totalRecipients int64
+	for provider, payloads := range allPayloads {
+		var providerRecipients []string
+		if recipients != nil {
+			providerRecipients = recipients[provider]
+			if len(providerRecipients) <= 0 {
+				errs = append(errs, fmt.Sprintf("no recipient for provider '%s' from the user ids or topics", provider))
+				continue
+			}
+		}
+
+		// the messages is the same for all push notification providers of the same provider
+		providerMsgs := make([]*backend.Message, 0)
+		for _, payload := range payloads {
+			msgs, _err := splitMessages(ctx, p.Config.PNSender, provider, input.TaskID, payload, providerRecipients)
+			if _err != nil {
+				errs = append(errs, fmt.Sprintf("invalid payload of provider '%s': %s", provider, _err))
+				continue
+			}
+
+			providerMsgs = append(providerMsgs, msgs...)
+		}
+
+		if len(providerMsgs) <= 0 {
+			continue
+		}
+
+		providerRecipientCount := 0
+		for _, msg := range providerMsgs {
+			msg.TTL = app.Settings.DefaultTTLs[provider]
+			providerRecipientCount += recipientCount(msg)
+		}
 
 		// get push notification provider only one per provider
 		outGetServiceProvider, _err := p.Config.PNProviderSvc.GetByLabels(ctx, pnpsvc.InGetByLabels{
 			AppID:    app.ID,
 			Provider: provider,
-			Label:    input.Label,
+			Label:    label,
 		})
 		if _err != nil {
 			_err = fmt.Errorf("failed get service provider '%s': %w", provider, _err)
@@ -89,66 +157,262 @@ func (p *SvcSync) Process(ctx context.Context, input *InputProcess) (out *OutPro
 			continue
 		}
 
+		totalRecipients += int64(providerRecipientCount * len(outGetServiceProvider.PnProviders))
+		plans = append(plans, sendPlan{
+			Messages:    providerMsgs,
+			PnProviders: outGetServiceProvider.PnProviders,
+		})
+	}
+
+	if totalRecipients > 0 {
+		_, err = p.Config.AppSvc.UseDailyQuota(ctx, appsvc.InputUseDailyQuota{
+			AppID: app.ID,
+			Quota: app.Settings.DailySendQuota,
+			Count: totalRecipients,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	lock := &sync.Mutex{}
+	onReportLock := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+
+	wgReport := &senderWorkerJobReport{
+		BackendReports: map[int64][]backendReport{},
+	}
+
+	allPnpMapByID := make(map[int64]backend.PushNotificationProvider)
+
+	progress, stopProgress := startProgress(input.OnProgress)
+
+	for _, plan := range plans {
 		// we may get push notification config more than one, because we use label:* or label1,label2.
 		// so, we need to iterate every push notification configuration.
 		// And since one push notification can have array of payloads, we need to iterate that.
-		for _, pnProvider := range outGetServiceProvider.PnProviders {
+		for _, pnProvider := range plan.PnProviders {
 			allPnpMapByID[pnProvider.ID] = pnProvider
 
-			for _, payload := range payloads {
-				msg := &backend.Message{
-					ReferenceID: input.TaskID,
-					RawPayload:  payload,
-				}
+			// each provider has its own wait group, so the report can be emitted as soon as it completes
+			pnpWg := &sync.WaitGroup{}
+			for _, providerMsg := range plan.Messages {
+				msg := *providerMsg // copy, so each job own its message
 
-				wg.Add(1)
+				pnpWg.Add(1)
 				p.MessageQueue <- senderWorkerJob{
 					Ctx:             ctx,
 					Lock:            lock,
-					Wg:              wg,
+					Wg:              pnpWg,
 					SubmitTime:      time.Now(),
+					ClientID:        app.ClientID,
 					ServiceProvider: pnProvider,
-					Message:         msg,
+					Message:         &msg,
 					Report:          wgReport,
+					Progress:        progress,
 				}
+
+				metric.QueueDepth.Set(float64(len(p.MessageQueue)))
 			}
 
+			wg.Add(1)
+			go func(pnp backend.PushNotificationProvider) {
+				defer wg.Done()
+				pnpWg.Wait()
+
+				if input.OnReport == nil {
+					return
+				}
+
+				lock.Lock()
+				report := groupReport(pnp, wgReport.BackendReports[pnp.ID])
+				lock.Unlock()
+
+				onReportLock.Lock()
+				input.OnReport(report)
+				onReportLock.Unlock()
+			}(pnProvider)
 		}
 	}
 
 	wg.Wait()
+	stopProgress()
 
 	// Group report with the push notification provider
 	reportGroup := make([]ReportGroup, 0)
 	for pnpID, reports := range wgReport.BackendReports {
-		pnp := allPnpMapByID[pnpID]
+		reportGroup = append(reportGroup, groupReport(allPnpMapByID[pnpID], reports))
+	}
 
-		collectiveErr := make([]string, 0)
-		collectiveReport := make([]*backend.Report, 0)
+	out = &OutProcess{
+		TaskID:      input.TaskID,
+		App:         app,
+		Errors:      errs,
+		ReportGroup: reportGroup,
+	}
 
-		for _, report := range reports {
-			if report.BackendError != "" {
-				collectiveErr = append(collectiveErr, report.BackendError)
-			}
+	p.emitCallbacks(ctx, out)
+	return
+}
 
-			if report.BackendReport != nil {
-				collectiveReport = append(collectiveReport, report.BackendReport)
-			}
+// sendPlan is the messages of one provider, each message is sent using every push notification provider.
+type sendPlan struct {
+	Messages    []*backend.Message
+	PnProviders []backend.PushNotificationProvider
+}
+
+// HealthCheck return error when the sender queue is saturated, so new message will wait until worker is available.
+func (p *SvcSync) HealthCheck(_ context.Context) error {
+	depth, capacity := len(p.MessageQueue), cap(p.MessageQueue)
+	if depth >= capacity {
+		return fmt.Errorf("sender queue is saturated: %d of %d jobs", depth, capacity)
+	}
+
+	return nil
+}
+
+// groupReport collect the errors and reports of one push notification provider.
+func groupReport(pnp backend.PushNotificationProvider, reports []backendReport) ReportGroup {
+	collectiveErr := make([]string, 0)
+	collectiveReport := make([]*backend.Report, 0)
+
+	for _, report := range reports {
+		if report.BackendError != "" {
+			collectiveErr = append(collectiveErr, report.BackendError)
 		}
 
-		reportGroup = append(reportGroup, ReportGroup{
-			PNP:            pnp,
-			BackendErrors:  collectiveErr,
-			BackendReports: collectiveReport,
+		if report.BackendReport != nil {
+			collectiveReport = append(collectiveReport, report.BackendReport)
+		}
+	}
+
+	return ReportGroup{
+		PNP:            pnp,
+		BackendErrors:  collectiveErr,
+		BackendReports: collectiveReport,
+	}
+}
+
+// renderTemplate return the payloads from input merged with the rendered template (if any).
+func (p *SvcSync) renderTemplate(ctx context.Context, app appsvc.App, input *InputProcess) (payloads map[string][]interface{}, err error) {
+	payloads = make(map[string][]interface{})
+	for provider, providerPayloads := range input.Payloads {
+		payloads[provider] = append(payloads[provider], providerPayloads...)
+	}
+
+	if input.TemplateID == 0 {
+		return
+	}
+
+	outRender, err := p.Config.TemplateSvc.Render(ctx, templatesvc.InRender{
+		AppID:     app.ID,
+		ID:        input.TemplateID,
+		Locale:    input.Locale,
+		Variables: input.TemplateVars,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot render template id %d: %w", input.TemplateID, err)
+		return
+	}
+
+	for provider, payload := range outRender.Payloads {
+		payloads[provider] = append(payloads[provider], payload)
+	}
+
+	return
+}
+
+// resolveRecipients return nil when no user ids nor topics in input, so caller can distinguish with empty recipients.
+func (p *SvcSync) resolveRecipients(ctx context.Context, app appsvc.App, input *InputProcess) (recipients map[string][]string, err error) {
+	if len(input.UserIDs) <= 0 && len(input.Topics) <= 0 {
+		return
+	}
+
+	fromUsers := map[string][]string{}
+	if len(input.UserIDs) > 0 {
+		outResolve, _err := p.Config.DeviceSvc.ResolveRecipients(ctx, devicesvc.InResolveRecipients{
+			AppID:   app.ID,
+			UserIDs: input.UserIDs,
 		})
+		if _err != nil {
+			err = fmt.Errorf("cannot resolve user ids into devices: %w", _err)
+			return
+		}
+
+		fromUsers = outResolve.Recipients
 	}
 
-	out = &OutProcess{
-		TaskID:      input.TaskID,
-		App:         app,
-		Errors:      errs,
-		ReportGroup: reportGroup,
+	fromTopics := map[string][]string{}
+	if len(input.Topics) > 0 {
+		outResolve, _err := p.Config.TopicSvc.ResolveRecipients(ctx, topicsvc.InResolveRecipients{
+			AppID:  app.ID,
+			Topics: input.Topics,
+		})
+		if _err != nil {
+			err = fmt.Errorf("cannot resolve topics into recipients: %w", _err)
+			return
+		}
+
+		fromTopics = outResolve.Recipients
 	}
 
+	recipients = topicsvc.MergeRecipients(fromUsers, fromTopics)
 	return
 }
+
+// chunkRecipients split recipients with maximum size per chunk, size <= 0 means no limit.
+// Nil recipients return one nil chunk, so the payload is still sent once using its own recipients.
+// splitMessages merge the recipients defined in the payload (i.e: FCM tokens) with the resolved recipients without duplicate,
+// then split them based on the provider limit, i.e: fcm multicast only accept 500 tokens.
+// So the payload recipients is sent once, and no message carries more than the limit.
+func splitMessages(ctx context.Context, sender backend.SenderMux, provider, taskID string, rawPayload interface{}, recipients []string) ([]*backend.Message, error) {
+	payloadRecipients, payload, err := sender.ExtractRecipients(ctx, provider, rawPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make([]string, 0, len(payloadRecipients)+len(recipients))
+	seen := make(map[string]struct{}, len(payloadRecipients)+len(recipients))
+	for _, recipient := range append(payloadRecipients, recipients...) {
+		if _, exist := seen[recipient]; exist {
+			continue
+		}
+
+		seen[recipient] = struct{}{}
+		merged = append(merged, recipient)
+	}
+
+	chunks := chunkRecipients(merged, sender.MaxRecipients(ctx, provider))
+	msgs := make([]*backend.Message, 0, len(chunks))
+	for _, chunk := range chunks {
+		msgs = append(msgs, &backend.Message{
+			ReferenceID: taskID,
+			RawPayload:  payload,
+			Recipients:  chunk,
+		})
+	}
+
+	return msgs, nil
+}
+
+func chunkRecipients(recipients []string, size int) [][]string {
+	if len(recipients) <= 0 {
+		return [][]string{nil}
+	}
+
+	if size <= 0 || len(recipients) <= size {
+		return [][]string{recipients}
+	}
+
+	chunks := make([][]string, 0, (len(recipients)+size-1)/size)
+	for start := 0; start < len(recipients); start += size {
+		end := start + size
+		if end > len(recipients) {
+			end = len(recipients)
+		}
+
+		chunks = append(chunks, recipients[start:end])
+	}
+
+	return chunks
+}
@@ -0,0 +1,60 @@
+package msgsvc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/backend"
+	"github.com/yusufsyaifudin/ngendika/backend/befcm"
+	"github.com/yusufsyaifudin/ngendika/pkg/fcm"
+)
+
+func TestSplitMessages(t *testing.T) {
+	const provider = "fcm_split_messages_test"
+
+	fcmBackend, err := befcm.NewBE(nil)
+	assert.NoError(t, err)
+	// the mux is global, the provider may already registered by the previous run of -count
+	if err = backend.Register(provider, fcmBackend); err != nil {
+		assert.ErrorIs(t, err, backend.ErrProviderAlreadyRegistered)
+	}
+
+	recipients := make([]string, 0)
+	for i := 0; i < 1000; i++ {
+		recipients = append(recipients, fmt.Sprintf("token-%d", i))
+	}
+
+	payload := map[string]interface{}{
+		"tokens": []string{"payload-token-1", "payload-token-2", "token-0"}, // token-0 is also resolved recipient
+		"data":   map[string]string{"title": "hello"},
+	}
+
+	ctx := context.Background()
+	msgs, err := splitMessages(ctx, backend.MuxBackend(), provider, "task-1", payload, recipients)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 3)
+
+	seen := map[string]int{}
+	for _, msg := range msgs {
+		assert.LessOrEqual(t, len(msg.Recipients), befcm.MaxMulticastTokens)
+		assert.Equal(t, "task-1", msg.ReferenceID)
+		assert.NotContains(t, msg.RawPayload, "tokens")
+
+		for _, recipient := range msg.Recipients {
+			seen[recipient]++
+		}
+
+		// the payload without tokens is still valid, and the tokens is only taken from recipients
+		validMsg, err := fcmBackend.ValidateMsg(ctx, msg)
+		assert.NoError(t, err)
+		assert.IsType(t, fcm.MulticastMessage{}, validMsg)
+		assert.Len(t, validMsg.(fcm.MulticastMessage).Tokens, len(msg.Recipients))
+	}
+
+	assert.Len(t, seen, 1002)
+	for recipient, count := range seen {
+		assert.Equal(t, 1, count, recipient)
+	}
+}
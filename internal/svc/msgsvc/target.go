@@ -0,0 +1,28 @@
+package msgsvc
+
+import (
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+	"strings"
+)
+
+const userPrefix = "user:"
+
+// ParseTargets split target with prefix topic: or user: into topics or user ids.
+func ParseTargets(userIDs []string, targets []string) (outUserIDs, topics []string, err error) {
+	outUserIDs = append(outUserIDs, userIDs...)
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		switch {
+		case strings.HasPrefix(target, topicsvc.TopicPrefix):
+			topics = append(topics, strings.TrimPrefix(target, topicsvc.TopicPrefix))
+		case strings.HasPrefix(target, userPrefix):
+			outUserIDs = append(outUserIDs, strings.TrimPrefix(target, userPrefix))
+		default:
+			err = fmt.Errorf("unknown target '%s', must be prefixed with '%s' or '%s'", target, topicsvc.TopicPrefix, userPrefix)
+			return
+		}
+	}
+
+	return
+}
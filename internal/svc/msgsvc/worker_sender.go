@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/yusufsyaifudin/ngendika/backend"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -30,16 +31,110 @@ type senderWorkerJob struct {
 	Wg   *sync.WaitGroup
 
 	SubmitTime      time.Time
+	ClientID        string // app client id, used as metric label
 	ServiceProvider backend.PushNotificationProvider
 	Message         *backend.Message
 
 	// Report must be pointer so we can append slice and read from the caller function.
 	Report *senderWorkerJobReport
+
+	// Progress is drained by one go routine which call InputProcess.OnProgress, nil when OnProgress is not defined.
+	Progress *progressQueue
+}
+
+// addReport append the report under the job provider, then push the progress after the lock is released.
+// Pushing the progress never blocks, so slow OnProgress (i.e: slow stream client) never holds the sender worker.
+func (job senderWorkerJob) addReport(report backendReport) {
+	job.Lock.Lock()
+	pnpID := job.ServiceProvider.ID
+	job.Report.BackendReports[pnpID] = append(job.Report.BackendReports[pnpID], report)
+	job.Lock.Unlock()
+
+	job.Progress.push(Progress{
+		PNP:           job.ServiceProvider,
+		BackendError:  report.BackendError,
+		BackendReport: report.BackendReport,
+	})
+}
+
+// progressQueue is unbounded queue of one Process call. It is bounded by the number of messages of the call,
+// so it is safe to never push back to the shared sender workers.
+type progressQueue struct {
+	mu     sync.Mutex
+	items  []Progress
+	closed bool
+	notify chan struct{}
+}
+
+// push add the progress into the queue without blocking, nil queue is no-op.
+func (q *progressQueue) push(p Progress) {
+	if q == nil {
+		return
+	}
+
+	q.mu.Lock()
+	q.items = append(q.items, p)
+	q.mu.Unlock()
+	q.wake()
+}
+
+func (q *progressQueue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take return all queued progress and whether the queue is closed.
+func (q *progressQueue) take() (items []Progress, closed bool) {
+	q.mu.Lock()
+	items, q.items = q.items, nil
+	closed = q.closed
+	q.mu.Unlock()
+	return
+}
+
+// startProgress return the queue of senderWorkerJob.Progress, each progress is passed to onProgress serially.
+// stop must be called after all jobs are done, it waits until all progress is passed.
+func startProgress(onProgress func(progress Progress)) (progress *progressQueue, stop func()) {
+	if onProgress == nil {
+		return nil, func() {}
+	}
+
+	q := &progressQueue{notify: make(chan struct{}, 1)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range q.notify {
+			items, closed := q.take()
+			for _, p := range items {
+				onProgress(p)
+			}
+
+			if closed {
+				return
+			}
+		}
+	}()
+
+	return q, func() {
+		q.mu.Lock()
+		q.closed = true
+		q.mu.Unlock()
+		q.wake()
+		<-done
+	}
 }
 
 func senderWorker(workerID int, sender backend.SenderMux, jobs <-chan senderWorkerJob) {
 	for job := range jobs {
+		metric.QueueDepth.Set(float64(len(jobs)))
+
 		pnpID := job.ServiceProvider.ID
+		metricLabels := []string{job.ClientID, job.ServiceProvider.Provider, job.ServiceProvider.Label}
+		// count per recipient, the same unit as success and failure reported by the backend
+		recipients := float64(recipientCount(job.Message))
+		metric.MessagesSubmitted.WithLabelValues(metricLabels...).Add(recipients)
 
 		job.Lock.Lock()
 		if job.Report.BackendReports[pnpID] == nil {
@@ -49,29 +144,21 @@ func senderWorker(workerID int, sender backend.SenderMux, jobs <-chan senderWork
 		job.Lock.Unlock()
 
 		if job.Ctx == nil {
-			job.Lock.Lock()
+			metric.MessagesFailure.WithLabelValues(metricLabels...).Add(recipients)
+			job.addReport(backendReport{
+				BackendError: "no context passed in send fcm msg multicast job!",
+			})
 
-			job.Report.BackendReports[pnpID] = append(
-				job.Report.BackendReports[pnpID],
-				backendReport{
-					BackendError: "no context passed in send fcm msg multicast job!",
-				},
-			)
-
-			job.Lock.Unlock()
 			job.Wg.Done()
 			continue
 		}
 
 		if deadline, _ := job.Ctx.Deadline(); deadline.IsZero() {
-			job.Lock.Lock()
-			job.Report.BackendReports[pnpID] = append(
-				job.Report.BackendReports[pnpID],
-				backendReport{
-					BackendError: "deadline context not defined in send fcm msg multicast job!",
-				},
-			)
-			job.Lock.Unlock()
+			metric.MessagesFailure.WithLabelValues(metricLabels...).Add(recipients)
+			job.addReport(backendReport{
+				BackendError: "deadline context not defined in send fcm msg multicast job!",
+			})
+
 			job.Wg.Done()
 			continue
 		}
@@ -85,35 +172,36 @@ func senderWorker(workerID int, sender backend.SenderMux, jobs <-chan senderWork
 			attribute.String("pnp_label", job.ServiceProvider.Label),
 		)
 
+		metric.WorkersBusy.Inc()
+		sendStart := time.Now()
 		report, err := sender.Send(ctx, workerID, job.ServiceProvider, job.Message)
-		if err != nil {
-			job.Lock.Lock()
+		sendDuration := time.Since(sendStart).Seconds()
+		metric.WorkersBusy.Dec()
 
-			job.Report.BackendReports[pnpID] = append(
-				job.Report.BackendReports[pnpID],
-				backendReport{
-					BackendError: fmt.Sprintf("error occured during send msg id '%s': %s", job.Message.ReferenceID, err),
-				},
-			)
+		if err != nil {
+			metric.BackendSendDuration.WithLabelValues(job.ServiceProvider.Provider, "error").Observe(sendDuration)
+			metric.MessagesFailure.WithLabelValues(metricLabels...).Add(recipients)
 
-			job.Lock.Unlock()
+			job.addReport(backendReport{
+				BackendError: fmt.Sprintf("error occured during send msg id '%s': %s", job.Message.ReferenceID, err),
+			})
 
 			span.End()
 			job.Wg.Done()
 			continue
 		}
 
-		job.Lock.Lock()
+		metric.BackendSendDuration.WithLabelValues(job.ServiceProvider.Provider, "ok").Observe(sendDuration)
 		if report != nil {
-			job.Report.BackendReports[pnpID] = append(
-				job.Report.BackendReports[pnpID],
-				backendReport{
-					BackendReport: report,
-				},
-			)
+			metric.MessagesSuccess.WithLabelValues(metricLabels...).Add(float64(report.SuccessCount))
+			metric.MessagesFailure.WithLabelValues(metricLabels...).Add(float64(report.FailureCount))
 		}
 
-		job.Lock.Unlock()
+		if report != nil {
+			job.addReport(backendReport{
+				BackendReport: report,
+			})
+		}
 
 		span.End()
 		job.Wg.Done()
@@ -121,3 +209,13 @@ func senderWorker(workerID int, sender backend.SenderMux, jobs <-chan senderWork
 	}
 
 }
+
+// recipientCount return the number of recipients of the message,
+// message without Recipients is counted as one because the recipient is defined in the payload.
+func recipientCount(msg *backend.Message) int {
+	if msg == nil || len(msg.Recipients) <= 0 {
+		return 1
+	}
+
+	return len(msg.Recipients)
+}
@@ -7,26 +7,36 @@ import (
 
 var (
 	ErrValidation = errors.New("validation error")
+
+	// ErrActiveProvider is returned when purging app which still has not deleted provider.
+	ErrActiveProvider = errors.New("app still has active push notification provider")
 )
 
 type Repo interface {
 	Insert(ctx context.Context, in InputInsert) (out OutInsert, err error)
 	GetByLabels(ctx context.Context, in InGetByLabels) (out OutGetByLabels, err error)
+	DelByAppID(ctx context.Context, in InDelByAppID) (out OutDelByAppID, err error)
+	RestoreByAppID(ctx context.Context, in InRestoreByAppID) (out OutRestoreByAppID, err error)
+	PurgeByAppID(ctx context.Context, in InPurgeByAppID) (out OutPurgeByAppID, err error)
 }
 
 // PushNotificationProvider is resembles the table structure.
 // We separate the table model into service model (entity that be use for entire app)
 // to scoping the database entity to HTTP (request/response) entity.
+// Json tag is used for caching.
 type PushNotificationProvider struct {
-	ID             int64  `db:"id" validate:"required"`
-	AppID          int64  `db:"app_id" validate:"required"`
-	Provider       string `db:"provider" validate:"required"`
-	Label          string `db:"label" validate:"required"`
-	CredentialJSON string `db:"credential_json" validate:"required"`
+	ID             int64  `json:"id" db:"id" validate:"required"`
+	AppID          int64  `json:"app_id" db:"app_id" validate:"required"`
+	Provider       string `json:"provider" db:"provider" validate:"required"`
+	Label          string `json:"label" db:"label" validate:"required"`
+	CredentialJSON string `json:"credential_json" db:"credential_json" validate:"required"`
 
 	// Timestamp using integer as unix microsecond in UTC
-	CreatedAt int64 `db:"created_at" validate:"required"`
-	UpdatedAt int64 `db:"updated_at" validate:"required"`
+	CreatedAt int64 `json:"created_at" db:"created_at" validate:"required"`
+	UpdatedAt int64 `json:"updated_at" db:"updated_at" validate:"required"`
+
+	// DeletedAt is 0 when not deleted, deleted provider is never returned by GetByLabels
+	DeletedAt int64 `json:"deleted_at" db:"deleted_at"`
 }
 
 type InputInsert struct {
@@ -46,3 +56,35 @@ type InGetByLabels struct {
 type OutGetByLabels struct {
 	PnProvider []PushNotificationProvider
 }
+
+type InDelByAppID struct {
+	AppID     int64 `validate:"required"`
+	DeletedAt int64 `validate:"required"`
+}
+
+type OutDelByAppID struct {
+	PnProvider []PushNotificationProvider
+}
+
+// InRestoreByAppID only restore the providers that deleted at DeletedAt,
+// so providers deleted at other time is not restored.
+type InRestoreByAppID struct {
+	AppID     int64 `validate:"required"`
+	DeletedAt int64 `validate:"required"`
+	UpdatedAt int64 `validate:"required"`
+}
+
+type OutRestoreByAppID struct {
+	PnProvider []PushNotificationProvider
+}
+
+// InPurgeByAppID permanently remove the deleted providers of the app.
+// When Archive is true, the Postgres partition is only detached, and other database keep the deleted rows.
+type InPurgeByAppID struct {
+	AppID   int64 `validate:"required"`
+	Archive bool  `validate:"-"`
+}
+
+type OutPurgeByAppID struct {
+	Archived bool
+}
@@ -0,0 +1,155 @@
+package pnprepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ylog"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type CachedConfig struct {
+	Persistent     Repo          `validate:"required"`
+	CacheExpiry    time.Duration `validate:"required"`
+	CachePrefixKey string        `validate:"required,alphanum"`
+	Cache          cache.Cache   `validate:"required"`
+}
+
+// CachedRepo cache each push notification provider by (app_id, provider, label).
+// Every write must evict the key instead of overwrite it, so when Cache is cache.Broadcast
+// the eviction is also published to the other nodes.
+type CachedRepo struct {
+	Config CachedConfig
+}
+
+var _ Repo = (*CachedRepo)(nil)
+
+func NewCached(cfg CachedConfig) (*CachedRepo, error) {
+	if err := validator.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	return &CachedRepo{
+		Config: cfg,
+	}, nil
+}
+
+func (c *CachedRepo) Insert(ctx context.Context, in InputInsert) (out OutInsert, err error) {
+	out, err = c.Config.Persistent.Insert(ctx, in)
+	if err != nil {
+		return
+	}
+
+	c.evict(ctx, out.PnProvider.AppID, out.PnProvider.Provider, out.PnProvider.Label)
+	return
+}
+
+// GetByLabels only query the labels that not exist in cache to the persistent storage.
+// Label that not exist in persistent storage is not cached.
+func (c *CachedRepo) GetByLabels(ctx context.Context, in InGetByLabels) (out OutGetByLabels, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "pnprepo.CachedRepo.GetByLabels")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	pnProviders := make([]PushNotificationProvider, 0, len(in.Labels))
+	missLabels := make([]string, 0)
+	for _, label := range in.Labels {
+		var pnProvider PushNotificationProvider
+		_err := c.Config.Cache.GetAs(ctx, c.genCacheKey(in.AppID, in.Provider, label), &pnProvider)
+		if _err == nil && pnProvider.Label == label {
+			metric.CacheRequests.WithLabelValues(c.Config.CachePrefixKey, metric.CacheHit).Inc()
+			pnProviders = append(pnProviders, pnProvider)
+			continue
+		}
+
+		metric.CacheRequests.WithLabelValues(c.Config.CachePrefixKey, metric.CacheMiss).Inc()
+		missLabels = append(missLabels, label)
+	}
+
+	if len(missLabels) <= 0 {
+		out = OutGetByLabels{PnProvider: pnProviders}
+		return
+	}
+
+	outPersistent, err := c.Config.Persistent.GetByLabels(ctx, InGetByLabels{
+		AppID:    in.AppID,
+		Provider: in.Provider,
+		Labels:   missLabels,
+	})
+	if err != nil {
+		err = fmt.Errorf("persistence storage fetch error: %w", err)
+		return
+	}
+
+	for _, pnProvider := range outPersistent.PnProvider {
+		pnProviders = append(pnProviders, pnProvider)
+
+		// Try cache, only log when error
+		key := c.genCacheKey(pnProvider.AppID, pnProvider.Provider, pnProvider.Label)
+		if _err := c.Config.Cache.SetExp(ctx, key, pnProvider, c.Config.CacheExpiry); _err != nil {
+			ylog.Error(ctx, fmt.Sprintf("cannot save cache pnp %s", key), ylog.KV("error", _err))
+		}
+	}
+
+	out = OutGetByLabels{
+		PnProvider: pnProviders,
+	}
+
+	return
+}
+
+// DelByAppID evict every deleted provider, so message in flight cannot resolve the credential from cache.
+func (c *CachedRepo) DelByAppID(ctx context.Context, in InDelByAppID) (out OutDelByAppID, err error) {
+	out, err = c.Config.Persistent.DelByAppID(ctx, in)
+	if err != nil {
+		return
+	}
+
+	for _, pnProvider := range out.PnProvider {
+		c.evict(ctx, pnProvider.AppID, pnProvider.Provider, pnProvider.Label)
+	}
+
+	return
+}
+
+func (c *CachedRepo) RestoreByAppID(ctx context.Context, in InRestoreByAppID) (out OutRestoreByAppID, err error) {
+	out, err = c.Config.Persistent.RestoreByAppID(ctx, in)
+	if err != nil {
+		return
+	}
+
+	for _, pnProvider := range out.PnProvider {
+		c.evict(ctx, pnProvider.AppID, pnProvider.Provider, pnProvider.Label)
+	}
+
+	return
+}
+
+// PurgeByAppID is not using cache, because deleted provider is already evicted.
+func (c *CachedRepo) PurgeByAppID(ctx context.Context, in InPurgeByAppID) (out OutPurgeByAppID, err error) {
+	return c.Config.Persistent.PurgeByAppID(ctx, in)
+}
+
+// -- cache
+
+func (c *CachedRepo) genCacheKey(appID int64, provider, label string) string {
+	return fmt.Sprintf("%s:%d:%s:%s", c.Config.CachePrefixKey, appID, provider, label)
+}
+
+// evict only log the error, since the data is already persisted.
+func (c *CachedRepo) evict(ctx context.Context, appID int64, provider, label string) {
+	key := c.genCacheKey(appID, provider, label)
+	if err := c.Config.Cache.Delete(ctx, key); err != nil {
+		ylog.Error(ctx, fmt.Sprintf("cannot evict cache pnp %s", key), ylog.KV("error", err))
+	}
+}
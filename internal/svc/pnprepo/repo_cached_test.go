@@ -0,0 +1,112 @@
+package pnprepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+)
+
+// persistentStub count the labels queried to the persistent storage.
+type persistentStub struct {
+	rows    []pnprepo.PushNotificationProvider
+	queried [][]string
+}
+
+func (p *persistentStub) Insert(_ context.Context, in pnprepo.InputInsert) (out pnprepo.OutInsert, err error) {
+	p.rows = append(p.rows, in.PnProvider)
+	out = pnprepo.OutInsert{PnProvider: in.PnProvider}
+	return
+}
+
+func (p *persistentStub) GetByLabels(_ context.Context, in pnprepo.InGetByLabels) (out pnprepo.OutGetByLabels, err error) {
+	p.queried = append(p.queried, in.Labels)
+	for _, row := range p.rows {
+		for _, label := range in.Labels {
+			if row.AppID == in.AppID && row.Provider == in.Provider && row.Label == label {
+				out.PnProvider = append(out.PnProvider, row)
+			}
+		}
+	}
+
+	return
+}
+
+func (p *persistentStub) DelByAppID(_ context.Context, in pnprepo.InDelByAppID) (out pnprepo.OutDelByAppID, err error) {
+	rows := make([]pnprepo.PushNotificationProvider, 0)
+	for _, row := range p.rows {
+		if row.AppID == in.AppID {
+			row.DeletedAt = in.DeletedAt
+			out.PnProvider = append(out.PnProvider, row)
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	p.rows = rows
+	return
+}
+
+func (p *persistentStub) RestoreByAppID(_ context.Context, _ pnprepo.InRestoreByAppID) (out pnprepo.OutRestoreByAppID, err error) {
+	return
+}
+
+func (p *persistentStub) PurgeByAppID(_ context.Context, _ pnprepo.InPurgeByAppID) (out pnprepo.OutPurgeByAppID, err error) {
+	return
+}
+
+func TestCachedRepo_GetByLabels(t *testing.T) {
+	ctx := context.Background()
+	persistent := &persistentStub{}
+
+	local, err := cache.NewInMemory()
+	assert.NoError(t, err)
+
+	repo, err := pnprepo.NewCached(pnprepo.CachedConfig{
+		Persistent:     persistent,
+		CacheExpiry:    time.Minute,
+		CachePrefixKey: "pnp",
+		Cache:          local,
+	})
+	assert.NoError(t, err)
+
+	for _, label := range []string{"a", "b"} {
+		_, err = repo.Insert(ctx, pnprepo.InputInsert{PnProvider: pnprepo.PushNotificationProvider{
+			ID: 1, AppID: 1, Provider: "fcm", Label: label, CredentialJSON: "{}", CreatedAt: 1, UpdatedAt: 1,
+		}})
+		assert.NoError(t, err)
+	}
+
+	in := pnprepo.InGetByLabels{AppID: 1, Provider: "fcm", Labels: []string{"a"}}
+	out, err := repo.GetByLabels(ctx, in)
+	assert.NoError(t, err)
+	assert.Len(t, out.PnProvider, 1)
+
+	// label a is cached, only b and c (not exist) is queried
+	in.Labels = []string{"a", "b", "c"}
+	out, err = repo.GetByLabels(ctx, in)
+	assert.NoError(t, err)
+	assert.Len(t, out.PnProvider, 2)
+	assert.Equal(t, [][]string{{"a"}, {"b", "c"}}, persistent.queried)
+
+	// insert evict the key, so it is queried again
+	_, err = repo.Insert(ctx, pnprepo.InputInsert{PnProvider: persistent.rows[0]})
+	assert.NoError(t, err)
+
+	in.Labels = []string{"a", "b"}
+	_, err = repo.GetByLabels(ctx, in)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, persistent.queried[2])
+
+	// deleted providers is evicted, so it is not resolved from cache
+	_, err = repo.DelByAppID(ctx, pnprepo.InDelByAppID{AppID: 1, DeletedAt: 2})
+	assert.NoError(t, err)
+
+	out, err = repo.GetByLabels(ctx, in)
+	assert.NoError(t, err)
+	assert.Len(t, out.PnProvider, 0)
+}
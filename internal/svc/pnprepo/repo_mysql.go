@@ -0,0 +1,233 @@
+package pnprepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/multidb"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SqlMysqlInsert = `
+INSERT INTO push_providers (id, app_id, provider, label, credential_json, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+`
+
+	SqlMysqlGetByID = `SELECT * FROM push_providers WHERE id = ? AND app_id = ? LIMIT 1;`
+
+	// MySQL does not support RETURNING, so the affected rows is selected before update in the same transaction
+	SqlMysqlGetByAppIDDeletedAt = `SELECT * FROM push_providers WHERE app_id = ? AND deleted_at = ? FOR UPDATE;`
+	SqlMysqlDelByAppID          = `UPDATE push_providers SET deleted_at = ? WHERE app_id = ? AND deleted_at = 0;`
+	SqlMysqlRestoreByAppID      = `UPDATE push_providers SET deleted_at = 0, updated_at = ? WHERE app_id = ? AND deleted_at = ?;`
+	SqlMysqlCountActive         = `SELECT COUNT(*) AS total FROM push_providers WHERE app_id = ? AND deleted_at = 0;`
+	SqlMysqlPurgeByAppID        = `DELETE FROM push_providers WHERE app_id = ? AND deleted_at <> 0;`
+)
+
+type MySQLConfig struct {
+	Connection sqlx.ExtContext     `validate:"required"`
+	Reader     sqlx.QueryerContext `validate:"-"` // for read only query, default to Connection
+}
+
+// MySQL does not partition the table per app like Postgres,
+// the query by app_id is using index (app_id, provider, label).
+type MySQL struct {
+	Config MySQLConfig
+}
+
+var _ Repo = (*MySQL)(nil)
+
+func NewMySQL(cfg MySQLConfig) (repo *MySQL, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	if cfg.Reader == nil {
+		cfg.Reader = cfg.Connection
+	}
+
+	repo = &MySQL{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (p *MySQL) Insert(ctx context.Context, in InputInsert) (out OutInsert, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	args := []interface{}{
+		in.PnProvider.ID,
+		in.PnProvider.AppID,
+		in.PnProvider.Provider,
+		in.PnProvider.Label,
+		in.PnProvider.CredentialJSON,
+		in.PnProvider.CreatedAt,
+		in.PnProvider.UpdatedAt,
+	}
+
+	_, err = p.Config.Connection.ExecContext(ctx, SqlMysqlInsert, args...)
+	if err != nil {
+		err = fmt.Errorf("insert db error: %w", err)
+		return
+	}
+
+	var svcProvider PushNotificationProvider
+	err = sqlx.GetContext(ctx, p.Config.Connection, &svcProvider, SqlMysqlGetByID, in.PnProvider.ID, in.PnProvider.AppID)
+	if err != nil {
+		err = fmt.Errorf("get inserted db error: %w", err)
+		return
+	}
+
+	out = OutInsert{
+		PnProvider: svcProvider,
+	}
+
+	return
+}
+
+func (p *MySQL) GetByLabels(ctx context.Context, in InGetByLabels) (out OutGetByLabels, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "pnprepo.GetByLabels")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	// MySQL is using question mark, so no need to rebind
+	query, args, err := sqlx.In(SqlGetByLabels, in.AppID, in.Provider, in.Labels)
+	if err != nil {
+		err = fmt.Errorf("cannot generate sql query: %w", err)
+		return
+	}
+
+	var svcProviders []PushNotificationProvider
+	err = sqlx.SelectContext(ctx, p.Config.Reader, &svcProviders, query, args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get config by labels: %w", err)
+		return
+	}
+
+	out = OutGetByLabels{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+func (p *MySQL) DelByAppID(ctx context.Context, in InDelByAppID) (out OutDelByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svcProviders := make([]PushNotificationProvider, 0)
+	err = multidb.InTx(ctx, p.Config.Connection, func(conn sqlx.ExtContext) error {
+		if _err := sqlx.SelectContext(ctx, conn, &svcProviders, SqlMysqlGetByAppIDDeletedAt, in.AppID, 0); _err != nil {
+			return fmt.Errorf("cannot get providers of app id '%d': %w", in.AppID, _err)
+		}
+
+		if _, _err := conn.ExecContext(ctx, SqlMysqlDelByAppID, in.DeletedAt, in.AppID); _err != nil {
+			return fmt.Errorf("cannot delete providers of app id '%d': %w", in.AppID, _err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	for i := range svcProviders {
+		svcProviders[i].DeletedAt = in.DeletedAt
+	}
+
+	out = OutDelByAppID{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+func (p *MySQL) RestoreByAppID(ctx context.Context, in InRestoreByAppID) (out OutRestoreByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svcProviders := make([]PushNotificationProvider, 0)
+	err = multidb.InTx(ctx, p.Config.Connection, func(conn sqlx.ExtContext) error {
+		if _err := sqlx.SelectContext(ctx, conn, &svcProviders, SqlMysqlGetByAppIDDeletedAt, in.AppID, in.DeletedAt); _err != nil {
+			return fmt.Errorf("cannot get deleted providers of app id '%d': %w", in.AppID, _err)
+		}
+
+		if _, _err := conn.ExecContext(ctx, SqlMysqlRestoreByAppID, in.UpdatedAt, in.AppID, in.DeletedAt); _err != nil {
+			return fmt.Errorf("cannot restore providers of app id '%d': %w", in.AppID, _err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	for i := range svcProviders {
+		svcProviders[i].DeletedAt = 0
+		svcProviders[i].UpdatedAt = in.UpdatedAt
+	}
+
+	out = OutRestoreByAppID{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+// PurgeByAppID delete the deleted rows of the app, unless in.Archive is true.
+func (p *MySQL) PurgeByAppID(ctx context.Context, in InPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	count := struct {
+		Total int64 `db:"total"`
+	}{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &count, SqlMysqlCountActive, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot count active providers of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	if count.Total > 0 {
+		err = fmt.Errorf("%w: app id '%d' has %d providers", ErrActiveProvider, in.AppID, count.Total)
+		return
+	}
+
+	if !in.Archive {
+		_, err = p.Config.Connection.ExecContext(ctx, SqlMysqlPurgeByAppID, in.AppID)
+		if err != nil {
+			err = fmt.Errorf("cannot purge providers of app id '%d': %w", in.AppID, err)
+			return
+		}
+	}
+
+	out = OutPurgeByAppID{
+		Archived: in.Archive,
+	}
+
+	return
+}
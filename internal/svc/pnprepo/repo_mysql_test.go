@@ -0,0 +1,69 @@
+package pnprepo_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+)
+
+// newMySQL return new database in the MySQL 8 server of MYSQL_TEST_DSN, i.e: root:mysql@tcp(localhost:3306)/
+// migrated using the embedded mysql migrations. The test is skipped when MYSQL_TEST_DSN is not defined.
+func newMySQL(t *testing.T) *sqlx.DB {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not defined")
+	}
+
+	ctx := context.Background()
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_TEST_DSN: %s", err)
+	}
+
+	server, err := sqlx.Open("mysql", cfg.FormatDSN())
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	cfg.DBName = fmt.Sprintf("ngendika_pnprepo_%d", time.Now().UnixNano())
+	_, err = server.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s;", cfg.DBName))
+	if err != nil {
+		t.Fatalf("cannot create test database: %s", err)
+	}
+
+	t.Cleanup(func() { _, _ = server.ExecContext(ctx, fmt.Sprintf("DROP DATABASE %s;", cfg.DBName)) })
+
+	db, err := sqlx.Open("mysql", cfg.FormatDSN())
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := migration.Load(assets.Migrations, "migrations/mysql/push_providers_repo")
+	assert.NoError(t, err)
+
+	migrator, err := migration.New(migration.Config{
+		DB:         db,
+		Dialect:    "mysql",
+		Service:    "serviceProvider",
+		Migrations: migrations,
+	})
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestMySQL(t *testing.T) {
+	repo, err := pnprepo.NewMySQL(pnprepo.MySQLConfig{Connection: newMySQL(t)})
+	assert.NoError(t, err)
+
+	testRepo(t, repo)
+}
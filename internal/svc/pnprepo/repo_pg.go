@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/multidb"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"go.opentelemetry.io/otel/trace"
@@ -17,18 +18,38 @@ RETURNING *;
 `
 
 	// SqlGetByLabels use with sqlx.In so it mush using quote rather than dollar
-	SqlGetByLabels = `SELECT * FROM push_providers WHERE app_id = ? AND provider = ? AND label IN (?);`
+	SqlGetByLabels = `SELECT * FROM push_providers WHERE app_id = ? AND provider = ? AND label IN (?) AND deleted_at = 0;`
+
+	SqlDelByAppID     = `UPDATE push_providers SET deleted_at = $1 WHERE app_id = $2 AND deleted_at = 0 RETURNING *;`
+	SqlRestoreByAppID = `UPDATE push_providers SET deleted_at = 0, updated_at = $1 WHERE app_id = $2 AND deleted_at = $3 RETURNING *;`
+	SqlCountActive    = `SELECT COUNT(*) AS total FROM push_providers WHERE app_id = $1 AND deleted_at = 0;`
+
+	// SqlPartitionAttached return true when the partition is still part of push_providers table
+	SqlPartitionAttached = `SELECT EXISTS (SELECT 1 FROM pg_inherits WHERE inhrelid = to_regclass($1) AND inhparent = 'push_providers'::regclass);`
 )
 
+func PartitionName(id int64) string {
+	return fmt.Sprintf("push_provider_app_%d", id)
+}
+
 func CreatePartitionSQL(id int64) string {
 	return fmt.Sprintf(
-		"CREATE TABLE IF NOT EXISTS push_provider_app_%d PARTITION OF push_providers FOR VALUES IN (%d);",
-		id, id,
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF push_providers FOR VALUES IN (%d);",
+		PartitionName(id), id,
 	)
 }
 
+func DetachPartitionSQL(id int64) string {
+	return fmt.Sprintf("ALTER TABLE push_providers DETACH PARTITION %s;", PartitionName(id))
+}
+
+func DropPartitionSQL(id int64) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", PartitionName(id))
+}
+
 type PostgresConfig struct {
-	Connection sqlx.QueryerContext `validate:"required"`
+	Connection sqlx.ExtContext     `validate:"required"`
+	Reader     sqlx.QueryerContext `validate:"-"` // for read only query, default to Connection
 }
 
 type Postgres struct {
@@ -44,6 +65,10 @@ func NewPostgres(cfg PostgresConfig) (repo *Postgres, err error) {
 		return
 	}
 
+	if cfg.Reader == nil {
+		cfg.Reader = cfg.Connection
+	}
+
 	repo = &Postgres{
 		Config: cfg,
 	}
@@ -58,13 +83,6 @@ func (p *Postgres) Insert(ctx context.Context, in InputInsert) (out OutInsert, e
 		return
 	}
 
-	sqlCreatePartition := CreatePartitionSQL(in.PnProvider.AppID)
-	_, err = p.Config.Connection.QueryContext(ctx, sqlCreatePartition)
-	if err != nil {
-		err = fmt.Errorf("cannot create partition for app id '%d' error: %w", in.PnProvider.AppID, err)
-		return
-	}
-
 	args := []interface{}{
 		in.PnProvider.ID,
 		in.PnProvider.AppID,
@@ -75,10 +93,21 @@ func (p *Postgres) Insert(ctx context.Context, in InputInsert) (out OutInsert, e
 		in.PnProvider.UpdatedAt,
 	}
 
+	// partition is created in the same transaction, so it is rolled back when the insert failed
 	var svcProvider PushNotificationProvider
-	err = sqlx.GetContext(ctx, p.Config.Connection, &svcProvider, SqlInsert, args...)
+	err = multidb.InTx(ctx, p.Config.Connection, func(conn sqlx.ExtContext) error {
+		sqlCreatePartition := CreatePartitionSQL(in.PnProvider.AppID)
+		if _, _err := conn.ExecContext(ctx, sqlCreatePartition); _err != nil {
+			return fmt.Errorf("cannot create partition for app id '%d' error: %w", in.PnProvider.AppID, _err)
+		}
+
+		if _err := sqlx.GetContext(ctx, conn, &svcProvider, SqlInsert, args...); _err != nil {
+			return fmt.Errorf("insert db error: %w", _err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		err = fmt.Errorf("insert db error: %w", err)
 		return
 	}
 
@@ -110,7 +139,7 @@ func (p *Postgres) GetByLabels(ctx context.Context, in InGetByLabels) (out OutGe
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
 
 	var svcProviders []PushNotificationProvider
-	err = sqlx.SelectContext(ctx, p.Config.Connection, &svcProviders, query, args...)
+	err = sqlx.SelectContext(ctx, p.Config.Reader, &svcProviders, query, args...)
 	if err != nil {
 		err = fmt.Errorf("cannot get config by labels: %w", err)
 		return
@@ -122,3 +151,98 @@ func (p *Postgres) GetByLabels(ctx context.Context, in InGetByLabels) (out OutGe
 
 	return
 }
+
+func (p *Postgres) DelByAppID(ctx context.Context, in InDelByAppID) (out OutDelByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svcProviders := make([]PushNotificationProvider, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &svcProviders, SqlDelByAppID, in.DeletedAt, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot delete providers of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutDelByAppID{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+func (p *Postgres) RestoreByAppID(ctx context.Context, in InRestoreByAppID) (out OutRestoreByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svcProviders := make([]PushNotificationProvider, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &svcProviders, SqlRestoreByAppID, in.UpdatedAt, in.AppID, in.DeletedAt)
+	if err != nil {
+		err = fmt.Errorf("cannot restore providers of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutRestoreByAppID{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+// PurgeByAppID detach the app partition, then drop it unless in.Archive is true.
+// Detaching partition lock the whole push_providers table, so it must only be called by background job.
+func (p *Postgres) PurgeByAppID(ctx context.Context, in InPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	err = multidb.InTx(ctx, p.Config.Connection, func(conn sqlx.ExtContext) error {
+		count := struct {
+			Total int64 `db:"total"`
+		}{}
+		if _err := sqlx.GetContext(ctx, conn, &count, SqlCountActive, in.AppID); _err != nil {
+			return fmt.Errorf("cannot count active providers of app id '%d': %w", in.AppID, _err)
+		}
+
+		if count.Total > 0 {
+			return fmt.Errorf("%w: app id '%d' has %d providers", ErrActiveProvider, in.AppID, count.Total)
+		}
+
+		var attached bool
+		if _err := sqlx.GetContext(ctx, conn, &attached, SqlPartitionAttached, PartitionName(in.AppID)); _err != nil {
+			return fmt.Errorf("cannot check partition of app id '%d': %w", in.AppID, _err)
+		}
+
+		if attached {
+			if _, _err := conn.ExecContext(ctx, DetachPartitionSQL(in.AppID)); _err != nil {
+				return fmt.Errorf("cannot detach partition of app id '%d': %w", in.AppID, _err)
+			}
+		}
+
+		if in.Archive {
+			return nil
+		}
+
+		if _, _err := conn.ExecContext(ctx, DropPartitionSQL(in.AppID)); _err != nil {
+			return fmt.Errorf("cannot drop partition of app id '%d': %w", in.AppID, _err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	out = OutPurgeByAppID{
+		Archived: in.Archive,
+	}
+
+	return
+}
@@ -0,0 +1,191 @@
+package pnprepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SqlSqliteInsert = `
+INSERT INTO push_providers (id, app_id, provider, label, credential_json, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+`
+
+	SqlSqliteDelByAppID     = `UPDATE push_providers SET deleted_at = ? WHERE app_id = ? AND deleted_at = 0 RETURNING *;`
+	SqlSqliteRestoreByAppID = `UPDATE push_providers SET deleted_at = 0, updated_at = ? WHERE app_id = ? AND deleted_at = ? RETURNING *;`
+	SqlSqliteCountActive    = `SELECT COUNT(*) AS total FROM push_providers WHERE app_id = ? AND deleted_at = 0;`
+	SqlSqlitePurgeByAppID   = `DELETE FROM push_providers WHERE app_id = ? AND deleted_at <> 0;`
+)
+
+type SQLiteConfig struct {
+	Connection sqlx.ExtContext `validate:"required"`
+}
+
+// SQLite has no table partition like Postgres,
+// the query by app_id is using index (app_id, provider, label).
+type SQLite struct {
+	Config SQLiteConfig
+}
+
+var _ Repo = (*SQLite)(nil)
+
+func NewSQLite(cfg SQLiteConfig) (repo *SQLite, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	repo = &SQLite{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (p *SQLite) Insert(ctx context.Context, in InputInsert) (out OutInsert, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	args := []interface{}{
+		in.PnProvider.ID,
+		in.PnProvider.AppID,
+		in.PnProvider.Provider,
+		in.PnProvider.Label,
+		in.PnProvider.CredentialJSON,
+		in.PnProvider.CreatedAt,
+		in.PnProvider.UpdatedAt,
+	}
+
+	var svcProvider PushNotificationProvider
+	err = sqlx.GetContext(ctx, p.Config.Connection, &svcProvider, SqlSqliteInsert, args...)
+	if err != nil {
+		err = fmt.Errorf("insert db error: %w", err)
+		return
+	}
+
+	out = OutInsert{
+		PnProvider: svcProvider,
+	}
+
+	return
+}
+
+func (p *SQLite) GetByLabels(ctx context.Context, in InGetByLabels) (out OutGetByLabels, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "pnprepo.GetByLabels")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	// SQLite is using question mark, so no need to rebind
+	query, args, err := sqlx.In(SqlGetByLabels, in.AppID, in.Provider, in.Labels)
+	if err != nil {
+		err = fmt.Errorf("cannot generate sql query: %w", err)
+		return
+	}
+
+	var svcProviders []PushNotificationProvider
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &svcProviders, query, args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get config by labels: %w", err)
+		return
+	}
+
+	out = OutGetByLabels{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+func (p *SQLite) DelByAppID(ctx context.Context, in InDelByAppID) (out OutDelByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svcProviders := make([]PushNotificationProvider, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &svcProviders, SqlSqliteDelByAppID, in.DeletedAt, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot delete providers of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutDelByAppID{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+func (p *SQLite) RestoreByAppID(ctx context.Context, in InRestoreByAppID) (out OutRestoreByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svcProviders := make([]PushNotificationProvider, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &svcProviders, SqlSqliteRestoreByAppID, in.UpdatedAt, in.AppID, in.DeletedAt)
+	if err != nil {
+		err = fmt.Errorf("cannot restore providers of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	out = OutRestoreByAppID{
+		PnProvider: svcProviders,
+	}
+
+	return
+}
+
+// PurgeByAppID delete the deleted rows of the app, unless in.Archive is true.
+func (p *SQLite) PurgeByAppID(ctx context.Context, in InPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	count := struct {
+		Total int64 `db:"total"`
+	}{}
+	err = sqlx.GetContext(ctx, p.Config.Connection, &count, SqlSqliteCountActive, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot count active providers of app id '%d': %w", in.AppID, err)
+		return
+	}
+
+	if count.Total > 0 {
+		err = fmt.Errorf("%w: app id '%d' has %d providers", ErrActiveProvider, in.AppID, count.Total)
+		return
+	}
+
+	if !in.Archive {
+		_, err = p.Config.Connection.ExecContext(ctx, SqlSqlitePurgeByAppID, in.AppID)
+		if err != nil {
+			err = fmt.Errorf("cannot purge providers of app id '%d': %w", in.AppID, err)
+			return
+		}
+	}
+
+	out = OutPurgeByAppID{
+		Archived: in.Archive,
+	}
+
+	return
+}
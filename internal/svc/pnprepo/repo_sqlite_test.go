@@ -0,0 +1,105 @@
+package pnprepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newSQLite return in-memory database migrated using the embedded sqlite migrations.
+func newSQLite(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	// each connection of :memory: is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := migration.Load(assets.Migrations, "migrations/sqlite/push_providers_repo")
+	assert.NoError(t, err)
+
+	migrator, err := migration.New(migration.Config{
+		DB:         db,
+		Dialect:    "sqlite",
+		Service:    "serviceProvider",
+		Migrations: migrations,
+	})
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(context.Background(), 0)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestSQLite(t *testing.T) {
+	repo, err := pnprepo.NewSQLite(pnprepo.SQLiteConfig{Connection: newSQLite(t)})
+	assert.NoError(t, err)
+
+	testRepo(t, repo)
+}
+
+// testRepo is run against every database, the repo must be empty.
+func testRepo(t *testing.T, repo pnprepo.Repo) {
+	ctx := context.Background()
+
+	for i, label := range []string{"driver", "consumer"} {
+		out, err := repo.Insert(ctx, pnprepo.InputInsert{PnProvider: pnprepo.PushNotificationProvider{
+			ID:             int64(i + 1),
+			AppID:          1,
+			Provider:       "fcm",
+			Label:          label,
+			CredentialJSON: `{"project_id":"ngendika"}`,
+			CreatedAt:      1,
+			UpdatedAt:      1,
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, label, out.PnProvider.Label)
+		assert.Equal(t, `{"project_id":"ngendika"}`, out.PnProvider.CredentialJSON)
+	}
+
+	out, err := repo.GetByLabels(ctx, pnprepo.InGetByLabels{
+		AppID:    1,
+		Provider: "fcm",
+		Labels:   []string{"driver", "unknown"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, out.PnProvider, 1)
+	assert.Equal(t, "driver", out.PnProvider[0].Label)
+
+	deleted, err := repo.DelByAppID(ctx, pnprepo.InDelByAppID{AppID: 1, DeletedAt: 2})
+	assert.NoError(t, err)
+	assert.Len(t, deleted.PnProvider, 2)
+
+	out, err = repo.GetByLabels(ctx, pnprepo.InGetByLabels{AppID: 1, Provider: "fcm", Labels: []string{"driver"}})
+	assert.NoError(t, err)
+	assert.Len(t, out.PnProvider, 0)
+
+	// only providers deleted at the same time is restored
+	restored, err := repo.RestoreByAppID(ctx, pnprepo.InRestoreByAppID{AppID: 1, DeletedAt: 3, UpdatedAt: 4})
+	assert.NoError(t, err)
+	assert.Len(t, restored.PnProvider, 0)
+
+	restored, err = repo.RestoreByAppID(ctx, pnprepo.InRestoreByAppID{AppID: 1, DeletedAt: 2, UpdatedAt: 4})
+	assert.NoError(t, err)
+	assert.Len(t, restored.PnProvider, 2)
+
+	_, err = repo.PurgeByAppID(ctx, pnprepo.InPurgeByAppID{AppID: 1})
+	assert.ErrorIs(t, err, pnprepo.ErrActiveProvider)
+
+	_, err = repo.DelByAppID(ctx, pnprepo.InDelByAppID{AppID: 1, DeletedAt: 5})
+	assert.NoError(t, err)
+
+	_, err = repo.PurgeByAppID(ctx, pnprepo.InPurgeByAppID{AppID: 1})
+	assert.NoError(t, err)
+
+	restored, err = repo.RestoreByAppID(ctx, pnprepo.InRestoreByAppID{AppID: 1, DeletedAt: 5, UpdatedAt: 6})
+	assert.NoError(t, err)
+	assert.Len(t, restored.PnProvider, 0)
+}
@@ -0,0 +1,89 @@
+package templaterepo
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+// Repo is message template repository service
+type Repo interface {
+	Insert(ctx context.Context, in InputInsert) (out OutInsert, err error)
+	Update(ctx context.Context, in InputUpdate) (out OutUpdate, err error)
+	GetByID(ctx context.Context, in InputGetByID) (out OutGetByID, err error)
+	ListByApp(ctx context.Context, in InputListByApp) (out OutListByApp, err error)
+	DelByID(ctx context.Context, in InputDelByID) (out OutDelByID, err error)
+	PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error)
+}
+
+// Template is resembles the table structure.
+type Template struct {
+	ID            int64  `db:"id" validate:"required"`
+	AppID         int64  `db:"app_id" validate:"required"`
+	Name          string `db:"name" validate:"required"`
+	Engine        string `db:"engine" validate:"required,oneof=text html"`
+	DefaultLocale string `db:"default_locale" validate:"required"`
+
+	// VariantsJSON is JSON object of locale => provider => payload skeleton
+	VariantsJSON string `db:"variants_json" validate:"required"`
+
+	// Timestamp using integer as unix microsecond in UTC
+	CreatedAt int64 `db:"created_at" validate:"required"`
+	UpdatedAt int64 `db:"updated_at" validate:"required"`
+	DeletedAt int64 `db:"deleted_at" validate:"-"`
+}
+
+type InputInsert struct {
+	Template Template `validate:"required"`
+}
+
+type OutInsert struct {
+	Template Template
+}
+
+type InputUpdate struct {
+	Template Template `validate:"required"`
+}
+
+type OutUpdate struct {
+	Template Template
+}
+
+type InputGetByID struct {
+	AppID int64 `validate:"required"`
+	ID    int64 `validate:"required"`
+}
+
+type OutGetByID struct {
+	Template Template
+}
+
+type InputListByApp struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutListByApp struct {
+	Templates []Template
+}
+
+type InputDelByID struct {
+	AppID     int64 `validate:"required"`
+	ID        int64 `validate:"required"`
+	DeletedAt int64 `validate:"required"`
+}
+
+type OutDelByID struct {
+	Success bool
+}
+
+// InputPurgeByAppID permanently delete all message templates, including the deleted one of the purged app.
+type InputPurgeByAppID struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutPurgeByAppID struct {
+	Deleted int64
+}
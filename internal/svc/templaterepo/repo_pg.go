@@ -0,0 +1,220 @@
+package templaterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	sqlInsert = `
+INSERT INTO message_templates (id, app_id, name, engine, default_locale, variants_json, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+`
+
+	sqlUpdate = `
+UPDATE message_templates SET
+    name = $3,
+    engine = $4,
+    default_locale = $5,
+    variants_json = $6,
+    updated_at = $7
+WHERE id = $1 AND app_id = $2 AND deleted_at = 0
+RETURNING *;
+`
+
+	sqlGetByID     = `SELECT * FROM message_templates WHERE id = $1 AND app_id = $2 AND deleted_at = 0 LIMIT 1;`
+	sqlListByApp   = `SELECT * FROM message_templates WHERE app_id = $1 AND deleted_at = 0 ORDER BY id ASC;`
+	sqlSoftDelByID = `UPDATE message_templates SET deleted_at = $3 WHERE id = $1 AND app_id = $2 AND deleted_at = 0 RETURNING *;`
+
+	sqlPurgeMessageTemplatesByAppID = `DELETE FROM message_templates WHERE app_id = $1;`
+)
+
+type PostgresConfig struct {
+	Connection sqlx.ExtContext `validate:"required"`
+}
+
+type Postgres struct {
+	Config PostgresConfig
+}
+
+var _ Repo = (*Postgres)(nil)
+
+func NewPostgres(cfg PostgresConfig) (repo *Postgres, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	repo = &Postgres{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (p *Postgres) Insert(ctx context.Context, in InputInsert) (out OutInsert, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	args := []interface{}{
+		in.Template.ID,
+		in.Template.AppID,
+		in.Template.Name,
+		in.Template.Engine,
+		in.Template.DefaultLocale,
+		in.Template.VariantsJSON,
+		in.Template.CreatedAt,
+		in.Template.UpdatedAt,
+	}
+
+	var template Template
+	err = sqlx.GetContext(ctx, p.Config.Connection, &template, sqlInsert, args...)
+	if err != nil {
+		err = fmt.Errorf("insert db error: %w", err)
+		return
+	}
+
+	out = OutInsert{
+		Template: template,
+	}
+
+	return
+}
+
+func (p *Postgres) Update(ctx context.Context, in InputUpdate) (out OutUpdate, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	args := []interface{}{
+		in.Template.ID,
+		in.Template.AppID,
+		in.Template.Name,
+		in.Template.Engine,
+		in.Template.DefaultLocale,
+		in.Template.VariantsJSON,
+		in.Template.UpdatedAt,
+	}
+
+	var template Template
+	err = sqlx.GetContext(ctx, p.Config.Connection, &template, sqlUpdate, args...)
+	if err != nil {
+		err = fmt.Errorf("update db error: %w", err)
+		return
+	}
+
+	out = OutUpdate{
+		Template: template,
+	}
+
+	return
+}
+
+func (p *Postgres) GetByID(ctx context.Context, in InputGetByID) (out OutGetByID, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "templaterepo.GetByID")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var template Template
+	err = sqlx.GetContext(ctx, p.Config.Connection, &template, sqlGetByID, in.ID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot get template id %d: %w", in.ID, err)
+		return
+	}
+
+	out = OutGetByID{
+		Template: template,
+	}
+
+	return
+}
+
+func (p *Postgres) ListByApp(ctx context.Context, in InputListByApp) (out OutListByApp, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	templates := make([]Template, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &templates, sqlListByApp, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("cannot get list of templates: %w", err)
+		return
+	}
+
+	out = OutListByApp{
+		Templates: templates,
+	}
+
+	return
+}
+
+func (p *Postgres) DelByID(ctx context.Context, in InputDelByID) (out OutDelByID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var template Template
+	err = sqlx.GetContext(ctx, p.Config.Connection, &template, sqlSoftDelByID, in.ID, in.AppID, in.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		out = OutDelByID{
+			Success: false,
+		}
+
+		err = nil // discard error
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	out = OutDelByID{
+		Success: template.ID == in.ID && template.DeletedAt == in.DeletedAt,
+	}
+
+	return
+}
+
+func (p *Postgres) PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlPurgeMessageTemplatesByAppID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("purge message_templates of app id %d error: %w", in.AppID, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutPurgeByAppID{
+		Deleted: affected,
+	}
+
+	return
+}
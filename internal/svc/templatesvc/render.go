@@ -0,0 +1,128 @@
+package templatesvc
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"strings"
+	textTemplate "text/template"
+)
+
+const (
+	EngineText = "text"
+	EngineHTML = "html"
+)
+
+// executor is implemented by both text/template and html/template.
+type executor interface {
+	Execute(wr io.Writer, data any) error
+}
+
+func parse(engine, name, text string) (executor, error) {
+	switch engine {
+	case EngineText:
+		t, err := textTemplate.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+
+		return t, nil
+
+	case EngineHTML:
+		t, err := htmlTemplate.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+
+		return t, nil
+
+	default:
+		return nil, fmt.Errorf("unknown template engine '%s'", engine)
+	}
+}
+
+// Render walk the payload skeleton and execute every string value as template using vars.
+// Only string values is rendered, so the result is always valid structure for the provider payload.
+// When vars is nil, it only parses the template, this useful to validate before saving the template.
+func Render(engine string, skeleton any, vars map[string]any) (out any, err error) {
+	return render(engine, "$", skeleton, vars)
+}
+
+func render(engine, path string, skeleton any, vars map[string]any) (out any, err error) {
+	switch v := skeleton.(type) {
+	case string:
+		tpl, _err := parse(engine, path, v)
+		if _err != nil {
+			err = fmt.Errorf("cannot parse template on %s: %w", path, _err)
+			return
+		}
+
+		if vars == nil {
+			out = v
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		if _err = tpl.Execute(buf, vars); _err != nil {
+			err = fmt.Errorf("cannot render template on %s: %w", path, _err)
+			return
+		}
+
+		out = buf.String()
+		return
+
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[key], err = render(engine, path+"."+key, val, vars)
+			if err != nil {
+				return
+			}
+		}
+
+		out = m
+		return
+
+	case []any:
+		s := make([]any, len(v))
+		for i, val := range v {
+			s[i], err = render(engine, fmt.Sprintf("%s[%d]", path, i), val, vars)
+			if err != nil {
+				return
+			}
+		}
+
+		out = s
+		return
+
+	default:
+		out = v
+		return
+	}
+}
+
+// SelectLocale return the variant for the locale.
+// When the exact locale (i.e: id-ID) is not found, it fallback to the base language (i.e: id),
+// and then to the default locale.
+func SelectLocale(variants map[string]map[string]any, locale, defaultLocale string) (selected string, variant map[string]any, ok bool) {
+	candidates := make([]string, 0)
+	locale = strings.TrimSpace(locale)
+	if locale != "" {
+		candidates = append(candidates, locale)
+
+		base, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+		candidates = append(candidates, base)
+	}
+
+	candidates = append(candidates, defaultLocale)
+	for _, candidate := range candidates {
+		for key, val := range variants {
+			if strings.EqualFold(key, candidate) {
+				return key, val, true
+			}
+		}
+	}
+
+	return "", nil, false
+}
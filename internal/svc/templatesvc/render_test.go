@@ -0,0 +1,87 @@
+package templatesvc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+)
+
+func TestRender(t *testing.T) {
+	skeleton := map[string]any{
+		"notification": map[string]any{
+			"title": "Hi {{.name}}",
+			"body":  "You have {{.count}} new message",
+		},
+		"tokens": []any{"{{.token}}"},
+		"ttl":    3600,
+	}
+
+	t.Run("text", func(t *testing.T) {
+		out, err := templatesvc.Render(templatesvc.EngineText, skeleton, map[string]any{
+			"name":  "<b>John</b>",
+			"count": 2,
+			"token": "abc",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"notification": map[string]any{
+				"title": "Hi <b>John</b>",
+				"body":  "You have 2 new message",
+			},
+			"tokens": []any{"abc"},
+			"ttl":    3600,
+		}, out)
+	})
+
+	t.Run("html escaped", func(t *testing.T) {
+		out, err := templatesvc.Render(templatesvc.EngineHTML, "<p>Hi {{.name}}</p>", map[string]any{
+			"name": "<b>John</b>",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>Hi &lt;b&gt;John&lt;/b&gt;</p>", out)
+	})
+
+	t.Run("missing variable", func(t *testing.T) {
+		_, err := templatesvc.Render(templatesvc.EngineText, skeleton, map[string]any{"name": "John"})
+		assert.Error(t, err)
+	})
+
+	t.Run("parse only", func(t *testing.T) {
+		out, err := templatesvc.Render(templatesvc.EngineText, skeleton, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, skeleton, out)
+
+		_, err = templatesvc.Render(templatesvc.EngineText, "{{.name", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestSelectLocale(t *testing.T) {
+	variants := map[string]map[string]any{
+		"en": {"fcm": "en"},
+		"id": {"fcm": "id"},
+	}
+
+	testCases := []struct {
+		Locale   string
+		Selected string
+	}{
+		{Locale: "id", Selected: "id"},
+		{Locale: "id-ID", Selected: "id"},
+		{Locale: "ID_id", Selected: "id"},
+		{Locale: "fr", Selected: "en"},
+		{Locale: "", Selected: "en"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Locale, func(t *testing.T) {
+			selected, _, ok := templatesvc.SelectLocale(variants, testCase.Locale, "en")
+			assert.True(t, ok)
+			assert.Equal(t, testCase.Selected, selected)
+		})
+	}
+
+	_, _, ok := templatesvc.SelectLocale(variants, "fr", "de")
+	assert.False(t, ok)
+}
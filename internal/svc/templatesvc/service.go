@@ -0,0 +1,103 @@
+package templatesvc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+// Service manage message templates per app and render it into provider payloads.
+type Service interface {
+	Create(ctx context.Context, in InCreate) (out OutCreate, err error)
+	Update(ctx context.Context, in InUpdate) (out OutUpdate, err error)
+	Get(ctx context.Context, in InGet) (out OutGet, err error)
+	List(ctx context.Context, in InList) (out OutList, err error)
+	Delete(ctx context.Context, in InDelete) (out OutDelete, err error)
+	Render(ctx context.Context, in InRender) (out OutRender, err error)
+}
+
+// Template must not have any json or yaml tag, transport layer must define its own entity.
+type Template struct {
+	ID            int64
+	AppID         int64
+	Name          string
+	Engine        string
+	DefaultLocale string
+
+	// Variants is locale => provider => payload skeleton.
+	// i.e: {"en": {"fcm": {"notification": {"title": "Hi {{.name}}"}}}}
+	Variants map[string]map[string]any
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type InTemplate struct {
+	Name          string                    `validate:"required"`
+	Engine        string                    `validate:"required,oneof=text html"`
+	DefaultLocale string                    `validate:"required"`
+	Variants      map[string]map[string]any `validate:"required,min=1"`
+}
+
+type InCreate struct {
+	AppID    int64      `validate:"required"`
+	Template InTemplate `validate:"required"`
+}
+
+type OutCreate struct {
+	Template Template
+}
+
+type InUpdate struct {
+	AppID    int64      `validate:"required"`
+	ID       int64      `validate:"required"`
+	Template InTemplate `validate:"required"`
+}
+
+type OutUpdate struct {
+	Template Template
+}
+
+type InGet struct {
+	AppID int64 `validate:"required"`
+	ID    int64 `validate:"required"`
+}
+
+type OutGet struct {
+	Template Template
+}
+
+type InList struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutList struct {
+	Templates []Template
+}
+
+type InDelete struct {
+	AppID int64 `validate:"required"`
+	ID    int64 `validate:"required"`
+}
+
+type OutDelete struct {
+	Success bool
+}
+
+type InRender struct {
+	AppID     int64 `validate:"required"`
+	ID        int64 `validate:"required"`
+	Locale    string
+	Variables map[string]any
+}
+
+type OutRender struct {
+	Locale string
+
+	// Payloads is provider => rendered payload, ready to use as msgsvc payload.
+	Payloads map[string]any
+}
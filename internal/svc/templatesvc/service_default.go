@@ -0,0 +1,289 @@
+package templatesvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templaterepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/uid"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type Config struct {
+	UIDGen       uid.UID           `validate:"required"`
+	TemplateRepo templaterepo.Repo `validate:"required"`
+}
+
+type ServiceDefault struct {
+	Config Config
+}
+
+var _ Service = (*ServiceDefault)(nil)
+
+func New(cfg Config) (svc *ServiceDefault, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svc = &ServiceDefault{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Create(ctx context.Context, in InCreate) (out OutCreate, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error create template: %s", ErrValidation, err)
+		return
+	}
+
+	variantsJson, err := validateVariants(in.Template)
+	if err != nil {
+		return
+	}
+
+	id, err := s.Config.UIDGen.NextID()
+	if err != nil {
+		err = fmt.Errorf("cannot generate uid for new record: %w", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	outInsert, err := s.Config.TemplateRepo.Insert(ctx, templaterepo.InputInsert{
+		Template: templaterepo.Template{
+			ID:            int64(id),
+			AppID:         in.AppID,
+			Name:          in.Template.Name,
+			Engine:        in.Template.Engine,
+			DefaultLocale: in.Template.DefaultLocale,
+			VariantsJSON:  variantsJson,
+			CreatedAt:     now.UnixMicro(),
+			UpdatedAt:     now.UnixMicro(),
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot insert template record: %w", err)
+		return
+	}
+
+	out = OutCreate{
+		Template: FromRepo(outInsert.Template),
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Update(ctx context.Context, in InUpdate) (out OutUpdate, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error update template: %s", ErrValidation, err)
+		return
+	}
+
+	variantsJson, err := validateVariants(in.Template)
+	if err != nil {
+		return
+	}
+
+	outUpdate, err := s.Config.TemplateRepo.Update(ctx, templaterepo.InputUpdate{
+		Template: templaterepo.Template{
+			ID:            in.ID,
+			AppID:         in.AppID,
+			Name:          in.Template.Name,
+			Engine:        in.Template.Engine,
+			DefaultLocale: in.Template.DefaultLocale,
+			VariantsJSON:  variantsJson,
+			CreatedAt:     time.Now().UTC().UnixMicro(), // not updated, only to pass validation
+			UpdatedAt:     time.Now().UTC().UnixMicro(),
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot update template record: %w", err)
+		return
+	}
+
+	out = OutUpdate{
+		Template: FromRepo(outUpdate.Template),
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Get(ctx context.Context, in InGet) (out OutGet, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "templatesvc.Get")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outGet, err := s.Config.TemplateRepo.GetByID(ctx, templaterepo.InputGetByID{
+		AppID: in.AppID,
+		ID:    in.ID,
+	})
+	if err != nil {
+		err = fmt.Errorf("not found template id %d: %w", in.ID, err)
+		return
+	}
+
+	out = OutGet{
+		Template: FromRepo(outGet.Template),
+	}
+
+	return
+}
+
+func (s *ServiceDefault) List(ctx context.Context, in InList) (out OutList, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outList, err := s.Config.TemplateRepo.ListByApp(ctx, templaterepo.InputListByApp{
+		AppID: in.AppID,
+	})
+	if err != nil {
+		err = fmt.Errorf("list templates error: %w", err)
+		return
+	}
+
+	templates := make([]Template, 0)
+	for _, template := range outList.Templates {
+		templates = append(templates, FromRepo(template))
+	}
+
+	out = OutList{
+		Templates: templates,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Delete(ctx context.Context, in InDelete) (out OutDelete, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outDel, err := s.Config.TemplateRepo.DelByID(ctx, templaterepo.InputDelByID{
+		AppID:     in.AppID,
+		ID:        in.ID,
+		DeletedAt: time.Now().UTC().UnixMicro(),
+	})
+	if err != nil {
+		err = fmt.Errorf("db delete template id %d error: %w", in.ID, err)
+		return
+	}
+
+	out = OutDelete{
+		Success: outDel.Success,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Render(ctx context.Context, in InRender) (out OutRender, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "templatesvc.Render")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outGet, err := s.Get(ctx, InGet{
+		AppID: in.AppID,
+		ID:    in.ID,
+	})
+	if err != nil {
+		return
+	}
+
+	template := outGet.Template
+	locale, variant, ok := SelectLocale(template.Variants, in.Locale, template.DefaultLocale)
+	if !ok {
+		err = fmt.Errorf("template id %d has no variant for locale '%s' nor default locale '%s'",
+			template.ID, in.Locale, template.DefaultLocale,
+		)
+		return
+	}
+
+	vars := in.Variables
+	if vars == nil {
+		vars = map[string]any{}
+	}
+
+	payloads := make(map[string]any)
+	for provider, skeleton := range variant {
+		payloads[provider], err = Render(template.Engine, skeleton, vars)
+		if err != nil {
+			err = fmt.Errorf("template id %d locale '%s' provider '%s': %w", template.ID, locale, provider, err)
+			return
+		}
+	}
+
+	out = OutRender{
+		Locale:   locale,
+		Payloads: payloads,
+	}
+
+	return
+}
+
+// -- func helper
+
+// validateVariants parse every template in all variants and return the JSON string to be saved.
+func validateVariants(in InTemplate) (variantsJson string, err error) {
+	if _, _, ok := SelectLocale(in.Variants, in.DefaultLocale, in.DefaultLocale); !ok {
+		err = fmt.Errorf("%w: default locale '%s' is not defined in variants", ErrValidation, in.DefaultLocale)
+		return
+	}
+
+	for locale, variant := range in.Variants {
+		for provider, skeleton := range variant {
+			if _, _err := Render(in.Engine, skeleton, nil); _err != nil {
+				err = fmt.Errorf("%w: locale '%s' provider '%s': %s", ErrValidation, locale, provider, _err)
+				return
+			}
+		}
+	}
+
+	b, err := json.Marshal(in.Variants)
+	if err != nil {
+		err = fmt.Errorf("cannot marshal template variants: %w", err)
+		return
+	}
+
+	variantsJson = string(b)
+	return
+}
+
+func FromRepo(t templaterepo.Template) Template {
+	variants := make(map[string]map[string]any)
+	_ = json.Unmarshal([]byte(t.VariantsJSON), &variants)
+
+	return Template{
+		ID:            t.ID,
+		AppID:         t.AppID,
+		Name:          t.Name,
+		Engine:        t.Engine,
+		DefaultLocale: t.DefaultLocale,
+		Variants:      variants,
+		CreatedAt:     time.UnixMicro(t.CreatedAt).UTC(),
+		UpdatedAt:     time.UnixMicro(t.UpdatedAt).UTC(),
+	}
+}
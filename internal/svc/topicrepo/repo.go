@@ -0,0 +1,75 @@
+package topicrepo
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+const (
+	MemberTypeUser      = "user"
+	MemberTypeRecipient = "recipient"
+)
+
+// Repo is topic subscription repository service
+type Repo interface {
+	Subscribe(ctx context.Context, in InputSubscribe) (out OutSubscribe, err error)
+	Unsubscribe(ctx context.Context, in InputUnsubscribe) (out OutUnsubscribe, err error)
+	ListByTopics(ctx context.Context, in InputListByTopics) (out OutListByTopics, err error)
+	PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error)
+}
+
+// Subscription is resembles the table structure.
+type Subscription struct {
+	ID         int64  `db:"id" validate:"required"`
+	AppID      int64  `db:"app_id" validate:"required"`
+	Topic      string `db:"topic" validate:"required"`
+	MemberType string `db:"member_type" validate:"required,oneof=user recipient"`
+	Provider   string `db:"provider" validate:"required_if=MemberType recipient"`
+	Member     string `db:"member" validate:"required"`
+
+	// Timestamp using integer as unix microsecond in UTC
+	CreatedAt int64 `db:"created_at" validate:"required"`
+}
+
+type InputSubscribe struct {
+	Subscriptions []Subscription `validate:"required,min=1,dive"`
+}
+
+type OutSubscribe struct {
+	// Inserted only contain new subscription, already subscribed member is ignored
+	Inserted int64
+}
+
+type InputUnsubscribe struct {
+	AppID      int64  `validate:"required"`
+	Topic      string `validate:"required"`
+	MemberType string `validate:"required,oneof=user recipient"`
+	Provider   string `validate:"required_if=MemberType recipient"`
+	Member     string `validate:"required"`
+}
+
+type OutUnsubscribe struct {
+	Success bool
+}
+
+type InputListByTopics struct {
+	AppID  int64    `validate:"required"`
+	Topics []string `validate:"required,min=1"`
+}
+
+type OutListByTopics struct {
+	Subscriptions []Subscription
+}
+
+// InputPurgeByAppID permanently delete all topic subscriptions of the purged app.
+type InputPurgeByAppID struct {
+	AppID int64 `validate:"required"`
+}
+
+type OutPurgeByAppID struct {
+	Deleted int64
+}
@@ -0,0 +1,172 @@
+package topicrepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+const (
+	sqlSubscribe = `
+INSERT INTO topic_subscriptions (id, app_id, topic, member_type, provider, member, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (app_id, LOWER(topic), member_type, provider, member) DO NOTHING;
+`
+
+	sqlUnsubscribe = `
+DELETE FROM topic_subscriptions
+WHERE app_id = $1 AND LOWER(topic) = $2 AND member_type = $3 AND provider = $4 AND member = $5;
+`
+
+	// SqlListByTopics use with sqlx.In so it mush using quote rather than dollar
+	SqlListByTopics = `SELECT * FROM topic_subscriptions WHERE app_id = ? AND LOWER(topic) IN (?) ORDER BY id ASC;`
+
+	sqlPurgeTopicSubscriptionsByAppID = `DELETE FROM topic_subscriptions WHERE app_id = $1;`
+)
+
+type PostgresConfig struct {
+	Connection sqlx.ExtContext `validate:"required"`
+}
+
+type Postgres struct {
+	Config PostgresConfig
+}
+
+var _ Repo = (*Postgres)(nil)
+
+func NewPostgres(cfg PostgresConfig) (repo *Postgres, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	repo = &Postgres{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (p *Postgres) Subscribe(ctx context.Context, in InputSubscribe) (out OutSubscribe, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	var inserted int64
+	for _, sub := range in.Subscriptions {
+		args := []interface{}{
+			sub.ID,
+			sub.AppID,
+			strings.ToLower(strings.TrimSpace(sub.Topic)),
+			sub.MemberType,
+			sub.Provider,
+			sub.Member,
+			sub.CreatedAt,
+		}
+
+		res, _err := p.Config.Connection.ExecContext(ctx, sqlSubscribe, args...)
+		if _err != nil {
+			err = fmt.Errorf("subscribe '%s' to topic '%s' error: %w", sub.Member, sub.Topic, _err)
+			return
+		}
+
+		affected, _ := res.RowsAffected()
+		inserted += affected
+	}
+
+	out = OutSubscribe{
+		Inserted: inserted,
+	}
+
+	return
+}
+
+func (p *Postgres) Unsubscribe(ctx context.Context, in InputUnsubscribe) (out OutUnsubscribe, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlUnsubscribe,
+		in.AppID, strings.ToLower(strings.TrimSpace(in.Topic)), in.MemberType, in.Provider, in.Member,
+	)
+	if err != nil {
+		err = fmt.Errorf("unsubscribe '%s' from topic '%s' error: %w", in.Member, in.Topic, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutUnsubscribe{
+		Success: affected > 0,
+	}
+
+	return
+}
+
+func (p *Postgres) ListByTopics(ctx context.Context, in InputListByTopics) (out OutListByTopics, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "topicrepo.ListByTopics")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	topics := make([]string, 0)
+	for _, topic := range in.Topics {
+		topics = append(topics, strings.ToLower(strings.TrimSpace(topic)))
+	}
+
+	query, args, err := sqlx.In(SqlListByTopics, in.AppID, topics)
+	if err != nil {
+		err = fmt.Errorf("cannot generate sql query: %w", err)
+		return
+	}
+
+	// query is rebind using $ because we use postrges here
+	query = sqlx.Rebind(sqlx.DOLLAR, query)
+
+	subscriptions := make([]Subscription, 0)
+	err = sqlx.SelectContext(ctx, p.Config.Connection, &subscriptions, query, args...)
+	if err != nil {
+		err = fmt.Errorf("cannot get topic subscriptions: %w", err)
+		return
+	}
+
+	out = OutListByTopics{
+		Subscriptions: subscriptions,
+	}
+
+	return
+}
+
+func (p *Postgres) PurgeByAppID(ctx context.Context, in InputPurgeByAppID) (out OutPurgeByAppID, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	res, err := p.Config.Connection.ExecContext(ctx, sqlPurgeTopicSubscriptionsByAppID, in.AppID)
+	if err != nil {
+		err = fmt.Errorf("purge topic_subscriptions of app id %d error: %w", in.AppID, err)
+		return
+	}
+
+	affected, _ := res.RowsAffected()
+	out = OutPurgeByAppID{
+		Deleted: affected,
+	}
+
+	return
+}
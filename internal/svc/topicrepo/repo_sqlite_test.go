@@ -0,0 +1,55 @@
+package topicrepo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	// each connection of :memory: is a new database
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	migrations, err := migration.Load(assets.Migrations, "migrations/sqlite/topicrepo")
+	assert.NoError(t, err)
+
+	migrator, err := migration.New(migration.Config{DB: db, Dialect: "sqlite", Service: "topic", Migrations: migrations})
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+
+	repo, err := topicrepo.NewSQLite(topicrepo.SQLiteConfig{Connection: db})
+	assert.NoError(t, err)
+
+	sub := topicrepo.Subscription{ID: 1, AppID: 1, Topic: "News", MemberType: topicrepo.MemberTypeUser, Member: "user1", CreatedAt: 1}
+	out, err := repo.Subscribe(ctx, topicrepo.InputSubscribe{Subscriptions: []topicrepo.Subscription{sub}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, out.Inserted)
+
+	// same topic in different case is already subscribed
+	sub.ID, sub.Topic = 2, "news"
+	out, err = repo.Subscribe(ctx, topicrepo.InputSubscribe{Subscriptions: []topicrepo.Subscription{sub}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, out.Inserted)
+
+	list, err := repo.ListByTopics(ctx, topicrepo.InputListByTopics{AppID: 1, Topics: []string{"NEWS"}})
+	assert.NoError(t, err)
+	assert.Len(t, list.Subscriptions, 1)
+
+	unsub, err := repo.Unsubscribe(ctx, topicrepo.InputUnsubscribe{AppID: 1, Topic: "news", MemberType: topicrepo.MemberTypeUser, Member: "user1"})
+	assert.NoError(t, err)
+	assert.True(t, unsub.Success)
+}
@@ -0,0 +1,82 @@
+package topicsvc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrValidation = errors.New("validation error")
+)
+
+// TopicPrefix is used as recipient target to send to all topic members, i.e: topic:news
+const TopicPrefix = "topic:"
+
+// Service manage topic subscriptions. Member of topic can be a user (resolved using device registry)
+// or provider specific recipient (i.e: fcm token, email address) so the topic stay provider-agnostic.
+type Service interface {
+	Subscribe(ctx context.Context, in InSubscribe) (out OutSubscribe, err error)
+	Unsubscribe(ctx context.Context, in InUnsubscribe) (out OutUnsubscribe, err error)
+	ListMembers(ctx context.Context, in InListMembers) (out OutListMembers, err error)
+
+	// ResolveRecipients return provider => list of unique recipient of all topic members.
+	ResolveRecipients(ctx context.Context, in InResolveRecipients) (out OutResolveRecipients, err error)
+}
+
+type Member struct {
+	Topic      string
+	MemberType string // user or recipient
+	Provider   string // empty for user
+	Member     string
+	CreatedAt  time.Time
+}
+
+type InRecipient struct {
+	Provider  string `validate:"required"`
+	Recipient string `validate:"required"`
+}
+
+type InMembers struct {
+	UserIDs    []string      `validate:"required_without=Recipients"`
+	Recipients []InRecipient `validate:"required_without=UserIDs,dive"`
+}
+
+type InSubscribe struct {
+	AppID   int64     `validate:"required"`
+	Topic   string    `validate:"required,max=255"`
+	Members InMembers `validate:"required"`
+}
+
+type OutSubscribe struct {
+	Subscribed int64
+}
+
+type InUnsubscribe struct {
+	AppID   int64     `validate:"required"`
+	Topic   string    `validate:"required"`
+	Members InMembers `validate:"required"`
+}
+
+type OutUnsubscribe struct {
+	Unsubscribed int64
+}
+
+type InListMembers struct {
+	AppID int64  `validate:"required"`
+	Topic string `validate:"required"`
+}
+
+type OutListMembers struct {
+	Members []Member
+}
+
+type InResolveRecipients struct {
+	AppID  int64    `validate:"required"`
+	Topics []string `validate:"required,min=1"`
+}
+
+type OutResolveRecipients struct {
+	// Recipients provider => unique recipients
+	Recipients map[string][]string
+}
@@ -0,0 +1,259 @@
+package topicsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ngendika/pkg/uid"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	UIDGen    uid.UID           `validate:"required"`
+	TopicRepo topicrepo.Repo    `validate:"required"`
+	DeviceSvc devicesvc.Service `validate:"required"`
+}
+
+type ServiceDefault struct {
+	Config Config
+}
+
+var _ Service = (*ServiceDefault)(nil)
+
+func New(cfg Config) (svc *ServiceDefault, err error) {
+	err = validator.Validate(cfg)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	svc = &ServiceDefault{
+		Config: cfg,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Subscribe(ctx context.Context, in InSubscribe) (out OutSubscribe, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error subscribe topic: %s", ErrValidation, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	subscriptions := make([]topicrepo.Subscription, 0)
+	for _, member := range toRepoMembers(in.Members) {
+		id, _err := s.Config.UIDGen.NextID()
+		if _err != nil {
+			err = fmt.Errorf("cannot generate uid for new record: %w", _err)
+			return
+		}
+
+		member.ID = int64(id)
+		member.AppID = in.AppID
+		member.Topic = TrimTopic(in.Topic)
+		member.CreatedAt = now.UnixMicro()
+		subscriptions = append(subscriptions, member)
+	}
+
+	outSubscribe, err := s.Config.TopicRepo.Subscribe(ctx, topicrepo.InputSubscribe{
+		Subscriptions: subscriptions,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot subscribe topic '%s': %w", in.Topic, err)
+		return
+	}
+
+	out = OutSubscribe{
+		Subscribed: outSubscribe.Inserted,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) Unsubscribe(ctx context.Context, in InUnsubscribe) (out OutUnsubscribe, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: error unsubscribe topic: %s", ErrValidation, err)
+		return
+	}
+
+	var unsubscribed int64
+	for _, member := range toRepoMembers(in.Members) {
+		outUnsubscribe, _err := s.Config.TopicRepo.Unsubscribe(ctx, topicrepo.InputUnsubscribe{
+			AppID:      in.AppID,
+			Topic:      TrimTopic(in.Topic),
+			MemberType: member.MemberType,
+			Provider:   member.Provider,
+			Member:     member.Member,
+		})
+		if _err != nil {
+			err = fmt.Errorf("cannot unsubscribe topic '%s': %w", in.Topic, _err)
+			return
+		}
+
+		if outUnsubscribe.Success {
+			unsubscribed++
+		}
+	}
+
+	out = OutUnsubscribe{
+		Unsubscribed: unsubscribed,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) ListMembers(ctx context.Context, in InListMembers) (out OutListMembers, err error) {
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	outList, err := s.Config.TopicRepo.ListByTopics(ctx, topicrepo.InputListByTopics{
+		AppID:  in.AppID,
+		Topics: []string{TrimTopic(in.Topic)},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot list member of topic '%s': %w", in.Topic, err)
+		return
+	}
+
+	members := make([]Member, 0)
+	for _, sub := range outList.Subscriptions {
+		members = append(members, Member{
+			Topic:      sub.Topic,
+			MemberType: sub.MemberType,
+			Provider:   sub.Provider,
+			Member:     sub.Member,
+			CreatedAt:  time.UnixMicro(sub.CreatedAt).UTC(),
+		})
+	}
+
+	out = OutListMembers{
+		Members: members,
+	}
+
+	return
+}
+
+func (s *ServiceDefault) ResolveRecipients(ctx context.Context, in InResolveRecipients) (out OutResolveRecipients, err error) {
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, "topicsvc.ResolveRecipients")
+	defer span.End()
+
+	err = validator.Validate(in)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrValidation, err)
+		return
+	}
+
+	topics := make([]string, 0)
+	for _, topic := range in.Topics {
+		topics = append(topics, TrimTopic(topic))
+	}
+
+	outList, err := s.Config.TopicRepo.ListByTopics(ctx, topicrepo.InputListByTopics{
+		AppID:  in.AppID,
+		Topics: topics,
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot get topic members: %w", err)
+		return
+	}
+
+	recipients := make(map[string][]string)
+	userIDs := make([]string, 0)
+	for _, sub := range outList.Subscriptions {
+		switch sub.MemberType {
+		case topicrepo.MemberTypeUser:
+			userIDs = append(userIDs, sub.Member)
+		case topicrepo.MemberTypeRecipient:
+			recipients[sub.Provider] = append(recipients[sub.Provider], sub.Member)
+		}
+	}
+
+	if len(userIDs) > 0 {
+		outResolve, _err := s.Config.DeviceSvc.ResolveRecipients(ctx, devicesvc.InResolveRecipients{
+			AppID:   in.AppID,
+			UserIDs: userIDs,
+		})
+		if _err != nil {
+			err = fmt.Errorf("cannot resolve topic user members: %w", _err)
+			return
+		}
+
+		for provider, tokens := range outResolve.Recipients {
+			recipients[provider] = append(recipients[provider], tokens...)
+		}
+	}
+
+	out = OutResolveRecipients{
+		Recipients: MergeRecipients(recipients),
+	}
+
+	return
+}
+
+// -- func helper
+
+// TrimTopic remove the topic: prefix and normalize the topic name.
+func TrimTopic(topic string) string {
+	topic = strings.TrimSpace(topic)
+	if strings.HasPrefix(strings.ToLower(topic), TopicPrefix) {
+		topic = topic[len(TopicPrefix):]
+	}
+
+	return strings.ToLower(strings.TrimSpace(topic))
+}
+
+// MergeRecipients merge all maps of provider => recipients into one map with unique recipients per provider.
+func MergeRecipients(all ...map[string][]string) map[string][]string {
+	merged := make(map[string][]string)
+	seen := make(map[string]map[string]struct{})
+	for _, recipients := range all {
+		for provider, values := range recipients {
+			if seen[provider] == nil {
+				seen[provider] = make(map[string]struct{})
+			}
+
+			for _, value := range values {
+				if _, exist := seen[provider][value]; exist {
+					continue
+				}
+
+				seen[provider][value] = struct{}{}
+				merged[provider] = append(merged[provider], value)
+			}
+		}
+	}
+
+	return merged
+}
+
+func toRepoMembers(in InMembers) []topicrepo.Subscription {
+	members := make([]topicrepo.Subscription, 0)
+	for _, userID := range in.UserIDs {
+		members = append(members, topicrepo.Subscription{
+			MemberType: topicrepo.MemberTypeUser,
+			Member:     strings.TrimSpace(userID),
+		})
+	}
+
+	for _, recipient := range in.Recipients {
+		members = append(members, topicrepo.Subscription{
+			MemberType: topicrepo.MemberTypeRecipient,
+			Provider:   strings.TrimSpace(recipient.Provider),
+			Member:     strings.TrimSpace(recipient.Recipient),
+		})
+	}
+
+	return members
+}
@@ -0,0 +1,26 @@
+package topicsvc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+)
+
+func TestTrimTopic(t *testing.T) {
+	assert.Equal(t, "news", topicsvc.TrimTopic("topic:news"))
+	assert.Equal(t, "news", topicsvc.TrimTopic(" Topic:News "))
+	assert.Equal(t, "news", topicsvc.TrimTopic("news"))
+}
+
+func TestMergeRecipients(t *testing.T) {
+	merged := topicsvc.MergeRecipients(
+		map[string][]string{"fcm": {"a", "b"}},
+		map[string][]string{"fcm": {"b", "c"}, "email": {"x@y.z"}},
+	)
+
+	assert.Equal(t, map[string][]string{
+		"fcm":   {"a", "b", "c"},
+		"email": {"x@y.z"},
+	}, merged)
+}
@@ -0,0 +1,30 @@
+package uow
+
+import (
+	"context"
+
+	"github.com/yusufsyaifudin/ngendika/internal/svc/apprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbackrepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicerepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnprepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templaterepo"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicrepo"
+)
+
+// Repositories is bound to one transaction, it must not be used after UnitOfWork.Do returns.
+// Repository of the service that is not using the database of the UnitOfWork returns error.
+type Repositories interface {
+	AppRepo() (apprepo.Repo, error)
+	PNProviderRepo() (pnprepo.Repo, error)
+	TemplateRepo() (templaterepo.Repo, error)
+	DeviceRepo() (devicerepo.Repo, error)
+	TopicRepo() (topicrepo.Repo, error)
+	CallbackRepo() (callbackrepo.Repo, error)
+}
+
+// UnitOfWork compose repository calls of one database into one transaction.
+type UnitOfWork interface {
+	// Do commit the transaction when fn return nil and rollback otherwise.
+	// Cache eviction of the repositories is done after commit, so other readers cannot cache the uncommitted data.
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
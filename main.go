@@ -5,22 +5,26 @@ import (
 	"github.com/yusufsyaifudin/ngendika/cmd/gen/genapidoc"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/mitchellh/cli"
 	"github.com/yusufsyaifudin/ngendika/cmd/api"
+	"github.com/yusufsyaifudin/ngendika/cmd/migrate"
 )
 
 func main() {
 	const appName, appVersion = "ngendika", "1.0.0"
 
-	apiCmd := api.NewCmd(appName, appVersion)
+	configFile, args := configFlag(os.Args[1:])
+	apiCmd := api.NewCmd(appName, appVersion, configFile)
 
 	c := cli.NewCLI(appName, appVersion)
-	c.Args = os.Args[1:]
+	c.Args = args
 	c.Autocomplete = true
 	c.Commands = map[string]cli.CommandFactory{
-		"":    apiCmd, // default command if no subcommand defined
-		"api": apiCmd,
+		"":        apiCmd, // default command if no subcommand defined
+		"api":     apiCmd,
+		"migrate": migrate.NewCmd(configFile),
 		"apidoc": func() (cli.Command, error) {
 			return genapidoc.NewApiDocCmd(genapidoc.ApiDocCfg{})
 		},
@@ -33,3 +37,33 @@ func main() {
 
 	os.Exit(exitStatus)
 }
+
+// configFlag take global -c or --config flag before the subcommand, i.e: ngendika -c config.yml api,
+// so it is not treated as the subcommand. Flag after the subcommand is parsed by each subcommand.
+func configFlag(args []string) (configFile string, rest []string) {
+	rest = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg != "" && arg[0] != '-' {
+			// subcommand found, keep the remaining args as is
+			rest = append(rest, args[i:]...)
+			return
+		}
+
+		switch {
+		case arg == "-c", arg == "--config", arg == "-config":
+			if i+1 < len(args) {
+				configFile = args[i+1]
+				i++
+			}
+
+		case strings.HasPrefix(arg, "-c="), strings.HasPrefix(arg, "--config="), strings.HasPrefix(arg, "-config="):
+			_, configFile, _ = strings.Cut(arg, "=")
+
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,87 @@
+package apikey
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+// Scope is a permission attached to an API key and checked per route or method.
+type Scope string
+
+const (
+	ScopeAll            Scope = "*"
+	ScopeAppsRead       Scope = "apps:read"
+	ScopeAppsWrite      Scope = "apps:write"
+	ScopeAppsAdmin      Scope = "apps:admin"
+	ScopePnpRead        Scope = "pnp:read"
+	ScopePnpWrite       Scope = "pnp:write"
+	ScopePnpAdmin       Scope = "pnp:admin"
+	ScopeTemplatesRead  Scope = "templates:read"
+	ScopeTemplatesWrite Scope = "templates:write"
+	ScopeDevicesRead    Scope = "devices:read"
+	ScopeDevicesWrite   Scope = "devices:write"
+	ScopeTopicsRead     Scope = "topics:read"
+	ScopeTopicsWrite    Scope = "topics:write"
+	ScopeMessagesSend   Scope = "messages:send"
+	ScopeCallbacksRead  Scope = "callbacks:read"
+	ScopeCallbacksWrite Scope = "callbacks:write"
+)
+
+// Scopes is all known Scope, keep it the same as the scopes validation of container.ConfigAPIKey.
+var Scopes = []Scope{
+	ScopeAll,
+	ScopeAppsRead, ScopeAppsWrite, ScopeAppsAdmin,
+	ScopePnpRead, ScopePnpWrite, ScopePnpAdmin,
+	ScopeTemplatesRead, ScopeTemplatesWrite,
+	ScopeDevicesRead, ScopeDevicesWrite,
+	ScopeTopicsRead, ScopeTopicsWrite,
+	ScopeMessagesSend,
+	ScopeCallbacksRead, ScopeCallbacksWrite,
+}
+
+// APIKey is a key that allowed to access the routes protected by its Scopes.
+type APIKey struct {
+	Name   string  `validate:"required"`
+	Key    string  `validate:"required"`
+	Scopes []Scope `validate:"required,min=1"`
+}
+
+// Allow return true when the key has the exact scope, the wildcard scope,
+// or the admin scope of the same resource, i.e: apps:admin allow apps:read and apps:write.
+func (k APIKey) Allow(scope Scope) bool {
+	resource, _, _ := strings.Cut(string(scope), ":")
+	for _, s := range k.Scopes {
+		switch s {
+		case ScopeAll, scope, Scope(resource + ":admin"):
+			return true
+		}
+	}
+
+	return false
+}
+
+// Keys is the registered API keys, empty means auth is disabled.
+type Keys []APIKey
+
+// Lookup compare the key in constant time to all registered keys.
+func (k Keys) Lookup(key string) (APIKey, bool) {
+	var found APIKey
+	var ok bool
+	for _, apiKey := range k {
+		if subtle.ConstantTimeCompare([]byte(apiKey.Key), []byte(key)) == 1 {
+			found, ok = apiKey, true
+		}
+	}
+
+	return found, ok
+}
+
+// BearerToken return the token of "Bearer <token>" authorization value, empty when the scheme is not Bearer.
+func BearerToken(authorization string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
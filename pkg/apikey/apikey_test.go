@@ -0,0 +1,53 @@
+package apikey_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+)
+
+func TestAPIKey_Allow(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Scopes []apikey.Scope
+		Scope  apikey.Scope
+		Allow  bool
+	}{
+		{Name: "exact", Scopes: []apikey.Scope{apikey.ScopeMessagesSend}, Scope: apikey.ScopeMessagesSend, Allow: true},
+		{Name: "wildcard", Scopes: []apikey.Scope{apikey.ScopeAll}, Scope: apikey.ScopePnpWrite, Allow: true},
+		{Name: "admin same resource", Scopes: []apikey.Scope{apikey.ScopeAppsAdmin}, Scope: apikey.ScopeAppsWrite, Allow: true},
+		{Name: "admin other resource", Scopes: []apikey.Scope{apikey.ScopeAppsAdmin}, Scope: apikey.ScopePnpRead, Allow: false},
+		{Name: "read cannot write", Scopes: []apikey.Scope{apikey.ScopePnpRead}, Scope: apikey.ScopePnpWrite, Allow: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			key := apikey.APIKey{Name: "test", Key: "secret", Scopes: testCase.Scopes}
+			assert.Equal(t, testCase.Allow, key.Allow(testCase.Scope))
+		})
+	}
+}
+
+func TestKeys_Lookup(t *testing.T) {
+	keys := apikey.Keys{
+		{Name: "admin", Key: "admin-key", Scopes: []apikey.Scope{apikey.ScopeAll}},
+		{Name: "sender", Key: "sender-key", Scopes: []apikey.Scope{apikey.ScopeMessagesSend}},
+	}
+
+	key, ok := keys.Lookup("sender-key")
+	assert.True(t, ok)
+	assert.Equal(t, "sender", key.Name)
+
+	_, ok = keys.Lookup("sender")
+	assert.False(t, ok)
+
+	_, ok = apikey.Keys(nil).Lookup("")
+	assert.False(t, ok)
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "secret", apikey.BearerToken(" bearer secret "))
+	assert.Equal(t, "", apikey.BearerToken("Basic secret"))
+	assert.Equal(t, "", apikey.BearerToken("secret"))
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-redis/redis/v8"
+	"github.com/yusufsyaifudin/ylog"
+)
+
+type BroadcastConfig struct {
+	Local   Cache                 `validate:"required"`
+	PubSub  redis.UniversalClient `validate:"required"`
+	Channel string                `validate:"required"`
+}
+
+// Broadcast is a local (in-memory) cache that publish every deleted key to Redis channel,
+// so every node subscribing the same channel delete the key from its local cache.
+// The published key is prefixed by the node id, so the publisher ignore its own message
+// and the value written after the Delete (i.e: by Tiered SetExp) is not evicted.
+type Broadcast struct {
+	Conf   BroadcastConfig
+	nodeID string
+	pubSub *redis.PubSub
+	done   chan struct{}
+}
+
+var _ Cache = (*Broadcast)(nil)
+var _ Pinger = (*Broadcast)(nil)
+
+// NewBroadcast start subscribing the channel, Close must be called to stop it.
+func NewBroadcast(conf BroadcastConfig) (*Broadcast, error) {
+	err := validator.New().Struct(conf)
+	if err != nil {
+		err = fmt.Errorf("error validate cache broadcast: %w", err)
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	if _, err = rand.Read(id); err != nil {
+		err = fmt.Errorf("cannot generate cache broadcast node id: %w", err)
+		return nil, err
+	}
+
+	b := &Broadcast{
+		Conf:   conf,
+		nodeID: hex.EncodeToString(id),
+		pubSub: conf.PubSub.Subscribe(context.Background(), conf.Channel),
+		done:   make(chan struct{}),
+	}
+
+	go b.listen()
+	return b, nil
+}
+
+// listen until the subscription is closed, go-redis reconnect the subscription when the connection is lost.
+func (b *Broadcast) listen() {
+	defer close(b.done)
+
+	for msg := range b.pubSub.Channel() {
+		nodeID, key, ok := strings.Cut(msg.Payload, " ")
+		if !ok {
+			// message without node id, i.e: published by the older version
+			nodeID, key = "", msg.Payload
+		}
+
+		if nodeID == b.nodeID {
+			continue
+		}
+
+		err := b.Conf.Local.Delete(context.Background(), key)
+		if err != nil {
+			ylog.Error(context.Background(), "cannot evict broadcast cache key", ylog.KV("key", key), ylog.KV("error", err))
+		}
+	}
+}
+
+func (b *Broadcast) GetAs(ctx context.Context, key string, out interface{}) error {
+	return b.Conf.Local.GetAs(ctx, key, out)
+}
+
+func (b *Broadcast) SetExp(ctx context.Context, key string, inValue interface{}, expireDur time.Duration) error {
+	return b.Conf.Local.SetExp(ctx, key, inValue, expireDur)
+}
+
+// Delete the key from local cache, then publish it to the other nodes.
+func (b *Broadcast) Delete(ctx context.Context, key string) error {
+	err := b.Conf.Local.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	err = b.Conf.PubSub.Publish(ctx, b.Conf.Channel, b.nodeID+" "+key).Err()
+	if err != nil {
+		err = fmt.Errorf("error publish evicted key on redis: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *Broadcast) Ping(ctx context.Context) error {
+	err := b.Conf.PubSub.Ping(ctx).Err()
+	if err != nil {
+		err = fmt.Errorf("error occured on redis: %w", err)
+		return err
+	}
+
+	return nil
+}
+
+// Close stop the subscription, it does not close the Redis client.
+func (b *Broadcast) Close() error {
+	err := b.pubSub.Close()
+	<-b.done
+	return err
+}
@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+)
+
+// slowDelete delay every Delete, so the eviction received from the channel is done after the next write,
+// the same as Redis on the network.
+type slowDelete struct {
+	cache.Cache
+}
+
+func (s slowDelete) Delete(ctx context.Context, key string) error {
+	time.Sleep(50 * time.Millisecond)
+	return s.Cache.Delete(ctx, key)
+}
+
+func TestBroadcast_Delete(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	newNode := func() *cache.Broadcast {
+		local, err := cache.NewInMemory()
+		assert.NoError(t, err)
+
+		b, err := cache.NewBroadcast(cache.BroadcastConfig{
+			Local:   slowDelete{Cache: local},
+			PubSub:  redis.NewClient(&redis.Options{Addr: s.Addr()}),
+			Channel: "evict",
+		})
+		assert.NoError(t, err)
+
+		t.Cleanup(func() {
+			assert.NoError(t, b.Close())
+		})
+
+		return b
+	}
+
+	node1, node2 := newNode(), newNode()
+	assert.Eventually(t, func() bool {
+		return s.PubSubNumSub("evict")["evict"] == 2
+	}, time.Second, 10*time.Millisecond)
+
+	ctx := context.Background()
+	assert.NoError(t, node1.SetExp(ctx, "key", "value", time.Minute))
+	assert.NoError(t, node2.SetExp(ctx, "key", "value", time.Minute))
+
+	assert.NoError(t, node1.Delete(ctx, "key"))
+
+	var out string
+	assert.ErrorIs(t, node1.GetAs(ctx, "key", &out), cache.ErrKeyNotExist)
+	assert.Eventually(t, func() bool {
+		return node2.GetAs(ctx, "key", &out) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the publisher ignore its own message, so the value set right after the delete is kept
+	assert.NoError(t, node1.Delete(ctx, "key"))
+	assert.NoError(t, node1.SetExp(ctx, "key", "value2", time.Minute))
+	assert.Never(t, func() bool {
+		return node1.GetAs(ctx, "key", &out) != nil
+	}, 200*time.Millisecond, 10*time.Millisecond)
+}
@@ -15,3 +15,8 @@ type Cache interface {
 	SetExp(ctx context.Context, key string, inValue interface{}, expireDur time.Duration) error
 	Delete(ctx context.Context, key string) error
 }
+
+// Pinger is an optional interface for Cache that connect to remote server, used as health check.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
@@ -2,21 +2,75 @@ package cache
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/fastcache"
 )
 
+// DefaultInMemoryMaxBytes is also the minimum size, fastcache always allocate at least 32MB.
+const DefaultInMemoryMaxBytes = 32 * 1048576 // 32MB
+
+// expiryHeaderLen is the length of the expiry unix nano timestamp prepended into each stored value.
+const expiryHeaderLen = 8
+
+type InMemoryOpt func(*InMemoryConfig) error
+
+// WithMaxBytes set the maximum memory size, when full the oldest entries is overwritten.
+func WithMaxBytes(maxBytes int) InMemoryOpt {
+	return func(config *InMemoryConfig) error {
+		if maxBytes <= 0 {
+			return fmt.Errorf("in-memory cache max bytes must be positive, got %d", maxBytes)
+		}
+
+		config.maxBytes = maxBytes
+		return nil
+	}
+}
+
+type InMemoryConfig struct {
+	maxBytes int
+}
+
+// InMemoryStats is counted since the cache is created.
+// Entries overwritten because the cache is full is not counted as eviction, it is counted as miss on the next get.
+type InMemoryStats struct {
+	Hits         uint64
+	Misses       uint64
+	Expirations  uint64 // entries removed on get because it is expired
+	Deletes      uint64 // entries removed by Delete
+	Entries      uint64
+	BytesSize    uint64
+	MaxBytesSize uint64
+}
+
 type InMemory struct {
 	DB *fastcache.Cache
+
+	hits        uint64
+	misses      uint64
+	expirations uint64
+	deletes     uint64
 }
 
 var _ Cache = (*InMemory)(nil)
 
-func NewInMemory() (*InMemory, error) {
-	db := fastcache.New(32 * 1048576) // 32MB
+func NewInMemory(opts ...InMemoryOpt) (*InMemory, error) {
+	cfg := &InMemoryConfig{
+		maxBytes: DefaultInMemoryMaxBytes,
+	}
+
+	for _, opt := range opts {
+		err := opt(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db := fastcache.New(cfg.maxBytes)
 	return &InMemory{
 		DB: db,
 	}, nil
@@ -24,26 +78,62 @@ func NewInMemory() (*InMemory, error) {
 
 func (i *InMemory) GetAs(_ context.Context, key string, out interface{}) error {
 	result := i.DB.Get(nil, []byte(key))
-	if result == nil {
+	if len(result) < expiryHeaderLen {
+		atomic.AddUint64(&i.misses, 1)
 		return ErrKeyNotExist
 	}
 
-	return json.Unmarshal(result, out)
+	expiry := int64(binary.BigEndian.Uint64(result[:expiryHeaderLen]))
+	if expiry > 0 && time.Now().UnixNano() >= expiry {
+		i.DB.Del([]byte(key))
+		atomic.AddUint64(&i.expirations, 1)
+		atomic.AddUint64(&i.misses, 1)
+		return ErrKeyNotExist
+	}
+
+	atomic.AddUint64(&i.hits, 1)
+	return json.Unmarshal(result[expiryHeaderLen:], out)
 }
 
-// SetExp using InMemory does not support expired.
-func (i *InMemory) SetExp(_ context.Context, key string, inValue interface{}, _ time.Duration) error {
+// SetExp with expireDur <= 0 means the key never expired, but it still can be overwritten when the cache is full.
+func (i *InMemory) SetExp(_ context.Context, key string, inValue interface{}, expireDur time.Duration) error {
 	val, err := json.Marshal(inValue)
 	if err != nil {
 		err = fmt.Errorf("cannot marshal json value: %w", err)
 		return err
 	}
 
-	i.DB.Set([]byte(key), val)
+	var expiry int64
+	if expireDur > 0 {
+		expiry = time.Now().Add(expireDur).UnixNano()
+	}
+
+	stored := make([]byte, expiryHeaderLen, expiryHeaderLen+len(val))
+	binary.BigEndian.PutUint64(stored, uint64(expiry))
+	stored = append(stored, val...)
+
+	i.DB.Set([]byte(key), stored)
 	return nil
 }
 
 func (i *InMemory) Delete(ctx context.Context, key string) error {
 	i.DB.Del([]byte(key))
+	atomic.AddUint64(&i.deletes, 1)
 	return nil
 }
+
+// Stats return the snapshot of the cache counters.
+func (i *InMemory) Stats() InMemoryStats {
+	var s fastcache.Stats
+	i.DB.UpdateStats(&s)
+
+	return InMemoryStats{
+		Hits:         atomic.LoadUint64(&i.hits),
+		Misses:       atomic.LoadUint64(&i.misses),
+		Expirations:  atomic.LoadUint64(&i.expirations),
+		Deletes:      atomic.LoadUint64(&i.deletes),
+		Entries:      s.EntriesCount,
+		BytesSize:    s.BytesSize,
+		MaxBytesSize: s.MaxBytesSize,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/yusufsyaifudin/ngendika/pkg/cache"
@@ -109,3 +110,35 @@ func TestInMemory_Delete(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestInMemory_Expiry(t *testing.T) {
+	c, err := cache.NewInMemory()
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, c.SetExp(ctx, "short", "value", 10*time.Millisecond))
+	assert.NoError(t, c.SetExp(ctx, "long", "value", time.Minute))
+
+	var out string
+	assert.NoError(t, c.GetAs(ctx, "short", &out))
+	assert.Equal(t, "value", out)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.ErrorIs(t, c.GetAs(ctx, "short", &out), cache.ErrKeyNotExist)
+	assert.NoError(t, c.GetAs(ctx, "long", &out))
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Expirations)
+	assert.Equal(t, uint64(1), stats.Entries)
+}
+
+func TestNewInMemory_MaxBytes(t *testing.T) {
+	c, err := cache.NewInMemory(cache.WithMaxBytes(64 * 1048576))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(64*1048576), c.Stats().MaxBytesSize)
+
+	_, err = cache.NewInMemory(cache.WithMaxBytes(0))
+	assert.Error(t, err)
+}
@@ -20,6 +20,7 @@ type Redis struct {
 }
 
 var _ Cache = (*Redis)(nil)
+var _ Pinger = (*Redis)(nil)
 
 func NewRedis(conf RedisConfig) (*Redis, error) {
 	err := validator.New().Struct(conf)
@@ -70,3 +71,13 @@ func (r *Redis) Delete(ctx context.Context, key string) error {
 
 	return nil
 }
+
+func (r *Redis) Ping(ctx context.Context) error {
+	err := r.Conf.DB.Ping(ctx).Err()
+	if err != nil {
+		err = fmt.Errorf("error occured on redis: %w", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultTieredLoadTimeout is the timeout of L2 get when TieredConfig LoadTimeout is not set.
+const DefaultTieredLoadTimeout = 3 * time.Second
+
+type TieredConfig struct {
+	Local       Cache                 `validate:"required"` // L1, i.e: InMemory
+	Redis       redis.UniversalClient `validate:"required"` // L2 and the pub/sub of invalidation
+	Channel     string                `validate:"required"`
+	LocalTTL    time.Duration         `validate:"required"` // maximum expiry in L1, should be short
+	LoadTimeout time.Duration         `validate:"min=0"`    // timeout of L2 get on L1 miss, default DefaultTieredLoadTimeout
+}
+
+// Tiered is a local cache (L1) in front of Redis (L2).
+// Concurrent L1 misses of the same key only do one Redis get, which is not canceled by any of the callers.
+// Every Set and Delete is published to the other nodes, so they evict the key from their L1.
+type Tiered struct {
+	Conf   TieredConfig
+	local  *Broadcast
+	remote *Redis
+	group  singleflight.Group
+}
+
+var _ Cache = (*Tiered)(nil)
+var _ Pinger = (*Tiered)(nil)
+
+// NewTiered start subscribing the channel, Close must be called to stop it.
+func NewTiered(conf TieredConfig) (*Tiered, error) {
+	err := validator.New().Struct(conf)
+	if err != nil {
+		err = fmt.Errorf("error validate cache tiered: %w", err)
+		return nil, err
+	}
+
+	remote, err := NewRedis(RedisConfig{DB: conf.Redis})
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := NewBroadcast(BroadcastConfig{
+		Local:   conf.Local,
+		PubSub:  conf.Redis,
+		Channel: conf.Channel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.LoadTimeout <= 0 {
+		conf.LoadTimeout = DefaultTieredLoadTimeout
+	}
+
+	return &Tiered{
+		Conf:   conf,
+		local:  local,
+		remote: remote,
+	}, nil
+}
+
+func (t *Tiered) GetAs(ctx context.Context, key string, out interface{}) error {
+	err := t.local.GetAs(ctx, key, out)
+	if err == nil {
+		return nil
+	}
+
+	val, err, _ := t.group.Do(key, func() (interface{}, error) {
+		// the result is shared with the other callers, so it must not be canceled when the first caller is gone
+		loadCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, t.Conf.LoadTimeout)
+		defer cancel()
+
+		var raw json.RawMessage
+		_err := t.remote.GetAs(loadCtx, key, &raw)
+		if _err != nil {
+			return nil, _err
+		}
+
+		// remaining expiry in L2 is not known, so always use the L1 TTL
+		_ = t.local.SetExp(loadCtx, key, raw, t.Conf.LocalTTL)
+		return raw, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(val.(json.RawMessage), out)
+}
+
+// SetExp write to L2 first, then evict the L1 of other nodes and write into its own L1.
+// The eviction published by this node is ignored by itself, so its own L1 keep the new value.
+func (t *Tiered) SetExp(ctx context.Context, key string, inValue interface{}, expireDur time.Duration) error {
+	err := t.remote.SetExp(ctx, key, inValue, expireDur)
+	if err != nil {
+		return err
+	}
+
+	err = t.local.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	localExp := t.Conf.LocalTTL
+	if expireDur > 0 && expireDur < localExp {
+		localExp = expireDur
+	}
+
+	return t.local.SetExp(ctx, key, inValue, localExp)
+}
+
+// Delete from L2 first, so other nodes cannot get the old value from L2 after its L1 is evicted.
+func (t *Tiered) Delete(ctx context.Context, key string) error {
+	err := t.remote.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return t.local.Delete(ctx, key)
+}
+
+func (t *Tiered) Ping(ctx context.Context) error {
+	return t.remote.Ping(ctx)
+}
+
+// Close stop the subscription, it does not close the Redis client.
+func (t *Tiered) Close() error {
+	return t.local.Close()
+}
+
+// detachedContext keep the values of parent (i.e: trace span), but never canceled and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+func (d detachedContext) Value(key interface{}) interface{}     { return d.parent.Value(key) }
@@ -0,0 +1,73 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/cache"
+)
+
+func TestTiered(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	newNode := func() *cache.Tiered {
+		local, err := cache.NewInMemory()
+		assert.NoError(t, err)
+
+		c, err := cache.NewTiered(cache.TieredConfig{
+			Local:    slowDelete{Cache: local},
+			Redis:    redis.NewClient(&redis.Options{Addr: s.Addr()}),
+			Channel:  "evict",
+			LocalTTL: time.Minute,
+		})
+		assert.NoError(t, err)
+
+		t.Cleanup(func() {
+			assert.NoError(t, c.Close())
+		})
+
+		return c
+	}
+
+	node1, node2 := newNode(), newNode()
+	assert.Eventually(t, func() bool {
+		return s.PubSubNumSub("evict")["evict"] == 2
+	}, time.Second, 10*time.Millisecond)
+
+	ctx := context.Background()
+	var out string
+	assert.ErrorIs(t, node2.GetAs(ctx, "key", &out), cache.ErrKeyNotExist)
+
+	// node2 get from L2, then keep it in L1
+	assert.NoError(t, node1.SetExp(ctx, "key", "v1", time.Hour))
+	assert.NoError(t, node2.GetAs(ctx, "key", &out))
+	assert.Equal(t, "v1", out)
+
+	// set on node1 evict the L1 of node2
+	assert.NoError(t, node1.SetExp(ctx, "key", "v2", time.Hour))
+	assert.Eventually(t, func() bool {
+		return node2.GetAs(ctx, "key", &out) == nil && out == "v2"
+	}, time.Second, 10*time.Millisecond)
+
+	// node1 ignore its own eviction, so v2 is still served from its L1 after L2 is gone
+	s.Del("key")
+	assert.Never(t, func() bool {
+		return node1.GetAs(ctx, "key", &out) != nil || out != "v2"
+	}, 200*time.Millisecond, 10*time.Millisecond)
+
+	assert.NoError(t, node1.Delete(ctx, "key"))
+	assert.Eventually(t, func() bool {
+		return node2.GetAs(ctx, "key", &out) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// L2 get is not canceled by the caller, since the result is shared with the other callers
+	assert.NoError(t, node1.SetExp(ctx, "key2", "v1", time.Hour))
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.NoError(t, node2.GetAs(canceledCtx, "key2", &out))
+	assert.Equal(t, "v1", out)
+}
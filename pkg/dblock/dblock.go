@@ -0,0 +1,91 @@
+package dblock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+)
+
+type Config struct {
+	DB      *sqlx.DB `validate:"required"`
+	Dialect string   `validate:"required,oneof=postgres mysql sqlite"`
+	ID      int64    `validate:"required"` // advisory lock key, must be unique per job
+}
+
+// Lock is database advisory lock, so the background job only run on one node at a time.
+type Lock struct {
+	Config Config
+}
+
+func New(cfg Config) (*Lock, error) {
+	err := validator.Validate(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("lock config error: %w", err)
+	}
+
+	return &Lock{Config: cfg}, nil
+}
+
+// TryDo run fn while holding the lock, ok is false and fn is not run when the lock is held by other node.
+// SQLite has no advisory lock, fn is always run because SQLite is only used on single node.
+func (l *Lock) TryDo(ctx context.Context, fn func(ctx context.Context) error) (ok bool, err error) {
+	d := dialects[l.Config.Dialect]
+	if d.tryLock == "" {
+		return true, fn(ctx)
+	}
+
+	// the lock is owned by the session, so the same connection must be used to lock and unlock
+	conn, err := l.Config.DB.Connx(ctx)
+	if err != nil {
+		err = fmt.Errorf("cannot get db connection: %w", err)
+		return
+	}
+
+	defer func() {
+		if _err := conn.Close(); _err != nil && err == nil {
+			err = fmt.Errorf("cannot close db connection: %w", _err)
+		}
+	}()
+
+	var locked sql.NullBool
+	if err = conn.GetContext(ctx, &locked, d.tryLock, l.Config.ID); err != nil {
+		err = fmt.Errorf("cannot acquire lock %d: %w", l.Config.ID, err)
+		return
+	}
+
+	if !locked.Bool {
+		return
+	}
+
+	defer func() {
+		// use new context, so the lock is still released when ctx is canceled
+		if _, _err := conn.ExecContext(context.Background(), d.unlock, l.Config.ID); _err != nil && err == nil {
+			err = fmt.Errorf("cannot release lock %d: %w", l.Config.ID, _err)
+		}
+	}()
+
+	ok = true
+	err = fn(ctx)
+	return
+}
+
+type dialect struct {
+	tryLock string // with one argument of lock id, return true when acquired, empty means no lock
+	unlock  string
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		tryLock: `SELECT pg_try_advisory_lock($1);`,
+		unlock:  `SELECT pg_advisory_unlock($1);`,
+	},
+	// GET_LOCK return 1 when acquired, 0 on timeout and NULL on error
+	"mysql": {
+		tryLock: `SELECT GET_LOCK(?, 0);`,
+		unlock:  `SELECT RELEASE_LOCK(?);`,
+	},
+	"sqlite": {},
+}
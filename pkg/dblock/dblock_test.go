@@ -0,0 +1,68 @@
+package dblock_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/dblock"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLock_SQLite(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	lock, err := dblock.New(dblock.Config{DB: db, Dialect: "sqlite", ID: 1})
+	assert.NoError(t, err)
+
+	called := 0
+	ok, err := lock.TryDo(context.Background(), func(ctx context.Context) error {
+		called++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, called)
+
+	_, err = dblock.New(dblock.Config{DB: db, Dialect: "oracle", ID: 1})
+	assert.Error(t, err)
+}
+
+// TestLock_MySQL hold the lock while other session try it in the MySQL 8 server of MYSQL_TEST_DSN, i.e: root:mysql@tcp(localhost:3306)/
+// The test is skipped when MYSQL_TEST_DSN is not defined.
+func TestLock_MySQL(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not defined")
+	}
+
+	db, err := sqlx.Open("mysql", dsn)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	lock, err := dblock.New(dblock.Config{DB: db, Dialect: "mysql", ID: 1})
+	assert.NoError(t, err)
+
+	ok, err := lock.TryDo(context.Background(), func(ctx context.Context) error {
+		// other node is using other session, so it cannot get the lock
+		nested, _err := lock.TryDo(ctx, func(ctx context.Context) error {
+			t.Error("lock is acquired twice")
+			return nil
+		})
+		assert.False(t, nested)
+		return _err
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// released after done
+	ok, err = lock.TryDo(context.Background(), func(ctx context.Context) error { return nil })
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -78,6 +78,9 @@ type MulticastSendResponse struct {
 	Success     bool   `json:"success"`
 	MessageID   string `json:"message_id,omitempty"`
 	Error       string `json:"error,omitempty"`
+
+	// InvalidToken is true when FCM report the device token is unregistered.
+	InvalidToken bool `json:"invalid_token,omitempty"`
 }
 
 // MulticastBatchResponse represents the response from the FCM API.
@@ -272,10 +272,11 @@ func HandleFCMBatchResponse(tokens []string, result *messaging.BatchResponse) (o
 		}
 
 		sendResp = append(sendResp, MulticastSendResponse{
-			DeviceToken: tokens[i],
-			Success:     sendRes.Success,
-			MessageID:   sendRes.MessageID,
-			Error:       errStr,
+			DeviceToken:  tokens[i],
+			Success:      sendRes.Success,
+			MessageID:    sendRes.MessageID,
+			Error:        errStr,
+			InvalidToken: sendRes.Error != nil && messaging.IsUnregistered(sendRes.Error),
 		})
 	}
 
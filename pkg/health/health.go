@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc return nil error when the dependency is healthy.
+type CheckFunc func(ctx context.Context) error
+
+type Config struct {
+	// Checks dependency name => check function, i.e: db:allInOneDB => ping database
+	Checks map[string]CheckFunc `validate:"-"`
+
+	// Timeout for each check, default to 3 seconds.
+	Timeout time.Duration `validate:"min=0"`
+}
+
+type Health struct {
+	Config Config
+}
+
+func New(cfg Config) (*Health, error) {
+	err := validator.Validate(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+
+	if cfg.Checks == nil {
+		cfg.Checks = map[string]CheckFunc{}
+	}
+
+	return &Health{Config: cfg}, nil
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    string        `json:"status"`
+	LatencyMs float64       `json:"latency_ms"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Up return true when all dependencies is up.
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Check run all checks concurrently, each check is limited by Config.Timeout.
+func (h *Health) Check(ctx context.Context) Report {
+	start := time.Now()
+
+	wg := &sync.WaitGroup{}
+	lock := &sync.Mutex{}
+	results := make([]CheckResult, 0, len(h.Config.Checks))
+	for name, check := range h.Config.Checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+
+			result := runCheck(ctx, name, check, h.Config.Timeout)
+
+			lock.Lock()
+			results = append(results, result)
+			lock.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	// map iteration is random, make the output stable
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	status := StatusUp
+	for _, result := range results {
+		if result.Status != StatusUp {
+			status = StatusDown
+			break
+		}
+	}
+
+	return Report{
+		Status:    status,
+		LatencyMs: latencyMs(time.Since(start)),
+		Checks:    results,
+	}
+}
+
+func runCheck(ctx context.Context, name string, check CheckFunc, timeout time.Duration) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result = CheckResult{
+		Name:   name,
+		Status: StatusUp,
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status = StatusDown
+			result.Error = fmt.Sprintf("check panic: %v", r)
+		}
+
+		result.LatencyMs = latencyMs(time.Since(start))
+	}()
+
+	if check == nil {
+		return
+	}
+
+	if err := check(ctx); err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return
+}
+
+func latencyMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,59 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+)
+
+func TestHealth_Check(t *testing.T) {
+	t.Run("all up", func(t *testing.T) {
+		h, err := health.New(health.Config{
+			Checks: map[string]health.CheckFunc{
+				"db:a": func(ctx context.Context) error { return nil },
+				"db:b": func(ctx context.Context) error { return nil },
+			},
+		})
+		assert.NoError(t, err)
+
+		report := h.Check(context.Background())
+		assert.True(t, report.Up())
+		assert.Len(t, report.Checks, 2)
+		assert.Equal(t, "db:a", report.Checks[0].Name)
+	})
+
+	t.Run("one down", func(t *testing.T) {
+		h, err := health.New(health.Config{
+			Checks: map[string]health.CheckFunc{
+				"db:a":  func(ctx context.Context) error { return nil },
+				"cache": func(ctx context.Context) error { return fmt.Errorf("connection refused") },
+			},
+		})
+		assert.NoError(t, err)
+
+		report := h.Check(context.Background())
+		assert.False(t, report.Up())
+		assert.Equal(t, health.StatusDown, report.Checks[0].Status)
+		assert.Equal(t, "connection refused", report.Checks[0].Error)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		h, err := health.New(health.Config{
+			Timeout: 10 * time.Millisecond,
+			Checks: map[string]health.CheckFunc{
+				"slow": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		report := h.Check(context.Background())
+		assert.False(t, report.Up())
+	})
+}
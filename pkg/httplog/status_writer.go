@@ -0,0 +1,45 @@
+package httplog
+
+import (
+	"net/http"
+)
+
+// StatusWriter record the status code and keep the http.Flusher of the underlying writer,
+// so the streamed response is still flushed to the client.
+type StatusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+var _ http.Flusher = (*StatusWriter)(nil)
+
+func (w *StatusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *StatusWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *StatusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Code return the written status code, default to 200 as net/http does.
+func (w *StatusWriter) Code() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
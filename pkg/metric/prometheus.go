@@ -0,0 +1,218 @@
+package metric
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync"
+)
+
+const namespace = "ngendika"
+
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// Registry is the prometheus registry used by all ngendika metrics.
+// We don't use prometheus.DefaultRegisterer to make sure only known metrics exposed on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	MessagesSubmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "submitted_total",
+		Help:      "Number of message recipient submitted to the backend sender.",
+	}, []string{"app", "provider", "label"})
+
+	MessagesSuccess = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "success_total",
+		Help:      "Number of message recipient successfully delivered, taken from backend report.",
+	}, []string{"app", "provider", "label"})
+
+	MessagesFailure = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "failure_total",
+		Help:      "Number of message recipient failed to deliver, taken from backend report or backend error.",
+	}, []string{"app", "provider", "label"})
+
+	BackendSendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "backend",
+		Name:      "send_duration_seconds",
+		Help:      "Latency of backend sender sending one message.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "status"})
+
+	QueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sender_queue",
+		Name:      "depth",
+		Help:      "Number of job waiting in the sender queue.",
+	})
+
+	QueueCapacity = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sender_queue",
+		Name:      "capacity",
+		Help:      "Maximum number of job can be buffered in the sender queue.",
+	})
+
+	WorkersBusy = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sender_worker",
+		Name:      "busy",
+		Help:      "Number of sender worker currently sending a message.",
+	})
+
+	WorkersTotal = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sender_worker",
+		Name:      "total",
+		Help:      "Number of sender worker started.",
+	})
+
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookup, the hit ratio is hit / (hit + miss).",
+	}, []string{"cache", "result"})
+
+	CallbackAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "callback",
+		Name:      "attempts_total",
+		Help:      "Number of callback request attempt, status is success, retry or failed.",
+	}, []string{"event", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		MessagesSubmitted,
+		MessagesSuccess,
+		MessagesFailure,
+		BackendSendDuration,
+		QueueDepth,
+		QueueCapacity,
+		WorkersBusy,
+		WorkersTotal,
+		CacheRequests,
+		CallbackAttempts,
+	)
+}
+
+// Handler return http handler to expose all metrics in Registry using prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		Registry: Registry,
+	})
+}
+
+var dbStatsRegistered sync.Map
+
+// RegisterDBStats register connection pool stats of the database under label db_name.
+// Registering the same label twice is ignored, so it is safe to call on every setup.
+func RegisterDBStats(dbLabel string, db *sql.DB) error {
+	if db == nil {
+		return nil
+	}
+
+	if _, loaded := dbStatsRegistered.LoadOrStore(dbLabel, struct{}{}); loaded {
+		return nil
+	}
+
+	err := Registry.Register(collectors.NewDBStatsCollector(db, dbLabel))
+	if err != nil {
+		dbStatsRegistered.Delete(dbLabel)
+	}
+
+	return err
+}
+
+// CacheStats is the snapshot of the local cache counters, see cache.InMemoryStats.
+type CacheStats struct {
+	Hits         uint64
+	Misses       uint64
+	Expirations  uint64
+	Deletes      uint64
+	Entries      uint64
+	BytesSize    uint64
+	MaxBytesSize uint64
+}
+
+var cacheStatsRegistered sync.Map
+
+// RegisterCacheStats register the local cache stats under label cache.
+// Registering the same label twice is ignored, so it is safe to call on every setup.
+func RegisterCacheStats(cacheLabel string, stats func() CacheStats) error {
+	if _, loaded := cacheStatsRegistered.LoadOrStore(cacheLabel, struct{}{}); loaded {
+		return nil
+	}
+
+	err := Registry.Register(newCacheStatsCollector(cacheLabel, stats))
+	if err != nil {
+		cacheStatsRegistered.Delete(cacheLabel)
+	}
+
+	return err
+}
+
+type cacheStatsCollector struct {
+	stats func() CacheStats
+
+	hits         *prometheus.Desc
+	misses       *prometheus.Desc
+	expirations  *prometheus.Desc
+	deletes      *prometheus.Desc
+	entries      *prometheus.Desc
+	bytesSize    *prometheus.Desc
+	maxBytesSize *prometheus.Desc
+}
+
+func newCacheStatsCollector(cacheLabel string, stats func() CacheStats) *cacheStatsCollector {
+	labels := prometheus.Labels{"cache": cacheLabel}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache_local", name), help, nil, labels)
+	}
+
+	return &cacheStatsCollector{
+		stats:        stats,
+		hits:         desc("hits_total", "Number of get found in the local cache."),
+		misses:       desc("misses_total", "Number of get not found or expired in the local cache."),
+		expirations:  desc("expirations_total", "Number of expired entries removed from the local cache."),
+		deletes:      desc("deletes_total", "Number of entries deleted (evicted) from the local cache."),
+		entries:      desc("entries", "Number of entries in the local cache."),
+		bytesSize:    desc("bytes", "Current memory size of the local cache."),
+		maxBytesSize: desc("max_bytes", "Maximum memory size of the local cache."),
+	}
+}
+
+func (c *cacheStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.expirations
+	ch <- c.deletes
+	ch <- c.entries
+	ch <- c.bytesSize
+	ch <- c.maxBytesSize
+}
+
+func (c *cacheStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.expirations, prometheus.CounterValue, float64(s.Expirations))
+	ch <- prometheus.MustNewConstMetric(c.deletes, prometheus.CounterValue, float64(s.Deletes))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries))
+	ch <- prometheus.MustNewConstMetric(c.bytesSize, prometheus.GaugeValue, float64(s.BytesSize))
+	ch <- prometheus.MustNewConstMetric(c.maxBytesSize, prometheus.GaugeValue, float64(s.MaxBytesSize))
+}
@@ -0,0 +1,339 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"regexp"
+	"time"
+)
+
+// DefaultTable is the table to save applied migrations of all services in one database resource.
+const DefaultTable = "schema_migrations"
+
+var regxTableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// lockID is used as advisory lock key, so only one migration can run in the same database at a time.
+const lockID = 7210431963
+
+type Config struct {
+	DB         *sqlx.DB    `validate:"required"`
+	Dialect    string      `validate:"required,oneof=postgres mysql sqlite"`
+	Service    string      `validate:"required"` // service label, i.e: app, serviceProvider
+	Migrations []Migration `validate:"required,min=1"`
+	Table      string      `validate:"-"` // default DefaultTable
+}
+
+type Migrator struct {
+	Config Config
+}
+
+// Status of one migration, AppliedAt is zero if not applied yet.
+type Status struct {
+	Version   string
+	AppliedAt time.Time
+}
+
+func New(cfg Config) (*Migrator, error) {
+	err := validator.Validate(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("migration config error: %w", err)
+	}
+
+	if cfg.Table == "" {
+		cfg.Table = DefaultTable
+	}
+
+	// table name is written directly in the query, make sure it is safe
+	if !regxTableName.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("migration config error: invalid table name '%s'", cfg.Table)
+	}
+
+	return &Migrator{Config: cfg}, nil
+}
+
+// Up apply the pending migrations, limit <= 0 means apply all.
+func (m *Migrator) Up(ctx context.Context, limit int) (applied []string, err error) {
+	err = m.withLock(ctx, func(conn *sqlx.Conn) error {
+		appliedMap, _err := m.applied(ctx, conn)
+		if _err != nil {
+			return _err
+		}
+
+		for _, migration := range m.Config.Migrations {
+			if limit > 0 && len(applied) >= limit {
+				break
+			}
+
+			if _, exist := appliedMap[migration.Version]; exist {
+				continue
+			}
+
+			if _err = m.up(ctx, conn, migration); _err != nil {
+				return _err
+			}
+
+			applied = append(applied, migration.Version)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// Down rollback the applied migrations from the latest one, limit <= 0 means rollback 1 migration.
+func (m *Migrator) Down(ctx context.Context, limit int) (rolledBack []string, err error) {
+	if limit <= 0 {
+		limit = 1
+	}
+
+	err = m.withLock(ctx, func(conn *sqlx.Conn) error {
+		var _err error
+		rolledBack, _err = m.down(ctx, conn, limit)
+		return _err
+	})
+
+	return
+}
+
+// Redo rollback the latest applied migration then apply it again.
+func (m *Migrator) Redo(ctx context.Context) (version string, err error) {
+	err = m.withLock(ctx, func(conn *sqlx.Conn) error {
+		rolledBack, _err := m.down(ctx, conn, 1)
+		if _err != nil {
+			return _err
+		}
+
+		if len(rolledBack) <= 0 {
+			return fmt.Errorf("no applied migration to redo on service '%s'", m.Config.Service)
+		}
+
+		version = rolledBack[0]
+		for _, migration := range m.Config.Migrations {
+			if migration.Version == version {
+				return m.up(ctx, conn, migration)
+			}
+		}
+
+		return fmt.Errorf("migration '%s' not found", version)
+	})
+
+	return
+}
+
+// Status return all known migrations and the applied time.
+func (m *Migrator) Status(ctx context.Context) (statuses []Status, err error) {
+	err = m.withLock(ctx, func(conn *sqlx.Conn) error {
+		appliedMap, _err := m.applied(ctx, conn)
+		if _err != nil {
+			return _err
+		}
+
+		statuses = make([]Status, 0, len(m.Config.Migrations))
+		for _, migration := range m.Config.Migrations {
+			statuses = append(statuses, Status{
+				Version:   migration.Version,
+				AppliedAt: appliedMap[migration.Version],
+			})
+		}
+
+		return nil
+	})
+
+	return
+}
+
+func (m *Migrator) up(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	return m.inTx(ctx, conn, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return fmt.Errorf("apply migration '%s' error: %w", migration.Version, err)
+		}
+
+		query := tx.Rebind(fmt.Sprintf(`INSERT INTO %s (service, version, applied_at) VALUES (?, ?, ?);`, m.Config.Table))
+		_, err := tx.ExecContext(ctx, query, m.Config.Service, migration.Version, time.Now().UTC().UnixMicro())
+		if err != nil {
+			return fmt.Errorf("save migration '%s' error: %w", migration.Version, err)
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) down(ctx context.Context, conn *sqlx.Conn, limit int) (rolledBack []string, err error) {
+	appliedMap, err := m.applied(ctx, conn)
+	if err != nil {
+		return
+	}
+
+	for i := len(m.Config.Migrations) - 1; i >= 0 && len(rolledBack) < limit; i-- {
+		migration := m.Config.Migrations[i]
+		if _, exist := appliedMap[migration.Version]; !exist {
+			continue
+		}
+
+		err = m.inTx(ctx, conn, func(tx *sqlx.Tx) error {
+			if migration.Down != "" {
+				if _, _err := tx.ExecContext(ctx, migration.Down); _err != nil {
+					return fmt.Errorf("rollback migration '%s' error: %w", migration.Version, _err)
+				}
+			}
+
+			query := tx.Rebind(fmt.Sprintf(`DELETE FROM %s WHERE service = ? AND version = ?;`, m.Config.Table))
+			if _, _err := tx.ExecContext(ctx, query, m.Config.Service, migration.Version); _err != nil {
+				return fmt.Errorf("delete migration '%s' error: %w", migration.Version, _err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		rolledBack = append(rolledBack, migration.Version)
+	}
+
+	return
+}
+
+// applied return version => applied time of this service.
+func (m *Migrator) applied(ctx context.Context, conn *sqlx.Conn) (map[string]time.Time, error) {
+	type row struct {
+		Version   string `db:"version"`
+		AppliedAt int64  `db:"applied_at"`
+	}
+
+	rows := make([]row, 0)
+	query := conn.Rebind(fmt.Sprintf(`SELECT version, applied_at FROM %s WHERE service = ?;`, m.Config.Table))
+	err := conn.SelectContext(ctx, &rows, query, m.Config.Service)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get applied migrations: %w", err)
+	}
+
+	out := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		out[r.Version] = time.UnixMicro(r.AppliedAt).UTC()
+	}
+
+	return out, nil
+}
+
+// withLock run fn using single connection that hold the lock, so concurrent run wait until the lock released.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) (err error) {
+	conn, err := m.Config.DB.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get db connection: %w", err)
+	}
+
+	defer func() {
+		if _err := conn.Close(); _err != nil && err == nil {
+			err = fmt.Errorf("cannot close db connection: %w", _err)
+		}
+	}()
+
+	d := dialects[m.Config.Dialect]
+	if d.getTimeout != "" {
+		// the statement timeout of the pool must not cancel waiting the lock or long DDL,
+		// restore it afterward because the connection is returned to the pool
+		var timeout string
+		if err = conn.GetContext(ctx, &timeout, d.getTimeout); err != nil {
+			return fmt.Errorf("cannot get statement timeout: %w", err)
+		}
+
+		if _, err = conn.ExecContext(ctx, d.setTimeout, "0"); err != nil {
+			return fmt.Errorf("cannot disable statement timeout: %w", err)
+		}
+
+		defer func() {
+			if _, _err := conn.ExecContext(context.Background(), d.setTimeout, timeout); _err != nil && err == nil {
+				err = fmt.Errorf("cannot restore statement timeout: %w", _err)
+			}
+		}()
+	}
+
+	if d.lock != "" {
+		if _, err = conn.ExecContext(ctx, d.lock, lockID); err != nil {
+			return fmt.Errorf("cannot acquire migration lock: %w", err)
+		}
+
+		defer func() {
+			// use new context, so the lock is still released when ctx is canceled
+			if _, _err := conn.ExecContext(context.Background(), d.unlock, lockID); _err != nil && err == nil {
+				err = fmt.Errorf("cannot release migration lock: %w", _err)
+			}
+		}()
+	}
+
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf(d.createTable, m.Config.Table)); err != nil {
+		return fmt.Errorf("cannot create table %s: %w", m.Config.Table, err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) inTx(ctx context.Context, conn *sqlx.Conn, fn func(tx *sqlx.Tx) error) error {
+	tx, err := conn.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		if _err := tx.Rollback(); _err != nil {
+			err = fmt.Errorf("%w: rollback error: %s", err, _err)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type dialect struct {
+	createTable string // with %s as table name
+	lock        string // with one argument of lock id, empty means no lock
+	unlock      string
+	getTimeout  string // select the statement timeout of the session, empty means no timeout
+	setTimeout  string // with one argument of the timeout as string, 0 means no timeout
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+    service VARCHAR NOT NULL,
+    version VARCHAR NOT NULL,
+    applied_at BIGINT NOT NULL,
+    PRIMARY KEY (service, version)
+);`,
+		lock:   `SELECT pg_advisory_lock($1);`,
+		unlock: `SELECT pg_advisory_unlock($1);`,
+		// SET does not accept bind parameter, set_config is used instead
+		getTimeout: `SELECT current_setting('statement_timeout');`,
+		setTimeout: `SELECT set_config('statement_timeout', $1, false);`,
+	},
+	// MySQL implicitly commit on DDL, so the failed migration may be partially applied.
+	// GET_LOCK is server wide, so migrations of other databases in the same server also wait the lock.
+	"mysql": {
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+    service VARCHAR(64) NOT NULL,
+    version VARCHAR(255) NOT NULL,
+    applied_at BIGINT NOT NULL,
+    PRIMARY KEY (service, version)
+);`,
+		lock:   `SELECT GET_LOCK(?, -1);`,
+		unlock: `SELECT RELEASE_LOCK(?);`,
+		// max_execution_time only limits SELECT, including the GET_LOCK above
+		getTimeout: `SELECT @@SESSION.max_execution_time;`,
+		setTimeout: `SET SESSION max_execution_time = CAST(? AS UNSIGNED);`,
+	},
+	// SQLite has no advisory lock, the database file is locked by the write transaction instead.
+	"sqlite": {
+		createTable: `CREATE TABLE IF NOT EXISTS %s (
+    service VARCHAR NOT NULL,
+    version VARCHAR NOT NULL,
+    applied_at BIGINT NOT NULL,
+    PRIMARY KEY (service, version)
+);`,
+	},
+}
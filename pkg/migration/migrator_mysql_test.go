@@ -0,0 +1,89 @@
+package migration_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+)
+
+// TestMigrator_MySQLLock run concurrent migrations in the MySQL 8 server of MYSQL_TEST_DSN, i.e: root:mysql@tcp(localhost:3306)/
+// The test is skipped when MYSQL_TEST_DSN is not defined.
+func TestMigrator_MySQLLock(t *testing.T) {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not defined")
+	}
+
+	ctx := context.Background()
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_TEST_DSN: %s", err)
+	}
+
+	server, err := sqlx.Open("mysql", cfg.FormatDSN())
+	assert.NoError(t, err)
+	defer server.Close()
+
+	cfg.DBName = fmt.Sprintf("ngendika_migration_%d", time.Now().UnixNano())
+	_, err = server.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s;", cfg.DBName))
+	if err != nil {
+		t.Fatalf("cannot create test database: %s", err)
+	}
+
+	defer func() { _, _ = server.ExecContext(ctx, fmt.Sprintf("DROP DATABASE %s;", cfg.DBName)) }()
+
+	db, err := sqlx.Open("mysql", cfg.FormatDSN())
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// without the lock, the second migration fail because the table is already exist
+	migrations := []migration.Migration{
+		{Version: "1_create_a", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+		{Version: "2_slow", Up: "DO SLEEP(0.2);"},
+	}
+
+	const nodes = 3
+	applied := make([][]string, nodes)
+	errs := make([]error, nodes)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < nodes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			migrator, _err := migration.New(migration.Config{DB: db, Dialect: "mysql", Service: "test", Migrations: migrations})
+			if _err != nil {
+				errs[i] = _err
+				return
+			}
+
+			applied[i], errs[i] = migrator.Up(ctx, 0)
+		}(i)
+	}
+
+	wg.Wait()
+
+	total := 0
+	for i := 0; i < nodes; i++ {
+		assert.NoError(t, errs[i])
+		total += len(applied[i])
+	}
+
+	assert.Equal(t, len(migrations), total)
+
+	migrator, err := migration.New(migration.Config{DB: db, Dialect: "mysql", Service: "test", Migrations: migrations})
+	assert.NoError(t, err)
+
+	rolledBack, err := migrator.Down(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2_slow", "1_create_a"}, rolledBack)
+}
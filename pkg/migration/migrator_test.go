@@ -0,0 +1,96 @@
+package migration_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMigrator_SQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+
+	// each connection of :memory: is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations := []migration.Migration{
+		{Version: "1_create_a", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+		{Version: "2_create_b", Up: "CREATE TABLE b (id INT);", Down: "DROP TABLE b;"},
+		{Version: "3_create_c", Up: "CREATE TABLE c (id INT);"}, // without down
+	}
+
+	migrator, err := migration.New(migration.Config{DB: db, Dialect: "sqlite", Service: "test", Migrations: migrations})
+	assert.NoError(t, err)
+
+	tableExist := func(name string) bool {
+		var count int
+		err := db.GetContext(ctx, &count, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?;`, name)
+		assert.NoError(t, err)
+		return count > 0
+	}
+
+	applied, err := migrator.Up(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1_create_a", "2_create_b"}, applied)
+	assert.True(t, tableExist("b"))
+	assert.False(t, tableExist("c"))
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+	assert.False(t, statuses[1].AppliedAt.IsZero())
+	assert.True(t, statuses[2].AppliedAt.IsZero())
+
+	// applied migration is skipped
+	applied, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3_create_c"}, applied)
+
+	// migration without down only remove the applied version
+	rolledBack, err := migrator.Down(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3_create_c", "2_create_b"}, rolledBack)
+	assert.True(t, tableExist("a"))
+	assert.False(t, tableExist("b"))
+	assert.True(t, tableExist("c"))
+
+	version, err := migrator.Redo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1_create_a", version)
+	assert.True(t, tableExist("a"))
+
+	statuses, err = migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.False(t, statuses[0].AppliedAt.IsZero())
+	assert.True(t, statuses[1].AppliedAt.IsZero())
+	assert.True(t, statuses[2].AppliedAt.IsZero())
+
+	// failed migration is rolled back and not saved as applied
+	failed, err := migration.New(migration.Config{DB: db, Dialect: "sqlite", Service: "test", Migrations: []migration.Migration{
+		{Version: "1_create_a", Up: "CREATE TABLE a (id INT);"},
+		{Version: "2_invalid", Up: "CREATE TABLE d (id INT); NOT SQL;"},
+	}})
+	assert.NoError(t, err)
+
+	_, err = failed.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.False(t, tableExist("d"))
+}
+
+func TestNew_InvalidTable(t *testing.T) {
+	_, err := migration.New(migration.Config{
+		DB:         &sqlx.DB{},
+		Dialect:    "sqlite",
+		Service:    "test",
+		Migrations: []migration.Migration{{Version: "1"}},
+		Table:      "migrations; DROP TABLE a",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	markerPrefix = "-- +migrate"
+	markerUp     = "up"
+	markerDown   = "down"
+)
+
+// Migration is one SQL file, the Up and Down section is separated using "-- +migrate Up" and "-- +migrate Down".
+type Migration struct {
+	Version string // file name without .sql extension, i.e: 1595833942_create_apps_table
+	Up      string
+	Down    string
+}
+
+// Load read all .sql files in the dir, sorted by the file name.
+func Load(fsys fs.FS, dir string) (migrations []Migration, err error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		err = fmt.Errorf("cannot read migration dir '%s': %w", dir, err)
+		return
+	}
+
+	migrations = make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		content, _err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if _err != nil {
+			err = fmt.Errorf("cannot read migration file '%s': %w", entry.Name(), _err)
+			return
+		}
+
+		migration, _err := Parse(strings.TrimSuffix(entry.Name(), ".sql"), content)
+		if _err != nil {
+			err = _err
+			return
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return
+}
+
+// Parse split the content into Up and Down section.
+func Parse(version string, content []byte) (migration Migration, err error) {
+	var up, down strings.Builder
+	var current *strings.Builder
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, markerPrefix) {
+			fields := strings.Fields(strings.TrimPrefix(trimmed, markerPrefix))
+			if len(fields) <= 0 {
+				err = fmt.Errorf("migration '%s': empty marker", version)
+				return
+			}
+
+			switch strings.ToLower(fields[0]) {
+			case markerUp:
+				current = &up
+			case markerDown:
+				current = &down
+			default:
+				// other markers (i.e: StatementBegin) is not needed since the whole section executed at once
+			}
+
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+
+	if err = scanner.Err(); err != nil {
+		err = fmt.Errorf("migration '%s': cannot read content: %w", version, err)
+		return
+	}
+
+	migration = Migration{
+		Version: version,
+		Up:      strings.TrimSpace(up.String()),
+		Down:    strings.TrimSpace(down.String()),
+	}
+
+	if migration.Up == "" {
+		err = fmt.Errorf("migration '%s': no '-- +migrate Up' section", version)
+		return
+	}
+
+	return
+}
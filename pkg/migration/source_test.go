@@ -0,0 +1,47 @@
+package migration_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/pkg/migration"
+)
+
+func TestParse(t *testing.T) {
+	m, err := migration.Parse("1_create", []byte(`
+-- +migrate Up
+-- comment
+CREATE TABLE a (id INT);
+
+-- +migrate Down
+DROP TABLE a;
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "-- comment\nCREATE TABLE a (id INT);", m.Up)
+	assert.Equal(t, "DROP TABLE a;", m.Down)
+
+	_, err = migration.Parse("2_empty", []byte(`DROP TABLE a;`))
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/2_second.sql": {Data: []byte("-- +migrate Up\nSELECT 2;")},
+		"dir/1_first.sql":  {Data: []byte("-- +migrate Up\nSELECT 1;")},
+		"dir/README.md":    {Data: []byte("not migration")},
+	}
+
+	migrations, err := migration.Load(fsys, "dir")
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, "1_first", migrations[0].Version)
+	assert.Equal(t, "2_second", migrations[1].Version)
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := migration.Load(assets.Migrations, "migrations/postgres/apprepo")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+}
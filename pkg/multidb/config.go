@@ -1,5 +1,7 @@
 package multidb
 
+import "time"
+
 type Driver string
 
 func (d Driver) String() string {
@@ -8,19 +10,37 @@ func (d Driver) String() string {
 
 const (
 	Postgres Driver = "postgres"
+	MySQL    Driver = "mysql"
+	SQLite   Driver = "sqlite"
 )
 
 type GoSqlDb struct {
 	Debug bool
 	DSN   string // Data Source Name
+
+	// Replicas is DSN of read replicas, read query is routed to the healthy replicas
+	Replicas             []string
+	ReplicaCheckInterval time.Duration // default DefaultReplicaCheckInterval
+
+	// Connection pool of the primary and each replica, zero means using database/sql default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// StatementTimeout is set as server setting in DSN, zero means no timeout.
+	// Postgres using statement_timeout, MySQL using max_execution_time which only applies to SELECT.
+	StatementTimeout time.Duration
 }
 
 type DatabaseResource struct {
 	Disable bool
-	Driver  Driver // postgres, etc
+	Driver  Driver // postgres, mysql, sqlite
 
 	// per driver configuration
 	Postgres GoSqlDb
+	Mysql    GoSqlDb
+	Sqlite   GoSqlDb
 }
 
 type DatabaseResources map[string]DatabaseResource
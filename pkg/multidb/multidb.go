@@ -7,5 +7,15 @@ import (
 
 type MultiDB interface {
 	GetSqlx(driver Driver, key string) (*sqlx.DB, error)
+
+	// GetReader return the connection for read only query, routed to the healthy replicas or the primary.
+	// Data written to the primary may not be read immediately because of the replication lag.
+	GetReader(driver Driver, key string) (sqlx.QueryerContext, error)
+
+	// Connections return all enabled primary connection, db key name => connection.
+	Connections() map[string]*sqlx.DB
+
+	// Replicas return all read replica connection, replica name (<db key name>_replica<index>) => connection.
+	Replicas() map[string]*sqlx.DB
 	io.Closer
 }
@@ -6,7 +6,9 @@ import (
 	sqldblogger "github.com/simukti/sqldb-logger"
 	"strings"
 
+	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jmoiron/sqlx"
@@ -17,11 +19,13 @@ type SqlDbConnMakerConfig struct {
 }
 
 type SqlDbConnMaker struct {
-	conf     DatabaseResources
-	disabled map[string]struct{} // list of disabled databases, using struct for minimal memory footprint
-	dbSQL    map[string]*sqlx.DB // db key name => real connection
-	dbDriver map[string]Driver   // db key name => driver name
-	closer   []Closer
+	conf      DatabaseResources
+	disabled  map[string]struct{} // list of disabled databases, using struct for minimal memory footprint
+	dbSQL     map[string]*sqlx.DB // db key name => real connection
+	dbReader  map[string]*Reader  // db key name => read only connection
+	dbReplica map[string]*sqlx.DB // replica name => real connection
+	dbDriver  map[string]Driver   // db key name => driver name
+	closer    []Closer
 }
 
 var _ MultiDB = (*SqlDbConnMaker)(nil)
@@ -34,11 +38,13 @@ func NewSqlDbConnMaker(conf SqlDbConnMakerConfig) (*SqlDbConnMaker, error) {
 	}
 
 	instance := &SqlDbConnMaker{
-		conf:     conf.Config,
-		disabled: make(map[string]struct{}),
-		dbSQL:    make(map[string]*sqlx.DB),
-		dbDriver: make(map[string]Driver),
-		closer:   make([]Closer, 0),
+		conf:      conf.Config,
+		disabled:  make(map[string]struct{}),
+		dbSQL:     make(map[string]*sqlx.DB),
+		dbReader:  make(map[string]*Reader),
+		dbReplica: make(map[string]*sqlx.DB),
+		dbDriver:  make(map[string]Driver),
+		closer:    make([]Closer, 0),
 	}
 
 	err = instance.connect()
@@ -55,6 +61,9 @@ func NewSqlDbConnMaker(conf SqlDbConnMakerConfig) (*SqlDbConnMaker, error) {
 }
 
 func (i *SqlDbConnMaker) GetSqlx(driver Driver, key string) (*sqlx.DB, error) {
+	// label is saved in lower case during connect
+	key = strings.TrimSpace(strings.ToLower(key))
+
 	_, exists := i.disabled[key]
 	if exists {
 		return nil, fmt.Errorf("db with key '%s' is disabled", key)
@@ -73,6 +82,34 @@ func (i *SqlDbConnMaker) GetSqlx(driver Driver, key string) (*sqlx.DB, error) {
 	return nil, fmt.Errorf("db key '%s' not using driver %s", key, driver)
 }
 
+func (i *SqlDbConnMaker) GetReader(driver Driver, key string) (sqlx.QueryerContext, error) {
+	_, err := i.GetSqlx(driver, key)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strings.TrimSpace(strings.ToLower(key))
+	return i.dbReader[key], nil
+}
+
+func (i *SqlDbConnMaker) Connections() map[string]*sqlx.DB {
+	conns := make(map[string]*sqlx.DB, len(i.dbSQL))
+	for key, conn := range i.dbSQL {
+		conns[key] = conn
+	}
+
+	return conns
+}
+
+func (i *SqlDbConnMaker) Replicas() map[string]*sqlx.DB {
+	conns := make(map[string]*sqlx.DB, len(i.dbReplica))
+	for name, conn := range i.dbReplica {
+		conns[name] = conn
+	}
+
+	return conns
+}
+
 func (i *SqlDbConnMaker) Close() error {
 	var err error
 	for _, c := range i.closer {
@@ -102,35 +139,26 @@ func (i *SqlDbConnMaker) connect() error {
 			continue
 		}
 
-		var (
-			sqlxConn *sqlx.DB
-			err      error
-		)
-
+		// driver is the registered name of database/sql driver, it also used by sqlx to choose the bind type
+		driver := dbConfig.Driver.String()
+		var goSqlDb GoSqlDb
 		switch dbConfig.Driver {
 		case Postgres:
-			driver := dbConfig.Driver.String()
-			dsn := dbConfig.Postgres.DSN
-
-			db, err := sql.Open(driver, dsn)
-			if err != nil {
-				err = fmt.Errorf("cannot open db connection '%s': %w", dbLabel, err)
-				return err
-			}
+			goSqlDb = dbConfig.Postgres
 
-			if dbConfig.Postgres.Debug {
-				db = sqldblogger.OpenDriver(dsn, db.Driver(), &QueryLogger{}, sqldblogger.WithConnectionIDFieldname(dbLabel))
-			}
+		case MySQL:
+			goSqlDb = dbConfig.Mysql
 
-			sqlxConn = sqlx.NewDb(db, dbConfig.Driver.String())
+		case SQLite:
+			driver = "sqlite3"
+			goSqlDb = dbConfig.Sqlite
 
 		default:
-			err = fmt.Errorf("not supported driver '%s'", dbConfig.Driver)
-			return err
+			return fmt.Errorf("not supported driver '%s'", dbConfig.Driver)
 		}
 
+		sqlxConn, err := open(dbConfig.Driver, driver, goSqlDb.DSN, dbLabel, goSqlDb)
 		if err != nil {
-			err = fmt.Errorf("error connecting to database %s: %w", dbLabel, err)
 			return err
 		}
 
@@ -138,7 +166,64 @@ func (i *SqlDbConnMaker) connect() error {
 		i.dbSQL[dbLabel] = sqlxConn
 		i.dbDriver[dbLabel] = dbConfig.Driver
 		i.closer = append(i.closer, newNamedCloser(dbLabel, sqlxConn))
+
+		replicas := make([]*replica, 0, len(goSqlDb.Replicas))
+		for idx, dsn := range goSqlDb.Replicas {
+			name := fmt.Sprintf("%s_replica%d", dbLabel, idx)
+			replicaConn, err := open(dbConfig.Driver, driver, dsn, name, goSqlDb)
+			if err != nil {
+				return err
+			}
+
+			replicas = append(replicas, &replica{name: name, db: replicaConn})
+			i.dbReplica[name] = replicaConn
+			i.closer = append(i.closer, newNamedCloser(name, replicaConn))
+		}
+
+		// closer is called in order, health check must be stopped before the replica connection is closed
+		reader := newReader(sqlxConn, replicas, goSqlDb.ReplicaCheckInterval)
+		i.dbReader[dbLabel] = reader
+		i.closer = append([]Closer{newNamedCloser(fmt.Sprintf("%s_reader", dbLabel), reader)}, i.closer...)
 	}
 
 	return nil
 }
+
+// open does not connect to the database, the connection is made on the first query.
+// The dsn is passed separately, because replica use the same connection pool config but different dsn.
+func open(driver Driver, sqlDriver, dsn, name string, conf GoSqlDb) (*sqlx.DB, error) {
+	dsn, err := withStatementTimeout(driver, dsn, conf.StatementTimeout)
+	if err != nil {
+		err = fmt.Errorf("cannot prepare db connection '%s': %w", name, err)
+		return nil, err
+	}
+
+	db, err := sql.Open(sqlDriver, dsn)
+	if err != nil {
+		err = fmt.Errorf("cannot open db connection '%s': %w", name, err)
+		return nil, err
+	}
+
+	if conf.Debug {
+		db = sqldblogger.OpenDriver(dsn, db.Driver(), &QueryLogger{}, sqldblogger.WithConnectionIDFieldname(name))
+	}
+
+	// zero value is not set, because zero max idle connection in database/sql means no idle connection
+	if conf.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(conf.MaxOpenConns)
+	}
+
+	if conf.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(conf.MaxIdleConns)
+	}
+
+	if conf.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(conf.ConnMaxLifetime)
+	}
+
+	if conf.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(conf.ConnMaxIdleTime)
+	}
+
+	return sqlx.NewDb(db, sqlDriver), nil
+}
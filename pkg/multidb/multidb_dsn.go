@@ -0,0 +1,54 @@
+package multidb
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// withStatementTimeout return the DSN with statement timeout as the server setting of each connection.
+// migration.Migrator disable it on its connection, so waiting the migration lock and long DDL is not canceled.
+func withStatementTimeout(driver Driver, dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+
+	ms := fmt.Sprint(timeout.Milliseconds())
+	switch driver {
+	case Postgres:
+		// lib/pq send unknown key as run-time parameter on connection startup
+		if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return "", fmt.Errorf("cannot parse postgres url: %w", err)
+			}
+
+			query := u.Query()
+			query.Set("statement_timeout", ms)
+			u.RawQuery = query.Encode()
+			return u.String(), nil
+		}
+
+		return fmt.Sprintf("%s statement_timeout=%s", strings.TrimSpace(dsn), ms), nil
+
+	case MySQL:
+		// go-sql-driver/mysql set unknown param as system variable on connect
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse mysql dsn: %w", err)
+		}
+
+		if cfg.Params == nil {
+			cfg.Params = make(map[string]string)
+		}
+
+		cfg.Params["max_execution_time"] = ms
+		return cfg.FormatDSN(), nil
+
+	default:
+		return "", fmt.Errorf("statement timeout is not supported on driver '%s'", driver)
+	}
+}
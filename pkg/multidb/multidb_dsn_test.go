@@ -0,0 +1,33 @@
+package multidb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStatementTimeout(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		dsn    string
+		want   string
+	}{
+		{Postgres, "user=postgres dbname=ngendika ", "user=postgres dbname=ngendika statement_timeout=1500"},
+		{Postgres, "postgres://postgres@localhost/ngendika?sslmode=disable", "postgres://postgres@localhost/ngendika?sslmode=disable&statement_timeout=1500"},
+		{MySQL, "root:mysql@tcp(localhost:3306)/ngendika", "root:mysql@tcp(localhost:3306)/ngendika?max_execution_time=1500"},
+	}
+
+	for _, tt := range tests {
+		got, err := withStatementTimeout(tt.driver, tt.dsn, 1500*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := withStatementTimeout(SQLite, "file:ngendika.db", time.Second)
+	assert.Error(t, err)
+
+	got, err := withStatementTimeout(SQLite, "file:ngendika.db", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "file:ngendika.db", got)
+}
@@ -0,0 +1,137 @@
+package multidb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yusufsyaifudin/ylog"
+)
+
+// DefaultReplicaCheckInterval is the interval to ping each replica when not configured.
+const DefaultReplicaCheckInterval = 5 * time.Second
+
+type replica struct {
+	name    string
+	db      *sqlx.DB
+	healthy int32 // 1 when the last ping success
+}
+
+// Reader route each read query to the healthy replicas using round-robin,
+// and fallback to the primary when no replica is healthy.
+// Replica that failed to ping is skipped until the next ping success.
+type Reader struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	next     uint64
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+var _ sqlx.QueryerContext = (*Reader)(nil)
+
+// newReader start the health check when replicas is not empty, Close must be called to stop it.
+// All replicas is assumed healthy until the first check.
+func newReader(primary *sqlx.DB, replicas []*replica, interval time.Duration) *Reader {
+	if interval <= 0 {
+		interval = DefaultReplicaCheckInterval
+	}
+
+	r := &Reader{
+		primary:  primary,
+		replicas: replicas,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+
+	for _, rep := range replicas {
+		atomic.StoreInt32(&rep.healthy, 1)
+	}
+
+	if len(replicas) > 0 {
+		r.wg.Add(1)
+		go r.checkLoop()
+	}
+
+	return r
+}
+
+func (r *Reader) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.pick().QueryContext(ctx, query, args...)
+}
+
+func (r *Reader) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return r.pick().QueryxContext(ctx, query, args...)
+}
+
+func (r *Reader) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return r.pick().QueryRowxContext(ctx, query, args...)
+}
+
+// pick return the next healthy replica, or the primary when all replicas are unhealthy.
+func (r *Reader) pick() *sqlx.DB {
+	n := len(r.replicas)
+	if n <= 0 {
+		return r.primary
+	}
+
+	start := atomic.AddUint64(&r.next, 1)
+	for i := 0; i < n; i++ {
+		rep := r.replicas[(start+uint64(i))%uint64(n)]
+		if atomic.LoadInt32(&rep.healthy) == 1 {
+			return rep.db
+		}
+	}
+
+	return r.primary
+}
+
+func (r *Reader) checkLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.check()
+
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reader) check() {
+	for _, rep := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		err := rep.db.PingContext(ctx)
+		cancel()
+
+		var healthy int32
+		if err == nil {
+			healthy = 1
+		}
+
+		// only log when the state changed, so unhealthy replica does not flood the log
+		if atomic.SwapInt32(&rep.healthy, healthy) != healthy {
+			if err != nil {
+				ylog.Error(context.Background(), "db replica is unhealthy", ylog.KV("replica", rep.name), ylog.KV("error", err))
+			} else {
+				ylog.Info(context.Background(), "db replica is healthy", ylog.KV("replica", rep.name))
+			}
+		}
+	}
+}
+
+// Close stop the health check, it does not close the replica connections.
+func (r *Reader) Close() error {
+	close(r.stop)
+	r.wg.Wait()
+	return nil
+}
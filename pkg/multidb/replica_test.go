@@ -0,0 +1,38 @@
+package multidb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader_pick(t *testing.T) {
+	open := func() *sqlx.DB {
+		db, err := sqlx.Open("sqlite3", ":memory:")
+		assert.NoError(t, err)
+		return db
+	}
+
+	primary, healthy, unhealthy := open(), open(), open()
+	defer primary.Close()
+	defer healthy.Close()
+	_ = unhealthy.Close() // ping on closed db always fail
+
+	reader := newReader(primary, []*replica{
+		{name: "healthy", db: healthy},
+		{name: "unhealthy", db: unhealthy},
+	}, time.Hour)
+	defer reader.Close()
+
+	reader.check()
+	for i := 0; i < 4; i++ {
+		assert.Same(t, healthy, reader.pick())
+	}
+
+	// fallback to primary when no replica is healthy
+	_ = healthy.Close()
+	reader.check()
+	assert.Same(t, primary, reader.pick())
+}
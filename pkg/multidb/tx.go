@@ -0,0 +1,52 @@
+package multidb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxBeginner is implemented by *sqlx.DB.
+type TxBeginner interface {
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+}
+
+// InTx run fn inside new transaction, commit when fn return nil and rollback otherwise.
+// When conn is not TxBeginner, i.e: it is already *sqlx.Tx, fn use conn directly
+// so it becomes part of the outer transaction.
+func InTx(ctx context.Context, conn sqlx.ExtContext, fn func(conn sqlx.ExtContext) error) (err error) {
+	beginner, ok := conn.(TxBeginner)
+	if !ok {
+		return fn(conn)
+	}
+
+	tx, err := beginner.BeginTxx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("cannot begin transaction: %w", err)
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			if _err := tx.Rollback(); _err != nil {
+				err = fmt.Errorf("%w: rollback error: %s", err, _err)
+			}
+
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			err = fmt.Errorf("cannot commit transaction: %w", err)
+		}
+	}()
+
+	err = fn(tx)
+	return
+}
@@ -0,0 +1,105 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+type Direction string
+
+const (
+	Next Direction = "next"
+	Prev Direction = "prev"
+)
+
+// Cursor point to the boundary row of the current page, the page after (Next) or before (Prev) it is fetched.
+// Client must treat the encoded cursor as opaque token, so the content can be changed without breaking the API.
+type Cursor struct {
+	Direction Direction `json:"d"`
+	Sort      string    `json:"s"`           // Sort.String the cursor is created for
+	Key       string    `json:"k,omitempty"` // value of the sort field in the boundary row, empty when sort by id
+	ID        int64     `json:"i"`           // id of the boundary row, as tie-breaker of non-unique sort field
+}
+
+// Encode return the cursor as URL safe token.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c) // marshal struct of string and int never error
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parse the token from Cursor.Encode.
+func Decode(token string) (c Cursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+		return
+	}
+
+	if err = json.Unmarshal(b, &c); err != nil {
+		err = fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+		return
+	}
+
+	if c.Direction != Next && c.Direction != Prev {
+		err = fmt.Errorf("%w: unknown direction '%s'", ErrInvalidCursor, c.Direction)
+		return
+	}
+
+	return
+}
+
+// IDCursor return the encoded cursor of id sort from min_id (the page after it) or max_id (the page before it),
+// so the client using id based pagination keeps working. Empty when both is zero, both cannot be used together.
+func IDCursor(minID, maxID int64) (token string, err error) {
+	switch {
+	case minID > 0 && maxID > 0:
+		err = fmt.Errorf("%w: min_id and max_id cannot be used together", ErrInvalidCursor)
+	case minID > 0:
+		token = Cursor{Direction: Next, Sort: "id", ID: minID}.Encode()
+	case maxID > 0:
+		token = Cursor{Direction: Prev, Sort: "id", ID: maxID}.Encode()
+	}
+
+	return
+}
+
+// Sort is field name, prefixed with "-" when descending, i.e: -created_at
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort return Sort of the first field when s is empty, or ErrInvalidSort when the field is not in fields.
+func ParseSort(s string, fields ...string) (sort Sort, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" && len(fields) > 0 {
+		sort = Sort{Field: fields[0]}
+		return
+	}
+
+	sort = Sort{Field: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+	for _, field := range fields {
+		if sort.Field == field {
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: '%s', must be one of %s with optional '-' prefix for descending", ErrInvalidSort, s, fields)
+	return
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+
+	return s.Field
+}
@@ -0,0 +1,63 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
+)
+
+func TestDecode(t *testing.T) {
+	cursor := pagination.Cursor{Direction: pagination.Prev, Sort: "-name", Key: "my app", ID: 123}
+
+	decoded, err := pagination.Decode(cursor.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	_, err = pagination.Decode("not a cursor")
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+
+	_, err = pagination.Decode(pagination.Cursor{Direction: "up"}.Encode())
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestIDCursor(t *testing.T) {
+	token, err := pagination.IDCursor(0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+
+	token, err = pagination.IDCursor(0, 10)
+	assert.NoError(t, err)
+
+	cursor, err := pagination.Decode(token)
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.Cursor{Direction: pagination.Prev, Sort: "id", ID: 10}, cursor)
+
+	_, err = pagination.IDCursor(1, 10)
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestParseSort(t *testing.T) {
+	sort, err := pagination.ParseSort("", "id", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.Sort{Field: "id"}, sort)
+
+	sort, err = pagination.ParseSort("-name", "id", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "-name", sort.String())
+
+	_, err = pagination.ParseSort("client_id", "id", "name")
+	assert.ErrorIs(t, err, pagination.ErrInvalidSort)
+}
+
+func TestKeyset(t *testing.T) {
+	keyset := pagination.Keyset{Column: "name", IDColumn: "id", Cursor: &pagination.Cursor{Direction: pagination.Prev, ID: 1}, Key: "a"}
+
+	// previous page of ascending sort is fetched in descending order
+	cond, args := keyset.Where()
+	assert.Equal(t, "(name < ? OR (name = ? AND id < ?))", cond)
+	assert.Equal(t, []interface{}{"a", "a", int64(1)}, args)
+	assert.Equal(t, "name DESC, id DESC", keyset.OrderBy())
+
+	assert.Equal(t, "100!%!_!!%", pagination.LikePrefix("100%_!"))
+}
@@ -0,0 +1,113 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keyset build the SQL of keyset pagination, so the page is fetched using index instead of OFFSET.
+// The rows is ordered by Column then IDColumn, where IDColumn must be unique.
+type Keyset struct {
+	Column   string      // column of the sort field
+	IDColumn string      // unique tie-breaker column, i.e: id
+	Desc     bool        // same as Sort.Desc
+	Cursor   *Cursor     // nil for the first page
+	Key      interface{} // Cursor.Key converted into the column type, not used when Column is IDColumn
+}
+
+func (k Keyset) backward() bool {
+	return k.Cursor != nil && k.Cursor.Direction == Prev
+}
+
+// Where return the condition using "?" bind var to fetch rows after the cursor, empty when Cursor is nil.
+// Rebind the query for database that not using "?", i.e: Postgres.
+func (k Keyset) Where() (cond string, args []interface{}) {
+	if k.Cursor == nil {
+		return
+	}
+
+	// previous page is fetched in reverse order, then reversed back by Paginate
+	op := ">"
+	if k.Desc != k.backward() {
+		op = "<"
+	}
+
+	if k.Column == k.IDColumn {
+		cond = fmt.Sprintf("%s %s ?", k.IDColumn, op)
+		args = []interface{}{k.Cursor.ID}
+		return
+	}
+
+	cond = fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", k.Column, op, k.Column, k.IDColumn, op)
+	args = []interface{}{k.Key, k.Key, k.Cursor.ID}
+	return
+}
+
+// OrderBy return the ORDER BY expression without the keyword.
+func (k Keyset) OrderBy() string {
+	order := "ASC"
+	if k.Desc != k.backward() {
+		order = "DESC"
+	}
+
+	if k.Column == k.IDColumn {
+		return fmt.Sprintf("%s %s", k.IDColumn, order)
+	}
+
+	return fmt.Sprintf("%s %s, %s %s", k.Column, order, k.IDColumn, order)
+}
+
+// Page is the result of Paginate, Next and Prev is nil when there is no more page.
+type Page[T any] struct {
+	Items []T
+	Next  *Cursor
+	Prev  *Cursor
+}
+
+// Paginate return the page of rows which fetched using Keyset with limit + 1,
+// the extra row is only used to know whether there is more page.
+// keyOf return the Cursor.Key and Cursor.ID of the row.
+func Paginate[T any](rows []T, limit int64, sort Sort, cursor *Cursor, keyOf func(row T) (key string, id int64)) (page Page[T]) {
+	hasMore := int64(len(rows)) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	backward := cursor != nil && cursor.Direction == Prev
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	page.Items = rows
+	if len(rows) <= 0 {
+		return
+	}
+
+	newCursor := func(direction Direction, row T) *Cursor {
+		key, id := keyOf(row)
+		return &Cursor{Direction: direction, Sort: sort.String(), Key: key, ID: id}
+	}
+
+	// moving forward always come from the previous page when cursor is defined, and vice versa
+	if hasMore || backward {
+		page.Next = newCursor(Next, rows[len(rows)-1])
+	}
+
+	if (hasMore && backward) || (cursor != nil && !backward) {
+		page.Prev = newCursor(Prev, rows[0])
+	}
+
+	return
+}
+
+// LikeEscape is the escape character of LikePrefix, use it as: column LIKE ? ESCAPE '!'
+// Backslash is not used, because MySQL also treats it as escape character in the string literal.
+const LikeEscape = "!"
+
+// LikePrefix return the LIKE pattern to search value starting with prefix, the wildcard in prefix is escaped.
+func LikePrefix(prefix string) string {
+	replacer := strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_")
+	return replacer.Replace(prefix) + "%"
+}
@@ -8,6 +8,10 @@ const (
 	ErrDuplicateEntries
 	ErrResourceNotFound
 	ErrUnauthorized
+	ErrForbidden
+	ErrAppSuspended
+	ErrQuotaExceeded
+	ErrProviderNotAllowed
 )
 
 type Reason struct {
@@ -22,11 +26,15 @@ func (r *Reason) Error() string {
 var ErrX = &Reason{Code: "", Message: ""}
 
 var ReasonMap = map[ErrKind]Reason{
-	ErrUnhandled:        {Code: "01", Message: "unhandled error"},
-	ErrValidation:       {Code: "02", Message: "error validation"},
-	ErrDuplicateEntries: {Code: "03", Message: "duplicate entries"},
-	ErrResourceNotFound: {Code: "04", Message: "resource not found"},
-	ErrUnauthorized:     {Code: "05", Message: "unauthorized"},
+	ErrUnhandled:          {Code: "01", Message: "unhandled error"},
+	ErrValidation:         {Code: "02", Message: "error validation"},
+	ErrDuplicateEntries:   {Code: "03", Message: "duplicate entries"},
+	ErrResourceNotFound:   {Code: "04", Message: "resource not found"},
+	ErrUnauthorized:       {Code: "05", Message: "unauthorized"},
+	ErrForbidden:          {Code: "06", Message: "forbidden"},
+	ErrAppSuspended:       {Code: "07", Message: "app is suspended"},
+	ErrQuotaExceeded:      {Code: "08", Message: "app daily send quota exceeded"},
+	ErrProviderNotAllowed: {Code: "09", Message: "provider is not allowed for the app"},
 }
 
 // ErrorEntity contain code, message, debug (*if applicable) and trace id.
@@ -0,0 +1,195 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/yusufsyaifudin/ngendika/assets"
+	jaegerPropagator "go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/contrib/propagators/ot"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"sort"
+)
+
+const (
+	ExporterNone     = "none"
+	ExporterStdout   = "stdout"
+	ExporterJaeger   = "jaeger"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+)
+
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorJaeger       = "jaeger"
+	PropagatorOT           = "ot"
+)
+
+type Config struct {
+	// Exporter select where the span is exported, empty means jaeger to keep the previous behavior.
+	// Use none to disable exporting.
+	Exporter string `validate:"omitempty,oneof=none stdout jaeger otlp-grpc otlp-http"`
+
+	// Endpoint of the collector, i.e: http://localhost:14268/api/traces for jaeger or localhost:4317 for otlp-grpc.
+	// When empty, the exporter default endpoint is used.
+	Endpoint string
+
+	// Insecure disable TLS on otlp-grpc and otlp-http exporter.
+	Insecure bool
+
+	// Headers is sent on each export request, only for otlp-grpc and otlp-http exporter.
+	Headers map[string]string
+
+	// SamplingRatio 0 to 1, the parent decision is respected when the span has a parent.
+	SamplingRatio float64 `validate:"min=0,max=1"`
+
+	// ResourceAttributes is added to every span, i.e: environment=production.
+	ResourceAttributes map[string]string
+
+	// Propagators is text map propagators in order, empty means ot and jaeger to keep the previous behavior.
+	Propagators []string `validate:"dive,oneof=tracecontext baggage jaeger ot"`
+}
+
+// Setup build the exporter from the Config then set the global tracer provider and propagators.
+// The returned shutdown function must be called before the application exit to flush remaining spans.
+func Setup(ctx context.Context, cfg Config) (shutdown func(ctx context.Context) error, err error) {
+	shutdown = func(ctx context.Context) error { return nil }
+
+	err = validator.New().Struct(cfg)
+	if err != nil {
+		err = fmt.Errorf("tracing config error: %w", err)
+		return
+	}
+
+	otel.SetTextMapPropagator(newPropagator(cfg.Propagators))
+
+	exp, err := newExporter(ctx, cfg)
+	if err != nil {
+		return
+	}
+
+	// none exporter: keep the default no-op tracer provider
+	if exp == nil {
+		return
+	}
+
+	tp := InitTraceProvider(exp, cfg.SamplingRatio, cfg.ResourceAttributes)
+	shutdown = tp.Shutdown
+	return
+}
+
+// InitTraceProvider set the global tracer provider using the exporter.
+func InitTraceProvider(exp sdktrace.SpanExporter, samplingRatio float64, resourceAttrs map[string]string) *sdktrace.TracerProvider {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(assets.ServiceName),
+	}
+
+	// sort the keys, so the resource attributes is always in the same order
+	keys := make([]string, 0, len(resourceAttrs))
+	for key := range resourceAttrs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		attrs = append(attrs, attribute.String(key, resourceAttrs[key]))
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		// Always be sure to batch in production.
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		// Record information about this application in a Resource.
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+	)
+
+	otel.SetTracerProvider(tp)
+	return tp
+}
+
+func newExporter(ctx context.Context, cfg Config) (exp sdktrace.SpanExporter, err error) {
+	if cfg.Exporter == "" {
+		cfg.Exporter = ExporterJaeger
+	}
+
+	switch cfg.Exporter {
+	case ExporterNone:
+		return
+
+	case ExporterStdout:
+		exp, err = stdouttrace.New()
+
+	case ExporterJaeger:
+		var opts []jaeger.CollectorEndpointOption
+		if cfg.Endpoint != "" {
+			opts = append(opts, jaeger.WithEndpoint(cfg.Endpoint))
+		}
+
+		exp, err = jaeger.New(jaeger.WithCollectorEndpoint(opts...))
+
+	case ExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		exp, err = otlptracegrpc.New(ctx, opts...)
+
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exp, err = otlptracehttp.New(ctx, opts...)
+
+	default:
+		err = fmt.Errorf("not supported tracing exporter '%s'", cfg.Exporter)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("cannot setup %s exporter: %w", cfg.Exporter, err)
+	}
+
+	return
+}
+
+func newPropagator(names []string) propagation.TextMapPropagator {
+	if len(names) <= 0 {
+		names = []string{PropagatorOT, PropagatorJaeger}
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorJaeger:
+			propagators = append(propagators, jaegerPropagator.Jaeger{})
+		case PropagatorOT:
+			propagators = append(propagators, ot.OT{})
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
@@ -5,12 +5,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-playground/validator/v10"
-	"github.com/yusufsyaifudin/ngendika/assets"
+	"github.com/yusufsyaifudin/ngendika/pkg/httplog"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace"
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -24,25 +21,11 @@ func StartSpan(ctx context.Context, spanName string, opts ...trace.SpanStartOpti
 	return otel.GetTracerProvider().Tracer(tracerAppNme).Start(ctx, spanName, opts...)
 }
 
-func InitTraceProvider(exp sdktrace.SpanExporter) {
-	tp := sdktrace.NewTracerProvider(
-		// Always be sure to batch in production.
-		sdktrace.WithBatcher(exp),
-		// Record information about this application in a Resource.
-		sdktrace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(assets.ServiceName),
-			attribute.String("environment", "development"),
-		)),
-	)
-
-	otel.SetTracerProvider(tp)
-}
-
 type MiddlewareConfig struct {
 	TracerName     string                        `validate:"required"`
 	ServiceName    string                        `validate:"required"`
 	SkipFunc       func(r *http.Request) bool    `validate:"-"`
+	StreamFunc     func(r *http.Request) bool    `validate:"-"` // when true, the response is written directly without buffering
 	TracerProvider trace.TracerProvider          `validate:"required"`
 	TextPropagator propagation.TextMapPropagator `validate:"required"`
 }
@@ -89,8 +72,23 @@ func Middleware(cfg MiddlewareConfig, next http.Handler) http.HandlerFunc {
 		newCtx, span := cfg.TracerProvider.Tracer(cfg.TracerName).Start(ctx, spanName, opts...)
 		defer span.End()
 
-		respRec := httptest.NewRecorder()
 		r = r.WithContext(newCtx)
+
+		if cfg.StreamFunc != nil && cfg.StreamFunc(r) {
+			// header must be injected before the first write, since the body is flushed directly to the client
+			cfg.TextPropagator.Inject(newCtx, propagation.HeaderCarrier(w.Header()))
+
+			sw := &httplog.StatusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			code := sw.Code()
+			spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(code, oteltrace.SpanKindServer)
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(code)...)
+			span.SetStatus(spanStatus, spanMessage)
+			return
+		}
+
+		respRec := httptest.NewRecorder()
 		next.ServeHTTP(respRec, r)
 
 		attrs := semconv.HTTPAttributesFromHTTPStatusCode(respRec.Code)
@@ -0,0 +1,707 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: ngendikapb/ngendika.proto
+
+package ngendikapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// AppServiceClient is the client API for AppService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AppServiceClient interface {
+	CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error)
+	PutApp(ctx context.Context, in *PutAppRequest, opts ...grpc.CallOption) (*PutAppResponse, error)
+	ListApps(ctx context.Context, in *ListAppsRequest, opts ...grpc.CallOption) (*ListAppsResponse, error)
+	GetApp(ctx context.Context, in *GetAppRequest, opts ...grpc.CallOption) (*GetAppResponse, error)
+	DeleteApp(ctx context.Context, in *DeleteAppRequest, opts ...grpc.CallOption) (*DeleteAppResponse, error)
+	RestoreApp(ctx context.Context, in *RestoreAppRequest, opts ...grpc.CallOption) (*RestoreAppResponse, error)
+	SuspendApp(ctx context.Context, in *SuspendAppRequest, opts ...grpc.CallOption) (*SuspendAppResponse, error)
+	ResumeApp(ctx context.Context, in *ResumeAppRequest, opts ...grpc.CallOption) (*ResumeAppResponse, error)
+	PutAppSettings(ctx context.Context, in *PutAppSettingsRequest, opts ...grpc.CallOption) (*PutAppSettingsResponse, error)
+}
+
+type appServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAppServiceClient(cc grpc.ClientConnInterface) AppServiceClient {
+	return &appServiceClient{cc}
+}
+
+func (c *appServiceClient) CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error) {
+	out := new(CreateAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/CreateApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) PutApp(ctx context.Context, in *PutAppRequest, opts ...grpc.CallOption) (*PutAppResponse, error) {
+	out := new(PutAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/PutApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) ListApps(ctx context.Context, in *ListAppsRequest, opts ...grpc.CallOption) (*ListAppsResponse, error) {
+	out := new(ListAppsResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/ListApps", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) GetApp(ctx context.Context, in *GetAppRequest, opts ...grpc.CallOption) (*GetAppResponse, error) {
+	out := new(GetAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/GetApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) DeleteApp(ctx context.Context, in *DeleteAppRequest, opts ...grpc.CallOption) (*DeleteAppResponse, error) {
+	out := new(DeleteAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/DeleteApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) RestoreApp(ctx context.Context, in *RestoreAppRequest, opts ...grpc.CallOption) (*RestoreAppResponse, error) {
+	out := new(RestoreAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/RestoreApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) SuspendApp(ctx context.Context, in *SuspendAppRequest, opts ...grpc.CallOption) (*SuspendAppResponse, error) {
+	out := new(SuspendAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/SuspendApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) ResumeApp(ctx context.Context, in *ResumeAppRequest, opts ...grpc.CallOption) (*ResumeAppResponse, error) {
+	out := new(ResumeAppResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/ResumeApp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *appServiceClient) PutAppSettings(ctx context.Context, in *PutAppSettingsRequest, opts ...grpc.CallOption) (*PutAppSettingsResponse, error) {
+	out := new(PutAppSettingsResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.AppService/PutAppSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AppServiceServer is the server API for AppService service.
+// All implementations must embed UnimplementedAppServiceServer
+// for forward compatibility
+type AppServiceServer interface {
+	CreateApp(context.Context, *CreateAppRequest) (*CreateAppResponse, error)
+	PutApp(context.Context, *PutAppRequest) (*PutAppResponse, error)
+	ListApps(context.Context, *ListAppsRequest) (*ListAppsResponse, error)
+	GetApp(context.Context, *GetAppRequest) (*GetAppResponse, error)
+	DeleteApp(context.Context, *DeleteAppRequest) (*DeleteAppResponse, error)
+	RestoreApp(context.Context, *RestoreAppRequest) (*RestoreAppResponse, error)
+	SuspendApp(context.Context, *SuspendAppRequest) (*SuspendAppResponse, error)
+	ResumeApp(context.Context, *ResumeAppRequest) (*ResumeAppResponse, error)
+	PutAppSettings(context.Context, *PutAppSettingsRequest) (*PutAppSettingsResponse, error)
+	mustEmbedUnimplementedAppServiceServer()
+}
+
+// UnimplementedAppServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAppServiceServer struct {
+}
+
+func (UnimplementedAppServiceServer) CreateApp(context.Context, *CreateAppRequest) (*CreateAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateApp not implemented")
+}
+func (UnimplementedAppServiceServer) PutApp(context.Context, *PutAppRequest) (*PutAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutApp not implemented")
+}
+func (UnimplementedAppServiceServer) ListApps(context.Context, *ListAppsRequest) (*ListAppsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListApps not implemented")
+}
+func (UnimplementedAppServiceServer) GetApp(context.Context, *GetAppRequest) (*GetAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApp not implemented")
+}
+func (UnimplementedAppServiceServer) DeleteApp(context.Context, *DeleteAppRequest) (*DeleteAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteApp not implemented")
+}
+func (UnimplementedAppServiceServer) RestoreApp(context.Context, *RestoreAppRequest) (*RestoreAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreApp not implemented")
+}
+func (UnimplementedAppServiceServer) SuspendApp(context.Context, *SuspendAppRequest) (*SuspendAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuspendApp not implemented")
+}
+func (UnimplementedAppServiceServer) ResumeApp(context.Context, *ResumeAppRequest) (*ResumeAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeApp not implemented")
+}
+func (UnimplementedAppServiceServer) PutAppSettings(context.Context, *PutAppSettingsRequest) (*PutAppSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutAppSettings not implemented")
+}
+func (UnimplementedAppServiceServer) mustEmbedUnimplementedAppServiceServer() {}
+
+// UnsafeAppServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AppServiceServer will
+// result in compilation errors.
+type UnsafeAppServiceServer interface {
+	mustEmbedUnimplementedAppServiceServer()
+}
+
+func RegisterAppServiceServer(s grpc.ServiceRegistrar, srv AppServiceServer) {
+	s.RegisterService(&AppService_ServiceDesc, srv)
+}
+
+func _AppService_CreateApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).CreateApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/CreateApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).CreateApp(ctx, req.(*CreateAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_PutApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).PutApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/PutApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).PutApp(ctx, req.(*PutAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_ListApps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).ListApps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/ListApps",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).ListApps(ctx, req.(*ListAppsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_GetApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).GetApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/GetApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).GetApp(ctx, req.(*GetAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_DeleteApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).DeleteApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/DeleteApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).DeleteApp(ctx, req.(*DeleteAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_RestoreApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).RestoreApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/RestoreApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).RestoreApp(ctx, req.(*RestoreAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_SuspendApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuspendAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).SuspendApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/SuspendApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).SuspendApp(ctx, req.(*SuspendAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_ResumeApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).ResumeApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/ResumeApp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).ResumeApp(ctx, req.(*ResumeAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AppService_PutAppSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutAppSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AppServiceServer).PutAppSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.AppService/PutAppSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AppServiceServer).PutAppSettings(ctx, req.(*PutAppSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AppService_ServiceDesc is the grpc.ServiceDesc for AppService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AppService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ngendika.v1.AppService",
+	HandlerType: (*AppServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateApp",
+			Handler:    _AppService_CreateApp_Handler,
+		},
+		{
+			MethodName: "PutApp",
+			Handler:    _AppService_PutApp_Handler,
+		},
+		{
+			MethodName: "ListApps",
+			Handler:    _AppService_ListApps_Handler,
+		},
+		{
+			MethodName: "GetApp",
+			Handler:    _AppService_GetApp_Handler,
+		},
+		{
+			MethodName: "DeleteApp",
+			Handler:    _AppService_DeleteApp_Handler,
+		},
+		{
+			MethodName: "RestoreApp",
+			Handler:    _AppService_RestoreApp_Handler,
+		},
+		{
+			MethodName: "SuspendApp",
+			Handler:    _AppService_SuspendApp_Handler,
+		},
+		{
+			MethodName: "ResumeApp",
+			Handler:    _AppService_ResumeApp_Handler,
+		},
+		{
+			MethodName: "PutAppSettings",
+			Handler:    _AppService_PutAppSettings_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ngendikapb/ngendika.proto",
+}
+
+// PNPServiceClient is the client API for PNPService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PNPServiceClient interface {
+	CreatePNP(ctx context.Context, in *CreatePNPRequest, opts ...grpc.CallOption) (*CreatePNPResponse, error)
+	ListByProvider(ctx context.Context, in *ListByProviderRequest, opts ...grpc.CallOption) (*ListByProviderResponse, error)
+	Examples(ctx context.Context, in *ExamplesRequest, opts ...grpc.CallOption) (*ExamplesResponse, error)
+}
+
+type pNPServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPNPServiceClient(cc grpc.ClientConnInterface) PNPServiceClient {
+	return &pNPServiceClient{cc}
+}
+
+func (c *pNPServiceClient) CreatePNP(ctx context.Context, in *CreatePNPRequest, opts ...grpc.CallOption) (*CreatePNPResponse, error) {
+	out := new(CreatePNPResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.PNPService/CreatePNP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pNPServiceClient) ListByProvider(ctx context.Context, in *ListByProviderRequest, opts ...grpc.CallOption) (*ListByProviderResponse, error) {
+	out := new(ListByProviderResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.PNPService/ListByProvider", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pNPServiceClient) Examples(ctx context.Context, in *ExamplesRequest, opts ...grpc.CallOption) (*ExamplesResponse, error) {
+	out := new(ExamplesResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.PNPService/Examples", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PNPServiceServer is the server API for PNPService service.
+// All implementations must embed UnimplementedPNPServiceServer
+// for forward compatibility
+type PNPServiceServer interface {
+	CreatePNP(context.Context, *CreatePNPRequest) (*CreatePNPResponse, error)
+	ListByProvider(context.Context, *ListByProviderRequest) (*ListByProviderResponse, error)
+	Examples(context.Context, *ExamplesRequest) (*ExamplesResponse, error)
+	mustEmbedUnimplementedPNPServiceServer()
+}
+
+// UnimplementedPNPServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPNPServiceServer struct {
+}
+
+func (UnimplementedPNPServiceServer) CreatePNP(context.Context, *CreatePNPRequest) (*CreatePNPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePNP not implemented")
+}
+func (UnimplementedPNPServiceServer) ListByProvider(context.Context, *ListByProviderRequest) (*ListByProviderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListByProvider not implemented")
+}
+func (UnimplementedPNPServiceServer) Examples(context.Context, *ExamplesRequest) (*ExamplesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Examples not implemented")
+}
+func (UnimplementedPNPServiceServer) mustEmbedUnimplementedPNPServiceServer() {}
+
+// UnsafePNPServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PNPServiceServer will
+// result in compilation errors.
+type UnsafePNPServiceServer interface {
+	mustEmbedUnimplementedPNPServiceServer()
+}
+
+func RegisterPNPServiceServer(s grpc.ServiceRegistrar, srv PNPServiceServer) {
+	s.RegisterService(&PNPService_ServiceDesc, srv)
+}
+
+func _PNPService_CreatePNP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePNPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PNPServiceServer).CreatePNP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.PNPService/CreatePNP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PNPServiceServer).CreatePNP(ctx, req.(*CreatePNPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PNPService_ListByProvider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListByProviderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PNPServiceServer).ListByProvider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.PNPService/ListByProvider",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PNPServiceServer).ListByProvider(ctx, req.(*ListByProviderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PNPService_Examples_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExamplesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PNPServiceServer).Examples(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.PNPService/Examples",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PNPServiceServer).Examples(ctx, req.(*ExamplesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PNPService_ServiceDesc is the grpc.ServiceDesc for PNPService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PNPService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ngendika.v1.PNPService",
+	HandlerType: (*PNPServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePNP",
+			Handler:    _PNPService_CreatePNP_Handler,
+		},
+		{
+			MethodName: "ListByProvider",
+			Handler:    _PNPService_ListByProvider_Handler,
+		},
+		{
+			MethodName: "Examples",
+			Handler:    _PNPService_Examples_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ngendikapb/ngendika.proto",
+}
+
+// MessageServiceClient is the client API for MessageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MessageServiceClient interface {
+	// Send wait until all push notification providers done, then return all reports at once.
+	Send(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
+	// SendStream send each push notification provider report as soon as it completes,
+	// then the last response is the summary.
+	SendStream(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (MessageService_SendStreamClient, error)
+}
+
+type messageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMessageServiceClient(cc grpc.ClientConnInterface) MessageServiceClient {
+	return &messageServiceClient{cc}
+}
+
+func (c *messageServiceClient) Send(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error) {
+	out := new(SendMessageResponse)
+	err := c.cc.Invoke(ctx, "/ngendika.v1.MessageService/Send", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) SendStream(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (MessageService_SendStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MessageService_ServiceDesc.Streams[0], "/ngendika.v1.MessageService/SendStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &messageServiceSendStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MessageService_SendStreamClient interface {
+	Recv() (*SendStreamResponse, error)
+	grpc.ClientStream
+}
+
+type messageServiceSendStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *messageServiceSendStreamClient) Recv() (*SendStreamResponse, error) {
+	m := new(SendStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MessageServiceServer is the server API for MessageService service.
+// All implementations must embed UnimplementedMessageServiceServer
+// for forward compatibility
+type MessageServiceServer interface {
+	// Send wait until all push notification providers done, then return all reports at once.
+	Send(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
+	// SendStream send each push notification provider report as soon as it completes,
+	// then the last response is the summary.
+	SendStream(*SendMessageRequest, MessageService_SendStreamServer) error
+	mustEmbedUnimplementedMessageServiceServer()
+}
+
+// UnimplementedMessageServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMessageServiceServer struct {
+}
+
+func (UnimplementedMessageServiceServer) Send(context.Context, *SendMessageRequest) (*SendMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Send not implemented")
+}
+func (UnimplementedMessageServiceServer) SendStream(*SendMessageRequest, MessageService_SendStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendStream not implemented")
+}
+func (UnimplementedMessageServiceServer) mustEmbedUnimplementedMessageServiceServer() {}
+
+// UnsafeMessageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MessageServiceServer will
+// result in compilation errors.
+type UnsafeMessageServiceServer interface {
+	mustEmbedUnimplementedMessageServiceServer()
+}
+
+func RegisterMessageServiceServer(s grpc.ServiceRegistrar, srv MessageServiceServer) {
+	s.RegisterService(&MessageService_ServiceDesc, srv)
+}
+
+func _MessageService_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ngendika.v1.MessageService/Send",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).Send(ctx, req.(*SendMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_SendStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SendMessageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MessageServiceServer).SendStream(m, &messageServiceSendStreamServer{stream})
+}
+
+type MessageService_SendStreamServer interface {
+	Send(*SendStreamResponse) error
+	grpc.ServerStream
+}
+
+type messageServiceSendStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *messageServiceSendStreamServer) Send(m *SendStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MessageService_ServiceDesc is the grpc.ServiceDesc for MessageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MessageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ngendika.v1.MessageService",
+	HandlerType: (*MessageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _MessageService_Send_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendStream",
+			Handler:       _MessageService_SendStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ngendikapb/ngendika.proto",
+}
@@ -0,0 +1,141 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+	"strings"
+)
+
+// appServer same as REST handlerapp.
+type appServer struct {
+	ngendikapb.UnimplementedAppServiceServer
+
+	appService appsvc.Service
+}
+
+var _ ngendikapb.AppServiceServer = (*appServer)(nil)
+
+func (s *appServer) CreateApp(ctx context.Context, req *ngendikapb.CreateAppRequest) (*ngendikapb.CreateAppResponse, error) {
+	createAppOut, err := s.appService.CreateApp(ctx, appsvc.InputCreateApp{
+		ClientID: req.GetClientId(),
+		Name:     req.GetName(),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.CreateAppResponse{App: appFromSvc(createAppOut.App)}, nil
+}
+
+func (s *appServer) PutApp(ctx context.Context, req *ngendikapb.PutAppRequest) (*ngendikapb.PutAppResponse, error) {
+	putAppOut, err := s.appService.PutApp(ctx, appsvc.InputPutApp{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+		Name:     req.GetName(),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.PutAppResponse{App: appFromSvc(putAppOut.App)}, nil
+}
+
+// ListApps translate min_id and max_id into the cursor of id sort, both cannot be used together.
+// The total is always counted to keep the response the same as before cursor pagination.
+func (s *appServer) ListApps(ctx context.Context, req *ngendikapb.ListAppsRequest) (*ngendikapb.ListAppsResponse, error) {
+	cursor, err := pagination.IDCursor(req.GetMinId(), req.GetMaxId())
+	if err != nil {
+		return nil, errValidation(err)
+	}
+
+	listOut, err := s.appService.ListApp(ctx, appsvc.InputListApp{
+		Limit:     req.GetLimit(),
+		Cursor:    cursor,
+		WithTotal: true,
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	apps := make([]*ngendikapb.App, 0, len(listOut.Apps))
+	for _, app := range listOut.Apps {
+		apps = append(apps, appFromSvc(app))
+	}
+
+	return &ngendikapb.ListAppsResponse{
+		Total: listOut.Total,
+		Limit: listOut.Limit,
+		Items: apps,
+	}, nil
+}
+
+func (s *appServer) GetApp(ctx context.Context, req *ngendikapb.GetAppRequest) (*ngendikapb.GetAppResponse, error) {
+	enabled := true
+	getAppOut, err := s.appService.GetApp(ctx, appsvc.InputGetApp{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		return nil, errService(err)
+	}
+
+	return &ngendikapb.GetAppResponse{App: appFromSvc(getAppOut.App)}, nil
+}
+
+func (s *appServer) DeleteApp(ctx context.Context, req *ngendikapb.DeleteAppRequest) (*ngendikapb.DeleteAppResponse, error) {
+	delAppOut, err := s.appService.DelApp(ctx, appsvc.InputDelApp{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.DeleteAppResponse{Success: delAppOut.Success}, nil
+}
+
+func (s *appServer) RestoreApp(ctx context.Context, req *ngendikapb.RestoreAppRequest) (*ngendikapb.RestoreAppResponse, error) {
+	restoreAppOut, err := s.appService.RestoreApp(ctx, appsvc.InputRestoreApp{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.RestoreAppResponse{App: appFromSvc(restoreAppOut.App)}, nil
+}
+
+func (s *appServer) SuspendApp(ctx context.Context, req *ngendikapb.SuspendAppRequest) (*ngendikapb.SuspendAppResponse, error) {
+	suspendAppOut, err := s.appService.SuspendApp(ctx, appsvc.InputSuspendApp{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+		Reason:   strings.TrimSpace(req.GetReason()),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.SuspendAppResponse{App: appFromSvc(suspendAppOut.App)}, nil
+}
+
+func (s *appServer) ResumeApp(ctx context.Context, req *ngendikapb.ResumeAppRequest) (*ngendikapb.ResumeAppResponse, error) {
+	resumeAppOut, err := s.appService.ResumeApp(ctx, appsvc.InputResumeApp{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.ResumeAppResponse{App: appFromSvc(resumeAppOut.App)}, nil
+}
+
+func (s *appServer) PutAppSettings(ctx context.Context, req *ngendikapb.PutAppSettingsRequest) (*ngendikapb.PutAppSettingsResponse, error) {
+	putSettingsOut, err := s.appService.PutAppSettings(ctx, appsvc.InputPutAppSettings{
+		ClientID: strings.TrimSpace(req.GetClientId()),
+		Settings: appSettingsToSvc(req.GetSettings()),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.PutAppSettingsResponse{App: appFromSvc(putSettingsOut.App)}, nil
+}
@@ -0,0 +1,122 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// methodScopes full method name => scope required, same scope as the REST route.
+// Method not listed here is denied, except the publicServices.
+var methodScopes = map[string]apikey.Scope{
+	"/ngendika.v1.AppService/CreateApp":      apikey.ScopeAppsWrite,
+	"/ngendika.v1.AppService/PutApp":         apikey.ScopeAppsWrite,
+	"/ngendika.v1.AppService/ListApps":       apikey.ScopeAppsRead,
+	"/ngendika.v1.AppService/GetApp":         apikey.ScopeAppsRead,
+	"/ngendika.v1.AppService/DeleteApp":      apikey.ScopeAppsAdmin,
+	"/ngendika.v1.AppService/RestoreApp":     apikey.ScopeAppsAdmin,
+	"/ngendika.v1.AppService/SuspendApp":     apikey.ScopeAppsAdmin,
+	"/ngendika.v1.AppService/ResumeApp":      apikey.ScopeAppsAdmin,
+	"/ngendika.v1.AppService/PutAppSettings": apikey.ScopeAppsAdmin,
+
+	"/ngendika.v1.PNPService/CreatePNP":      apikey.ScopePnpWrite,
+	"/ngendika.v1.PNPService/ListByProvider": apikey.ScopePnpRead,
+	"/ngendika.v1.PNPService/Examples":       apikey.ScopePnpRead,
+
+	"/ngendika.v1.MessageService/Send":       apikey.ScopeMessagesSend,
+	"/ngendika.v1.MessageService/SendStream": apikey.ScopeMessagesSend,
+}
+
+// publicServices prefix of full method name which can be accessed without API key, i.e: health and reflection.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+type authenticator struct {
+	keys apikey.Keys
+}
+
+func newAuthenticator(keys []apikey.APIKey) *authenticator {
+	return &authenticator{keys: keys}
+}
+
+func (a *authenticator) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (a *authenticator) stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// authorize return Unauthenticated error when API key is missing or not valid,
+// or PermissionDenied when the API key doesn't have the scope of the method or the method has no scope.
+// When no API key registered, all method is allowed.
+func (a *authenticator) authorize(ctx context.Context, fullMethod string) error {
+	if len(a.keys) <= 0 || isPublicMethod(fullMethod) {
+		return nil
+	}
+
+	// fail closed, so new method is not accessible until its scope is defined
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "method '%s' has no scope", fullMethod)
+	}
+
+	rawKey := apiKeyFromMetadata(ctx)
+	if rawKey == "" {
+		return status.Error(codes.Unauthenticated, "missing api key, use metadata 'authorization: Bearer <key>' or 'x-api-key: <key>'")
+	}
+
+	apiKey, ok := a.keys.Lookup(rawKey)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "invalid api key")
+	}
+
+	if !apiKey.Allow(scope) {
+		return status.Errorf(codes.PermissionDenied, "api key '%s' does not have scope '%s'", apiKey.Name, scope)
+	}
+
+	return nil
+}
+
+func isPublicMethod(fullMethod string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-api-key"); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		return strings.TrimSpace(values[0])
+	}
+
+	values := md.Get("authorization")
+	if len(values) <= 0 {
+		return ""
+	}
+
+	return apikey.BearerToken(values[0])
+}
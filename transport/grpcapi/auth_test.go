@@ -0,0 +1,69 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticator_Authorize(t *testing.T) {
+	auth := newAuthenticator([]apikey.APIKey{
+		{Name: "sender", Key: "sender-key", Scopes: []apikey.Scope{apikey.ScopeMessagesSend}},
+	})
+
+	withMD := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+	}
+
+	testCases := []struct {
+		Name   string
+		Ctx    context.Context
+		Method string
+		Code   codes.Code
+	}{
+		{Name: "missing key", Ctx: context.Background(), Method: "/ngendika.v1.MessageService/Send", Code: codes.Unauthenticated},
+		{Name: "invalid key", Ctx: withMD("x-api-key", "wrong"), Method: "/ngendika.v1.MessageService/Send", Code: codes.Unauthenticated},
+		{Name: "allowed by x-api-key", Ctx: withMD("x-api-key", "sender-key"), Method: "/ngendika.v1.MessageService/SendStream", Code: codes.OK},
+		{Name: "allowed by bearer", Ctx: withMD("authorization", "Bearer sender-key"), Method: "/ngendika.v1.MessageService/Send", Code: codes.OK},
+		{Name: "no scope", Ctx: withMD("x-api-key", "sender-key"), Method: "/ngendika.v1.AppService/CreateApp", Code: codes.PermissionDenied},
+		{Name: "health is not protected", Ctx: context.Background(), Method: "/grpc.health.v1.Health/Check", Code: codes.OK},
+		{Name: "reflection is not protected", Ctx: context.Background(), Method: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", Code: codes.OK},
+		{Name: "unknown method is denied", Ctx: withMD("x-api-key", "sender-key"), Method: "/ngendika.v1.MessageService/Unknown", Code: codes.PermissionDenied},
+		{Name: "unknown service is denied", Ctx: context.Background(), Method: "/other.v1.Service/Call", Code: codes.PermissionDenied},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := auth.authorize(testCase.Ctx, testCase.Method)
+			assert.Equal(t, testCase.Code, status.Code(err))
+		})
+	}
+
+	t.Run("all registered method has scope", func(t *testing.T) {
+		for _, service := range []grpc.ServiceDesc{
+			ngendikapb.AppService_ServiceDesc,
+			ngendikapb.PNPService_ServiceDesc,
+			ngendikapb.MessageService_ServiceDesc,
+		} {
+			for _, method := range service.Methods {
+				assert.Contains(t, methodScopes, "/"+service.ServiceName+"/"+method.MethodName)
+			}
+
+			for _, stream := range service.Streams {
+				assert.Contains(t, methodScopes, "/"+service.ServiceName+"/"+stream.StreamName)
+			}
+		}
+	})
+
+	t.Run("no api key configured", func(t *testing.T) {
+		err := newAuthenticator(nil).authorize(context.Background(), "/ngendika.v1.AppService/DeleteApp")
+		assert.NoError(t, err)
+	})
+}
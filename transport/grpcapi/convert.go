@@ -0,0 +1,174 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/backend"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// errValidation is used when request is not valid, same as respbuilder.ErrValidation on REST API.
+func errValidation(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// errUnhandled is used when the service return error, same as respbuilder.ErrUnhandled on REST API.
+func errUnhandled(err error) error {
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// errService is errUnhandled, except suspended app is returned as FailedPrecondition,
+// provider not allowed as PermissionDenied, exceeded quota as ResourceExhausted and invalid input as InvalidArgument,
+// same as the respbuilder error kind on REST API.
+func errService(err error) error {
+	switch {
+	case errors.Is(err, msgsvc.ErrValidation):
+		return errValidation(err)
+	case errors.Is(err, appsvc.ErrAppSuspended):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, appsvc.ErrProviderNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, appsvc.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return errUnhandled(err)
+}
+
+// toValue convert any JSON serializable value into structpb.Value, nil is converted into null value.
+func toValue(v any) (*structpb.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal value to json: %w", err)
+	}
+
+	return rawJSONToValue(b)
+}
+
+func rawJSONToValue(b []byte) (*structpb.Value, error) {
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(b, value); err != nil {
+		return nil, fmt.Errorf("cannot convert json to protobuf value: %w", err)
+	}
+
+	return value, nil
+}
+
+func appFromSvc(app appsvc.App) *ngendikapb.App {
+	pbApp := &ngendikapb.App{
+		Id:              app.ID,
+		ClientId:        app.ClientID,
+		Name:            app.Name,
+		CreatedAt:       timestamppb.New(app.CreatedAt),
+		UpdatedAt:       timestamppb.New(app.UpdatedAt),
+		Enabled:         app.Enabled,
+		SuspendedReason: app.SuspendedReason,
+		Settings:        appSettingsFromSvc(app.Settings),
+	}
+
+	if !app.SuspendedAt.IsZero() {
+		pbApp.SuspendedAt = timestamppb.New(app.SuspendedAt)
+	}
+
+	return pbApp
+}
+
+// appSettingsFromSvc same as httptyped.AppSettingsEntityFromSvc, default ttls is in seconds.
+func appSettingsFromSvc(settings appsvc.AppSettings) *ngendikapb.AppSettings {
+	pbSettings := &ngendikapb.AppSettings{
+		DefaultLabel:     settings.DefaultLabel,
+		AllowedProviders: settings.AllowedProviders,
+		DailySendQuota:   settings.DailySendQuota,
+		ContactEmail:     settings.ContactEmail,
+	}
+
+	if len(settings.DefaultTTLs) > 0 {
+		pbSettings.DefaultTtls = make(map[string]int64, len(settings.DefaultTTLs))
+		for provider, ttl := range settings.DefaultTTLs {
+			pbSettings.DefaultTtls[provider] = int64(ttl / time.Second)
+		}
+	}
+
+	return pbSettings
+}
+
+// appSettingsToSvc same as httptyped.AppSettingsEntity ToSvc, nil settings reset all settings to default.
+func appSettingsToSvc(pbSettings *ngendikapb.AppSettings) appsvc.AppSettings {
+	settings := appsvc.AppSettings{
+		DefaultLabel:     pbSettings.GetDefaultLabel(),
+		AllowedProviders: pbSettings.GetAllowedProviders(),
+		DailySendQuota:   pbSettings.GetDailySendQuota(),
+		ContactEmail:     pbSettings.GetContactEmail(),
+	}
+
+	if len(pbSettings.GetDefaultTtls()) > 0 {
+		settings.DefaultTTLs = make(map[string]time.Duration, len(pbSettings.GetDefaultTtls()))
+		for provider, ttl := range pbSettings.GetDefaultTtls() {
+			settings.DefaultTTLs[provider] = time.Duration(ttl) * time.Second
+		}
+	}
+
+	return settings
+}
+
+// pnpFromBackend same as backend.PushNotificationProvider MarshalJSON,
+// credential is returned as JSON object when it is valid JSON, otherwise as string.
+func pnpFromBackend(pnp backend.PushNotificationProvider) (*ngendikapb.PushNotificationProvider, error) {
+	credential := structpb.NewStringValue(pnp.CredentialJSON)
+	if json.Valid([]byte(pnp.CredentialJSON)) {
+		value, err := rawJSONToValue([]byte(pnp.CredentialJSON))
+		if err != nil {
+			return nil, err
+		}
+
+		credential = value
+	}
+
+	return &ngendikapb.PushNotificationProvider{
+		Id:             pnp.ID,
+		AppId:          pnp.AppID,
+		Provider:       pnp.Provider,
+		Label:          pnp.Label,
+		CredentialJson: credential,
+		CreatedAt:      timestamppb.New(pnp.CreatedAt),
+		UpdatedAt:      timestamppb.New(pnp.UpdatedAt),
+	}, nil
+}
+
+func reportGroupFromSvc(group msgsvc.ReportGroup) (*ngendikapb.ReportGroup, error) {
+	pnp, err := pnpFromBackend(group.PNP)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*ngendikapb.BackendReport, 0, len(group.BackendReports))
+	for _, report := range group.BackendReports {
+		nativeResponse, _err := toValue(report.NativeResponse)
+		if _err != nil {
+			return nil, fmt.Errorf("native response of pnp id %d: %w", group.PNP.ID, _err)
+		}
+
+		reports = append(reports, &ngendikapb.BackendReport{
+			ReferenceId:    report.ReferenceID,
+			WorkerId:       int32(report.WorkerID),
+			SuccessCount:   int32(report.SuccessCount),
+			FailureCount:   int32(report.FailureCount),
+			NativeResponse: nativeResponse,
+		})
+	}
+
+	return &ngendikapb.ReportGroup{
+		Pnp:            pnp,
+		BackendErrors:  group.BackendErrors,
+		BackendReports: reports,
+	}, nil
+}
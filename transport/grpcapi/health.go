@@ -0,0 +1,85 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// watchInterval is the interval to re-check the dependencies on Watch.
+const watchInterval = 5 * time.Second
+
+// healthServer implement grpc.health.v1.Health using the same readiness checks as REST /health/ready.
+// Empty service name or any ngendika service is SERVING only when all dependencies are up.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	health   *health.Health
+	services map[string]struct{}
+}
+
+var _ grpc_health_v1.HealthServer = (*healthServer)(nil)
+
+func newHealthServer(h *health.Health) *healthServer {
+	return &healthServer{
+		health: h,
+		services: map[string]struct{}{
+			"": {},
+			ngendikapb.AppService_ServiceDesc.ServiceName:     {},
+			ngendikapb.PNPService_ServiceDesc.ServiceName:     {},
+			ngendikapb.MessageService_ServiceDesc.ServiceName: {},
+		},
+	}
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if _, ok := s.services[req.GetService()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service '%s'", req.GetService())
+	}
+
+	return &grpc_health_v1.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+// Watch send the status once, then send again every time the status changes until the client cancel it.
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+
+	if _, ok := s.services[req.GetService()]; !ok {
+		return stream.Send(&grpc_health_v1.HealthCheckResponse{
+			Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN,
+		})
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var last grpc_health_v1.HealthCheckResponse_ServingStatus = -1
+	for {
+		current := s.status(ctx)
+		if current != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *healthServer) status(ctx context.Context) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if s.health.Check(ctx).Up() {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,184 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/satori/uuid"
+	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
+	"github.com/yusufsyaifudin/ylog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+// metadataCarrier adapt incoming metadata as propagation.TextMapCarrier, metadata key is always lower case.
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	values := metadata.MD(m).Get(key)
+	if len(values) <= 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// toSimpleMap join the metadata values, the API key is never logged.
+func toSimpleMap(md metadata.MD) map[string]string {
+	out := map[string]string{}
+	for k, v := range md {
+		if k == "authorization" || k == "x-api-key" {
+			continue
+		}
+
+		out[k] = strings.Join(v, " ")
+	}
+
+	return out
+}
+
+// healthWatchMethod is long-lived stream, so the request timeout is not applied.
+const healthWatchMethod = "/grpc.health.v1.Health/Watch"
+
+// prepareContext add deadline when the client doesn't send one (the sender worker require it), timeout <= 0 means no deadline.
+// Then extract the propagated trace, start the server span and inject the log tracer.
+func prepareContext(ctx context.Context, fullMethod string, timeout time.Duration) (context.Context, context.CancelFunc, trace.Span) {
+	cancel := context.CancelFunc(func() {})
+	if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md.Copy()))
+
+	var span trace.Span
+	ctx, span = tracer.StartSpan(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", fullMethod),
+		),
+	)
+
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+
+	logTraceData, err := ylog.NewTracer(tracer.LogData{
+		RemoteAddr: remoteAddr,
+		TraceID:    uuid.NewV4().String(),
+	}, ylog.WithTag("tracer"))
+	if err == nil {
+		ctx = ylog.Inject(ctx, logTraceData)
+	}
+
+	return ctx, cancel, span
+}
+
+func finishSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+func unaryRequestLogger(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		t1 := time.Now().UTC()
+
+		ctx, cancel, span := prepareContext(ctx, info.FullMethod, timeout)
+		defer cancel()
+
+		resp, err = handler(ctx, req)
+		finishSpan(span, err)
+
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		ylog.Access(ctx, ylog.AccessLogData{
+			Path: info.FullMethod,
+			Request: ylog.HTTPData{
+				Header:     toSimpleMap(md),
+				DataObject: req,
+			},
+			Response: ylog.HTTPData{
+				DataObject: resp,
+			},
+			Error:       errStr,
+			ElapsedTime: time.Since(t1).Milliseconds(),
+		})
+
+		return
+	}
+}
+
+// wrappedStream replace the stream context with the prepared context.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func streamRequestLogger(timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		t1 := time.Now().UTC()
+
+		streamTimeout := timeout
+		if info.FullMethod == healthWatchMethod {
+			streamTimeout = 0
+		}
+
+		ctx, cancel, span := prepareContext(ss.Context(), info.FullMethod, streamTimeout)
+		defer cancel()
+
+		err = handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		finishSpan(span, err)
+
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+
+		// stream messages is not logged, since it can be many
+		md, _ := metadata.FromIncomingContext(ctx)
+		ylog.Access(ctx, ylog.AccessLogData{
+			Path: info.FullMethod,
+			Request: ylog.HTTPData{
+				Header: toSimpleMap(md),
+			},
+			Error:       errStr,
+			ElapsedTime: time.Since(t1).Milliseconds(),
+		})
+
+		return
+	}
+}
@@ -0,0 +1,127 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+)
+
+// messageServer same as REST handlermsg.
+type messageServer struct {
+	ngendikapb.UnimplementedMessageServiceServer
+
+	msgService msgsvc.Service
+}
+
+var _ ngendikapb.MessageServiceServer = (*messageServer)(nil)
+
+func (s *messageServer) Send(ctx context.Context, req *ngendikapb.SendMessageRequest) (*ngendikapb.SendMessageResponse, error) {
+	processMsgIn, err := inputProcessFromRequest(req)
+	if err != nil {
+		return nil, errValidation(err)
+	}
+
+	processMsgOut, err := s.msgService.Process(ctx, processMsgIn)
+	if err != nil {
+		return nil, errService(err)
+	}
+
+	reports := make([]*ngendikapb.ReportGroup, 0, len(processMsgOut.ReportGroup))
+	for _, group := range processMsgOut.ReportGroup {
+		report, _err := reportGroupFromSvc(group)
+		if _err != nil {
+			return nil, errUnhandled(_err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return &ngendikapb.SendMessageResponse{
+		TaskId:  processMsgOut.TaskID,
+		App:     appFromSvc(processMsgOut.App),
+		Errors:  processMsgOut.Errors,
+		Reports: reports,
+	}, nil
+}
+
+func (s *messageServer) SendStream(req *ngendikapb.SendMessageRequest, stream ngendikapb.MessageService_SendStreamServer) error {
+	processMsgIn, err := inputProcessFromRequest(req)
+	if err != nil {
+		return errValidation(err)
+	}
+
+	// OnReport is called serially, so it is safe to send into the stream and set streamErr
+	var streamErr error
+	processMsgIn.OnReport = func(group msgsvc.ReportGroup) {
+		if streamErr != nil {
+			return
+		}
+
+		report, _err := reportGroupFromSvc(group)
+		if _err != nil {
+			streamErr = errUnhandled(_err)
+			return
+		}
+
+		streamErr = stream.Send(&ngendikapb.SendStreamResponse{
+			Event: &ngendikapb.SendStreamResponse_Report{Report: report},
+		})
+	}
+
+	processMsgOut, err := s.msgService.Process(stream.Context(), processMsgIn)
+	if err != nil {
+		return errService(err)
+	}
+
+	if streamErr != nil {
+		return streamErr
+	}
+
+	return stream.Send(&ngendikapb.SendStreamResponse{
+		Event: &ngendikapb.SendStreamResponse_Summary{
+			Summary: &ngendikapb.SendSummary{
+				TaskId: processMsgOut.TaskID,
+				App:    appFromSvc(processMsgOut.App),
+				Errors: processMsgOut.Errors,
+			},
+		},
+	})
+}
+
+func inputProcessFromRequest(req *ngendikapb.SendMessageRequest) (*msgsvc.InputProcess, error) {
+	userIDs, topics, err := msgsvc.ParseTargets(req.GetUserIds(), req.GetTo())
+	if err != nil {
+		return nil, err
+	}
+
+	var payloads map[string][]interface{}
+	if len(req.GetPayloads()) > 0 {
+		payloads = make(map[string][]interface{}, len(req.GetPayloads()))
+		for provider, list := range req.GetPayloads() {
+			if list == nil {
+				return nil, fmt.Errorf("payloads of provider '%s' is null", provider)
+			}
+
+			payloads[provider] = list.AsSlice()
+		}
+	}
+
+	var variables map[string]interface{}
+	if req.GetVariables() != nil {
+		variables = req.GetVariables().AsMap()
+	}
+
+	return &msgsvc.InputProcess{
+		TaskID:   req.GetTaskId(),
+		ClientID: req.GetClientId(),
+		Label:    req.GetLabel(),
+		Payloads: payloads,
+
+		TemplateID:   req.GetTemplateId(),
+		TemplateVars: variables,
+		Locale:       req.GetLocale(),
+		UserIDs:      userIDs,
+		Topics:       topics,
+	}, nil
+}
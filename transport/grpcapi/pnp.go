@@ -0,0 +1,121 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnpsvc"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+)
+
+// pnpServer same as REST handlerpnp.
+type pnpServer struct {
+	ngendikapb.UnimplementedPNPServiceServer
+
+	appService appsvc.Service
+	pnpService pnpsvc.Service
+}
+
+var _ ngendikapb.PNPServiceServer = (*pnpServer)(nil)
+
+func (s *pnpServer) CreatePNP(ctx context.Context, req *ngendikapb.CreatePNPRequest) (*ngendikapb.CreatePNPResponse, error) {
+	if req.GetCredentialJson() == nil {
+		return nil, errValidation(fmt.Errorf("credential_json is required"))
+	}
+
+	app, err := s.getApp(ctx, req.GetClientId())
+	if err != nil {
+		return nil, err
+	}
+
+	outSvcProvider, err := s.pnpService.Create(ctx, pnpsvc.InCreate{
+		AppID: app.ID,
+		PnProvider: pnpsvc.InCreatePnProvider{
+			Provider:       req.GetProvider(),
+			Label:          req.GetLabel(),
+			CredentialJSON: req.GetCredentialJson().AsInterface(),
+		},
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	pnp, err := pnpFromBackend(outSvcProvider.ServiceProvider)
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	return &ngendikapb.CreatePNPResponse{
+		App:           appFromSvc(app),
+		BackendConfig: pnp,
+	}, nil
+}
+
+func (s *pnpServer) ListByProvider(ctx context.Context, req *ngendikapb.ListByProviderRequest) (*ngendikapb.ListByProviderResponse, error) {
+	app, err := s.getApp(ctx, req.GetClientId())
+	if err != nil {
+		return nil, err
+	}
+
+	fetchOut, err := s.pnpService.GetByLabels(ctx, pnpsvc.InGetByLabels{
+		AppID:    app.ID,
+		Provider: req.GetProvider(),
+		Label:    req.GetLabel(),
+	})
+	if err != nil {
+		return nil, errUnhandled(err)
+	}
+
+	items := make([]*ngendikapb.PushNotificationProvider, 0, len(fetchOut.PnProviders))
+	for _, pnProvider := range fetchOut.PnProviders {
+		pnp, _err := pnpFromBackend(pnProvider)
+		if _err != nil {
+			return nil, errUnhandled(_err)
+		}
+
+		items = append(items, pnp)
+	}
+
+	return &ngendikapb.ListByProviderResponse{Items: items}, nil
+}
+
+func (s *pnpServer) Examples(ctx context.Context, _ *ngendikapb.ExamplesRequest) (*ngendikapb.ExamplesResponse, error) {
+	examples := s.pnpService.Examples(ctx)
+
+	items := make([]*ngendikapb.Example, 0, len(examples.Items))
+	for _, example := range examples.Items {
+		backendConfig, err := toValue(example.BackendConfig)
+		if err != nil {
+			return nil, errUnhandled(err)
+		}
+
+		message, err := toValue(example.Message)
+		if err != nil {
+			return nil, errUnhandled(err)
+		}
+
+		items = append(items, &ngendikapb.Example{
+			Provider:      example.Provider,
+			BackendConfig: backendConfig,
+			Message:       message,
+		})
+	}
+
+	return &ngendikapb.ExamplesResponse{Items: items}, nil
+}
+
+// getApp return only enabled app.
+func (s *pnpServer) getApp(ctx context.Context, clientID string) (app appsvc.App, err error) {
+	enabled := true
+	getAppOut, err := s.appService.GetApp(ctx, appsvc.InputGetApp{
+		ClientID: clientID,
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		err = errService(err)
+		return
+	}
+
+	app = getAppOut.App
+	return
+}
@@ -0,0 +1,68 @@
+package grpcapi
+
+import (
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnpsvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ngendika/proto/ngendikapb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"time"
+)
+
+type Config struct {
+	AppService appsvc.Service  `validate:"required"`
+	PNPService pnpsvc.Service  `validate:"required"`
+	MsgService msgsvc.Service  `validate:"required"`
+	Health     *health.Health  `validate:"required"`
+	APIKeys    []apikey.APIKey `validate:"dive"` // empty means no auth, same keys and scopes as the REST API
+
+	// RequestTimeout is deadline of each call when the client doesn't send one, default 30 seconds.
+	RequestTimeout time.Duration `validate:"min=0"`
+}
+
+type DefaultGRPC struct {
+	server *grpc.Server
+}
+
+func NewGRPCTransport(cfg Config) (*DefaultGRPC, error) {
+	if err := validator.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("grpc transport cfg error: %w", err)
+	}
+
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 30 * time.Second
+	}
+
+	auth := newAuthenticator(cfg.APIKeys)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			unaryRequestLogger(cfg.RequestTimeout),
+			auth.unary(),
+		),
+		grpc.ChainStreamInterceptor(
+			streamRequestLogger(cfg.RequestTimeout),
+			auth.stream(),
+		),
+	)
+
+	ngendikapb.RegisterAppServiceServer(server, &appServer{appService: cfg.AppService})
+	ngendikapb.RegisterPNPServiceServer(server, &pnpServer{appService: cfg.AppService, pnpService: cfg.PNPService})
+	ngendikapb.RegisterMessageServiceServer(server, &messageServer{msgService: cfg.MsgService})
+	grpc_health_v1.RegisterHealthServer(server, newHealthServer(cfg.Health))
+
+	// allow client such as grpcurl to list the services without the proto file
+	reflection.Register(server)
+
+	return &DefaultGRPC{server: server}, nil
+}
+
+// Server .
+func (g *DefaultGRPC) Server() *grpc.Server {
+	return g.server
+}
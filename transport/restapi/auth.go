@@ -0,0 +1,77 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"net/http"
+	"strings"
+)
+
+type apiKeyCtxKey struct{}
+
+// APIKeyFromContext return the API key used in current request.
+// It returns false when auth is disabled (no API key configured).
+func APIKeyFromContext(ctx context.Context) (apikey.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(apikey.APIKey)
+	return key, ok
+}
+
+type authenticator struct {
+	keys apikey.Keys
+}
+
+func newAuthenticator(keys []apikey.APIKey) *authenticator {
+	return &authenticator{keys: keys}
+}
+
+// Require returns middleware that rejects request without valid API key (401)
+// or when the API key doesn't have the scope (403).
+// When no API key registered, all request is allowed.
+func (a *authenticator) Require(scope apikey.Scope) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(a.keys) <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+
+			rawKey := apiKeyFromRequest(r)
+			if rawKey == "" {
+				err := fmt.Errorf("missing api key, use header 'Authorization: Bearer <key>' or 'X-API-Key: <key>'")
+				resp := respbuilder.Error(ctx, respbuilder.ErrUnauthorized, err)
+				respbuilder.WriteJSON(http.StatusUnauthorized, w, r, resp)
+				return
+			}
+
+			apiKey, ok := a.keys.Lookup(rawKey)
+			if !ok {
+				err := fmt.Errorf("invalid api key")
+				resp := respbuilder.Error(ctx, respbuilder.ErrUnauthorized, err)
+				respbuilder.WriteJSON(http.StatusUnauthorized, w, r, resp)
+				return
+			}
+
+			if !apiKey.Allow(scope) {
+				err := fmt.Errorf("api key '%s' does not have scope '%s'", apiKey.Name, scope)
+				resp := respbuilder.Error(ctx, respbuilder.ErrForbidden, err)
+				respbuilder.WriteJSON(http.StatusForbidden, w, r, resp)
+				return
+			}
+
+			ctx = context.WithValue(ctx, apiKeyCtxKey{}, apiKey)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+
+	return apikey.BearerToken(r.Header.Get("Authorization"))
+}
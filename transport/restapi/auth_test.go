@@ -0,0 +1,58 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+)
+
+func TestAuthenticator_Require(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	auth := newAuthenticator([]apikey.APIKey{
+		{Name: "sender", Key: "sender-key", Scopes: []apikey.Scope{apikey.ScopeMessagesSend}},
+	})
+
+	testCases := []struct {
+		Name   string
+		Header http.Header
+		Code   int
+	}{
+		{Name: "missing key", Header: http.Header{}, Code: http.StatusUnauthorized},
+		{Name: "invalid key", Header: http.Header{"X-Api-Key": {"wrong"}}, Code: http.StatusUnauthorized},
+		{Name: "no scope", Header: http.Header{"Authorization": {"Bearer sender-key"}}, Code: http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/pnp", nil)
+			r.Header = testCase.Header
+			w := httptest.NewRecorder()
+
+			auth.Require(apikey.ScopePnpRead)(next).ServeHTTP(w, r)
+			assert.Equal(t, testCase.Code, w.Code)
+		})
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/messages", nil)
+		r.Header.Set("X-API-Key", "sender-key")
+		w := httptest.NewRecorder()
+
+		auth.Require(apikey.ScopeMessagesSend)(next).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("auth disabled", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/apps", nil)
+		w := httptest.NewRecorder()
+
+		newAuthenticator(nil).Require(apikey.ScopeAppsRead)(next).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
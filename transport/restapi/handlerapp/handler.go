@@ -6,6 +6,7 @@ import (
 	"github.com/gorilla/schema"
 	"github.com/segmentio/encoding/json"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/pagination"
 	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
@@ -33,8 +34,9 @@ func NewHandler(conf HandlerConfig) (*Handler, error) {
 }
 
 type CreateAppReq struct {
-	ClientID string `json:"client_id"`
-	Name     string `json:"name"`
+	ClientID string                      `json:"client_id"`
+	Name     string                      `json:"name"`
+	Settings httptyped.AppSettingsEntity `json:"settings"`
 }
 
 type CreateAppResp struct {
@@ -74,6 +76,7 @@ func (h *Handler) CreateApp() func(http.ResponseWriter, *http.Request) {
 		createAppIn := appsvc.InputCreateApp{
 			ClientID: reqBody.ClientID,
 			Name:     reqBody.Name,
+			Settings: reqBody.Settings.ToSvc(),
 		}
 
 		createAppOut, err := h.Config.AppService.CreateApp(ctx, createAppIn)
@@ -164,19 +167,29 @@ func (h *Handler) PutApp() func(http.ResponseWriter, *http.Request) {
 	return handler
 }
 
+// ListAppsReq Cursor is from the links of ListAppsResp, Name and ClientID is prefix search.
+// Sort is one of id, name and created_at, prefixed with "-" for descending, i.e: -created_at
+// MinID and MaxID is translated into the cursor of id sort, same as gRPC, it cannot be used with Cursor and other Sort.
 type ListAppsReq struct {
-	Limit int64 `schema:"limit"`
-	MaxID int64 `schema:"max_id"`
-	MinID int64 `schema:"min_id"`
+	Limit     int64  `schema:"limit"`
+	Cursor    string `schema:"cursor"`
+	Sort      string `schema:"sort"`
+	Name      string `schema:"name"`
+	ClientID  string `schema:"client_id"`
+	WithTotal bool   `schema:"with_total"`
+	MinID     int64  `schema:"min_id"`
+	MaxID     int64  `schema:"max_id"`
 }
 
+// ListAppsResp Total is only returned when requested using with_total=true
 type ListAppsResp struct {
-	Total int64                 `json:"total"`
+	Total *int64                `json:"total,omitempty"`
 	Limit int64                 `json:"limit"`
 	Items []httptyped.AppEntity `json:"items"`
+	Links httptyped.PageLinks   `json:"links"`
 }
 
-// ListApps ListApp apps
+// ListApps ListApp apps using keyset pagination
 // Path          : GET /api/v1/apps
 // Request Query : ListAppsReq
 // Response      : ListAppsResp
@@ -202,10 +215,30 @@ func (h *Handler) ListApps() func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
+		cursor := query.Cursor
+		if query.MinID > 0 || query.MaxID > 0 {
+			if cursor != "" || (query.Sort != "" && query.Sort != "id") {
+				err = fmt.Errorf("min_id and max_id cannot be used with cursor or sort other than id")
+				resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+				respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+				return
+			}
+
+			cursor, err = pagination.IDCursor(query.MinID, query.MaxID)
+			if err != nil {
+				resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+				respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+				return
+			}
+		}
+
 		appListIn := appsvc.InputListApp{
-			Limit:    query.Limit,
-			BeforeID: query.MaxID,
-			AfterID:  query.MinID,
+			Limit:          query.Limit,
+			Cursor:         cursor,
+			Sort:           query.Sort,
+			NamePrefix:     query.Name,
+			ClientIDPrefix: query.ClientID,
+			WithTotal:      query.WithTotal,
 		}
 
 		listOut, err := h.Config.AppService.ListApp(ctx, appListIn)
@@ -221,9 +254,13 @@ func (h *Handler) ListApps() func(http.ResponseWriter, *http.Request) {
 		}
 
 		respBody := ListAppsResp{
-			Total: listOut.Total,
 			Limit: listOut.Limit,
 			Items: apps,
+			Links: httptyped.PageLinksFromReq(r, listOut.NextCursor, listOut.PrevCursor),
+		}
+
+		if query.WithTotal {
+			respBody.Total = &listOut.Total
 		}
 
 		resp := respbuilder.Success(ctx, respBody)
@@ -252,10 +289,9 @@ func (h *Handler) GetByClientID() func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		enabled := true
+		// suspended app is also returned, so the suspension reason can be seen
 		getAppIn := appsvc.InputGetApp{
 			ClientID: clientID,
-			Enabled:  &enabled,
 		}
 
 		getAppOut, err := h.Config.AppService.GetApp(ctx, getAppIn)
@@ -316,3 +352,219 @@ func (h *Handler) DelAppByClientID() func(http.ResponseWriter, *http.Request) {
 
 	return handler
 }
+
+type SuspendAppReq struct {
+	Reason string `json:"reason"`
+}
+
+type SuspendAppResp struct {
+	App httptyped.AppEntity `json:"app"`
+}
+
+// SuspendApp Suspend app with reason, message of suspended app is refused until it is resumed
+// Path         : POST /api/v1/apps/{client_id}/suspend
+// Request Body : SuspendAppReq
+// Response     : SuspendAppResp
+func (h *Handler) SuspendApp() func(http.ResponseWriter, *http.Request) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		clientID := strings.TrimSpace(chi.URLParam(r, "client_id"))
+		if !utf8.ValidString(clientID) {
+			err := fmt.Errorf("client id '%s' is not valid utf8", clientID)
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		if r.Body == nil {
+			err := fmt.Errorf("request body is nil")
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		defer func() {
+			if _err := r.Body.Close(); _err != nil {
+				ylog.Error(ctx, "cannot close request body", ylog.KV("error", _err))
+			}
+		}()
+
+		var reqBody SuspendAppReq
+		dec := json.NewDecoder(r.Body)
+		err := dec.Decode(&reqBody)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		suspendAppIn := appsvc.InputSuspendApp{
+			ClientID: clientID,
+			Reason:   strings.TrimSpace(reqBody.Reason),
+		}
+
+		suspendAppOut, err := h.Config.AppService.SuspendApp(ctx, suspendAppIn)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respBody := SuspendAppResp{
+			App: httptyped.AppEntityFromSvc(suspendAppOut.App),
+		}
+
+		resp := respbuilder.Success(ctx, respBody)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return handler
+}
+
+type ResumeAppResp struct {
+	App httptyped.AppEntity `json:"app"`
+}
+
+// ResumeApp Resume suspended app
+// Path          : POST /api/v1/apps/{client_id}/resume
+// Response      : ResumeAppResp
+func (h *Handler) ResumeApp() func(http.ResponseWriter, *http.Request) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		clientID := strings.TrimSpace(chi.URLParam(r, "client_id"))
+		if !utf8.ValidString(clientID) {
+			err := fmt.Errorf("client id '%s' is not valid utf8", clientID)
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		resumeAppIn := appsvc.InputResumeApp{
+			ClientID: clientID,
+		}
+
+		resumeAppOut, err := h.Config.AppService.ResumeApp(ctx, resumeAppIn)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respBody := ResumeAppResp{
+			App: httptyped.AppEntityFromSvc(resumeAppOut.App),
+		}
+
+		resp := respbuilder.Success(ctx, respBody)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return handler
+}
+
+type PutAppSettingsResp struct {
+	App httptyped.AppEntity `json:"app"`
+}
+
+// PutAppSettings Replace all settings of the app, field that not defined is reset to default
+// Path         : PUT /api/v1/apps/{client_id}/settings
+// Request Body : httptyped.AppSettingsEntity
+// Response     : PutAppSettingsResp
+func (h *Handler) PutAppSettings() func(http.ResponseWriter, *http.Request) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		clientID := strings.TrimSpace(chi.URLParam(r, "client_id"))
+		if !utf8.ValidString(clientID) {
+			err := fmt.Errorf("client id '%s' is not valid utf8", clientID)
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		if r.Body == nil {
+			err := fmt.Errorf("request body is nil")
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		defer func() {
+			if _err := r.Body.Close(); _err != nil {
+				ylog.Error(ctx, "cannot close request body", ylog.KV("error", _err))
+			}
+		}()
+
+		var reqBody httptyped.AppSettingsEntity
+		dec := json.NewDecoder(r.Body)
+		err := dec.Decode(&reqBody)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		putSettingsIn := appsvc.InputPutAppSettings{
+			ClientID: clientID,
+			Settings: reqBody.ToSvc(),
+		}
+
+		putSettingsOut, err := h.Config.AppService.PutAppSettings(ctx, putSettingsIn)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respBody := PutAppSettingsResp{
+			App: httptyped.AppEntityFromSvc(putSettingsOut.App),
+		}
+
+		resp := respbuilder.Success(ctx, respBody)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return handler
+}
+
+type RestoreAppByClientIDResp struct {
+	App httptyped.AppEntity `json:"app"`
+}
+
+// RestoreAppByClientID Restore deleted app and its push notification providers within the retention window
+// Path          : POST /api/v1/apps/{client_id}/restore
+// Response      : RestoreAppByClientIDResp
+func (h *Handler) RestoreAppByClientID() func(http.ResponseWriter, *http.Request) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		clientID := strings.TrimSpace(chi.URLParam(r, "client_id"))
+		if !utf8.ValidString(clientID) {
+			err := fmt.Errorf("client id '%s' is not valid utf8", clientID)
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		restoreAppIn := appsvc.InputRestoreApp{
+			ClientID: clientID,
+		}
+
+		restoreAppOut, err := h.Config.AppService.RestoreApp(ctx, restoreAppIn)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respBody := RestoreAppByClientIDResp{
+			App: httptyped.AppEntityFromSvc(restoreAppOut.App),
+		}
+
+		resp := respbuilder.Success(ctx, respBody)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return handler
+}
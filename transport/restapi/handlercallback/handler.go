@@ -0,0 +1,301 @@
+package handlercallback
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/schema"
+	"github.com/segmentio/encoding/json"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
+	"github.com/yusufsyaifudin/ylog"
+	"net/http"
+	"strconv"
+)
+
+type HandlerConfig struct {
+	AppService      appsvc.Service      `validate:"required"`
+	CallbackService callbacksvc.Service `validate:"required"`
+}
+
+type Handler struct {
+	Config HandlerConfig
+}
+
+func NewHandler(conf HandlerConfig) (*Handler, error) {
+	err := validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{Config: conf}, nil
+}
+
+type ReqQueryParam struct {
+	ClientID string `schema:"client_id"`
+}
+
+type RegisterReq struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"` // optional, generated when empty
+	Events []string `json:"events"` // task.completed, provider.failed, token.invalid
+}
+
+type RegisterResp struct {
+	App      httptyped.AppEntity      `json:"app"`
+	Callback httptyped.CallbackEntity `json:"callback"`
+	Secret   string                   `json:"secret"` // only shown once, use it to verify the signature header
+}
+
+// Register new callback url under the app.
+// Path          : POST /api/v1/callbacks?client_id={client_id}
+// Request Body  : RegisterReq
+// Response      : RegisterResp
+func (h *Handler) Register() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		reqBody, err := decodeBody(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outRegister, err := h.Config.CallbackService.Register(ctx, callbacksvc.InRegister{
+			AppID:  app.ID,
+			URL:    reqBody.URL,
+			Secret: reqBody.Secret,
+			Events: reqBody.Events,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respData := RegisterResp{
+			App:      httptyped.AppEntityFromSvc(app),
+			Callback: httptyped.CallbackEntityFromSvc(outRegister.Callback),
+			Secret:   outRegister.Callback.Secret,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusCreated, w, r, resp)
+	}
+
+	return fn
+}
+
+type ListResp struct {
+	App   httptyped.AppEntity        `json:"app"`
+	Items []httptyped.CallbackEntity `json:"items"`
+}
+
+// List all callbacks under the app.
+// Path          : GET /api/v1/callbacks?client_id={client_id}
+// Response      : ListResp
+func (h *Handler) List() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outList, err := h.Config.CallbackService.List(ctx, callbacksvc.InList{
+			AppID: app.ID,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		items := make([]httptyped.CallbackEntity, 0)
+		for _, callback := range outList.Callbacks {
+			items = append(items, httptyped.CallbackEntityFromSvc(callback))
+		}
+
+		respData := ListResp{
+			App:   httptyped.AppEntityFromSvc(app),
+			Items: items,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type DeleteResp struct {
+	Success bool `json:"success"`
+}
+
+// Delete one callback, its delivery log is deleted too.
+// Path          : DELETE /api/v1/callbacks/{callback_id}?client_id={client_id}
+// Response      : DeleteResp
+func (h *Handler) Delete() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		callbackID, err := strconv.ParseInt(chi.URLParam(r, "callback_id"), 10, 64)
+		if err != nil {
+			err = fmt.Errorf("callback id must be integer: %w", err)
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outDel, err := h.Config.CallbackService.Delete(ctx, callbacksvc.InDelete{
+			AppID: app.ID,
+			ID:    callbackID,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		resp := respbuilder.Success(ctx, DeleteResp{Success: outDel.Success})
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type ListDeliveriesReq struct {
+	CallbackID int64 `schema:"callback_id"`
+	MaxID      int64 `schema:"max_id"`
+	Limit      int64 `schema:"limit"`
+}
+
+type ListDeliveriesResp struct {
+	App   httptyped.AppEntity                `json:"app"`
+	Limit int64                              `json:"limit"`
+	Items []httptyped.CallbackDeliveryEntity `json:"items"`
+}
+
+// ListDeliveries list the delivery log under the app, newest first.
+// Use the smallest id as max_id to get the next page.
+// Path          : GET /api/v1/callbacks/deliveries?client_id={client_id}&callback_id={callback_id}&max_id={max_id}&limit={limit}
+// Response      : ListDeliveriesResp
+func (h *Handler) ListDeliveries() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		query := ListDeliveriesReq{}
+		queryDec := schema.NewDecoder()
+		queryDec.IgnoreUnknownKeys(true)
+		err = queryDec.Decode(&query, r.Form)
+		if err != nil {
+			err = fmt.Errorf("failed decode query params: %w", err)
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outList, err := h.Config.CallbackService.ListDeliveries(ctx, callbacksvc.InListDeliveries{
+			AppID:      app.ID,
+			CallbackID: query.CallbackID,
+			BeforeID:   query.MaxID,
+			Limit:      query.Limit,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		items := make([]httptyped.CallbackDeliveryEntity, 0)
+		for _, delivery := range outList.Deliveries {
+			items = append(items, httptyped.CallbackDeliveryEntityFromSvc(delivery))
+		}
+
+		respData := ListDeliveriesResp{
+			App:   httptyped.AppEntityFromSvc(app),
+			Limit: outList.Limit,
+			Items: items,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+// getApp return the app using client_id query param.
+func (h *Handler) getApp(r *http.Request) (app appsvc.App, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		err = fmt.Errorf("failed parse form: %w", err)
+		return
+	}
+
+	query := ReqQueryParam{}
+	queryDec := schema.NewDecoder()
+	queryDec.IgnoreUnknownKeys(true)
+	err = queryDec.Decode(&query, r.Form)
+	if err != nil {
+		err = fmt.Errorf("failed decode query params: %w", err)
+		return
+	}
+
+	enabled := true
+	getAppOut, err := h.Config.AppService.GetApp(r.Context(), appsvc.InputGetApp{
+		ClientID: query.ClientID,
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		return
+	}
+
+	app = getAppOut.App
+	return
+}
+
+func decodeBody(r *http.Request) (reqBody RegisterReq, err error) {
+	if r.Body == nil {
+		err = fmt.Errorf("request body is nil")
+		return
+	}
+
+	defer func() {
+		if _err := r.Body.Close(); _err != nil {
+			ylog.Error(r.Context(), "cannot close request body", ylog.KV("error", _err))
+		}
+	}()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&reqBody)
+	return
+}
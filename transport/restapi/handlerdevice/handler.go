@@ -0,0 +1,223 @@
+package handlerdevice
+
+import (
+	"fmt"
+	"github.com/gorilla/schema"
+	"github.com/segmentio/encoding/json"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
+	"github.com/yusufsyaifudin/ylog"
+	"net/http"
+)
+
+type HandlerConfig struct {
+	AppService    appsvc.Service    `validate:"required"`
+	DeviceService devicesvc.Service `validate:"required"`
+}
+
+type Handler struct {
+	Config HandlerConfig
+}
+
+func NewHandler(conf HandlerConfig) (*Handler, error) {
+	err := validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{Config: conf}, nil
+}
+
+type ReqQueryParam struct {
+	ClientID string `schema:"client_id"`
+	UserID   string `schema:"user_id"`
+	Provider string `schema:"provider"`
+	Token    string `schema:"token"`
+}
+
+type RegisterReq struct {
+	UserID   string `json:"user_id"`
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+	Platform string `json:"platform"` // android, ios or web
+	Locale   string `json:"locale"`
+}
+
+type RegisterResp struct {
+	App    httptyped.AppEntity    `json:"app"`
+	Device httptyped.DeviceEntity `json:"device"`
+}
+
+// Register device token to the user, re-register the same token will update the last seen time.
+// Path          : POST /api/v1/devices?client_id={client_id}
+// Request Body  : RegisterReq
+// Response      : RegisterResp
+func (h *Handler) Register() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		_, app, err := h.parseQuery(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		if r.Body == nil {
+			err = fmt.Errorf("request body is nil")
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		defer func() {
+			if _err := r.Body.Close(); _err != nil {
+				ylog.Error(ctx, "cannot close request body", ylog.KV("error", _err))
+			}
+		}()
+
+		var reqBody RegisterReq
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&reqBody)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outRegister, err := h.Config.DeviceService.Register(ctx, devicesvc.InRegister{
+			AppID:  app.ID,
+			Device: devicesvc.InDevice(reqBody),
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respData := RegisterResp{
+			App:    httptyped.AppEntityFromSvc(app),
+			Device: httptyped.DeviceEntityFromSvc(outRegister.Device),
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusCreated, w, r, resp)
+	}
+
+	return fn
+}
+
+type ListByUserResp struct {
+	App   httptyped.AppEntity      `json:"app"`
+	Items []httptyped.DeviceEntity `json:"items"`
+}
+
+// ListByUser list all devices owned by user.
+// Path          : GET /api/v1/devices?client_id={client_id}&user_id={user_id}
+// Response      : ListByUserResp
+func (h *Handler) ListByUser() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, app, err := h.parseQuery(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outList, err := h.Config.DeviceService.ListByUser(ctx, devicesvc.InListByUser{
+			AppID:  app.ID,
+			UserID: query.UserID,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		items := make([]httptyped.DeviceEntity, 0)
+		for _, device := range outList.Devices {
+			items = append(items, httptyped.DeviceEntityFromSvc(device))
+		}
+
+		respData := ListByUserResp{
+			App:   httptyped.AppEntityFromSvc(app),
+			Items: items,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type UnregisterResp struct {
+	Success bool `json:"success"`
+}
+
+// Unregister remove device token, i.e: when user logout.
+// Path          : DELETE /api/v1/devices?client_id={client_id}&provider={provider}&token={token}
+// Response      : UnregisterResp
+func (h *Handler) Unregister() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, app, err := h.parseQuery(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outUnregister, err := h.Config.DeviceService.Unregister(ctx, devicesvc.InUnregister{
+			AppID:    app.ID,
+			Provider: query.Provider,
+			Token:    query.Token,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		resp := respbuilder.Success(ctx, UnregisterResp{Success: outUnregister.Success})
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+// parseQuery decode query params and get the app using client_id query param.
+func (h *Handler) parseQuery(r *http.Request) (query ReqQueryParam, app appsvc.App, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		err = fmt.Errorf("failed parse form: %w", err)
+		return
+	}
+
+	queryDec := schema.NewDecoder()
+	queryDec.IgnoreUnknownKeys(true)
+	err = queryDec.Decode(&query, r.Form)
+	if err != nil {
+		err = fmt.Errorf("failed decode query params: %w", err)
+		return
+	}
+
+	enabled := true
+	getAppOut, err := h.Config.AppService.GetApp(r.Context(), appsvc.InputGetApp{
+		ClientID: query.ClientID,
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		return
+	}
+
+	app = getAppOut.App
+	return
+}
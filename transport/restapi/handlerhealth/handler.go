@@ -0,0 +1,59 @@
+package handlerhealth
+
+import (
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"net/http"
+)
+
+type HandlerConfig struct {
+	Health *health.Health `validate:"required"`
+}
+
+type Handler struct {
+	Config HandlerConfig
+}
+
+func NewHandler(conf HandlerConfig) (*Handler, error) {
+	err := validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{Config: conf}, nil
+}
+
+type LiveResp struct {
+	Status string `json:"status"`
+}
+
+// Live only tells that the process is running, it never checks any dependencies,
+// so the orchestrator will not restart the process when the dependency is down.
+// Path          : GET /health/live
+// Response      : LiveResp
+func (h *Handler) Live() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		respbuilder.WriteJSON(http.StatusOK, w, r, LiveResp{Status: health.StatusUp})
+	}
+
+	return fn
+}
+
+// Ready checks all dependencies and return 503 Service Unavailable when one of them is down.
+// Path          : GET /health/ready
+// Response      : health.Report
+func (h *Handler) Ready() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		report := h.Config.Health.Check(r.Context())
+
+		httpStatus := http.StatusOK
+		if !report.Up() {
+			httpStatus = http.StatusServiceUnavailable
+		}
+
+		respbuilder.WriteJSON(httpStatus, w, r, report)
+	}
+
+	return fn
+}
@@ -1,18 +1,31 @@
 package handlermsg
 
 import (
+	"context"
+	"errors"
 	"github.com/segmentio/encoding/json"
+	"github.com/yusufsyaifudin/ngendika/backend"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
 	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
+	"github.com/yusufsyaifudin/ylog"
 	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"time"
 )
 
+// DefaultStreamTimeout is the deadline of SendMessageStream when StreamTimeout is not configured.
+const DefaultStreamTimeout = 5 * time.Minute
+
 type HandlerConfig struct {
 	MsgServiceProcessor msgsvc.Service `validate:"required"`
+
+	// StreamTimeout is deadline of SendMessageStream, which is exempted from the request timeout
+	// because it is written as long as the process runs. Default DefaultStreamTimeout.
+	StreamTimeout time.Duration `validate:"min=0"`
 }
 
 type Handler struct {
@@ -25,6 +38,10 @@ func NewHandler(cfg HandlerConfig) (*Handler, error) {
 		return nil, err
 	}
 
+	if cfg.StreamTimeout <= 0 {
+		cfg.StreamTimeout = DefaultStreamTimeout
+	}
+
 	return &Handler{Config: cfg}, nil
 }
 
@@ -33,6 +50,17 @@ type SendMessageReq struct {
 	ClientID string                   `json:"client_id"`
 	Label    string                   `json:"label"`
 	Payloads map[string][]interface{} `json:"payloads"` // fcm:[{}, {}]
+
+	// Template will be rendered and appended to the payloads of each provider in the template
+	TemplateID int64                  `json:"template_id,omitempty"`
+	Variables  map[string]interface{} `json:"variables,omitempty"`
+	Locale     string                 `json:"locale,omitempty"`
+
+	// UserIDs is resolved into registered devices and merged into recipients of each payload
+	UserIDs []string `json:"user_ids,omitempty"`
+
+	// To is list of target, i.e: ["topic:news", "user:123"]
+	To []string `json:"to,omitempty"`
 }
 
 type SendMessageResp struct {
@@ -50,27 +78,18 @@ func (h *Handler) SendMessage() func(http.ResponseWriter, *http.Request) {
 		ctx, span = tracer.StartSpan(ctx, "handlermsg.SendMessage")
 		defer span.End()
 
-		var reqBody SendMessageReq
-		dec := json.NewDecoder(r.Body)
-		dec.DisallowUnknownFields()
-		err := dec.Decode(&reqBody)
+		processMsgIn, err := decodeSendMessageReq(r)
 		if err != nil {
 			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
 			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
 			return
 		}
 
-		processMsgIn := &msgsvc.InputProcess{
-			TaskID:   reqBody.TaskID,
-			ClientID: reqBody.ClientID,
-			Label:    reqBody.Label,
-			Payloads: reqBody.Payloads,
-		}
-
 		processMsgOut, processMsgErr := h.Config.MsgServiceProcessor.Process(ctx, processMsgIn)
 		if processMsgErr != nil {
-			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, processMsgErr)
-			respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+			errKind, status := processErrKind(processMsgErr)
+			resp := respbuilder.Error(ctx, errKind, processMsgErr)
+			respbuilder.WriteJSON(status, w, r, resp)
 			return
 		}
 
@@ -86,3 +105,141 @@ func (h *Handler) SendMessage() func(http.ResponseWriter, *http.Request) {
 		return
 	}
 }
+
+// ProgressEvent is the data of event "progress", sent every time one message is sent to the push notification provider.
+type ProgressEvent struct {
+	PNPID         int64           `json:"pnp_id"`
+	Provider      string          `json:"provider"`
+	Label         string          `json:"label"`
+	BackendError  string          `json:"backend_error,omitempty"`
+	BackendReport *backend.Report `json:"backend_report,omitempty"`
+}
+
+// SendMessageStream same as SendMessage, but the progress is streamed as soon as the sender worker produces it.
+// The response format is Server-Sent Events when the request has header "Accept: text/event-stream",
+// otherwise newline delimited JSON (application/x-ndjson) with each line {"event": "...", "data": {...}}.
+// Events:
+//   - progress : ProgressEvent, one per sent message
+//   - summary  : SendMessageResp in success envelope, always the last event when succeed
+//   - error    : error envelope, the last event when the message cannot be processed
+//
+// Path         : POST /api/v1/messages/stream
+// Request Body : SendMessageReq
+// Response     : stream of events
+func (h *Handler) SendMessageStream() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var span trace.Span
+		ctx, span = tracer.StartSpan(ctx, "handlermsg.SendMessageStream")
+		defer span.End()
+
+		// the sender worker require deadline, and the request timeout is not applied on stream route
+		ctx, cancel := context.WithTimeout(ctx, h.Config.StreamTimeout)
+		defer cancel()
+
+		processMsgIn, err := decodeSendMessageReq(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		events, err := newEventWriter(w, r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusInternalServerError, w, r, resp)
+			return
+		}
+
+		// OnProgress is called serially, so it is safe to write the event and set writeErr
+		var writeErr error
+		processMsgIn.OnProgress = func(progress msgsvc.Progress) {
+			if writeErr != nil {
+				return
+			}
+
+			writeErr = events.Write(eventProgress, ProgressEvent{
+				PNPID:         progress.PNP.ID,
+				Provider:      progress.PNP.Provider,
+				Label:         progress.PNP.Label,
+				BackendError:  progress.BackendError,
+				BackendReport: progress.BackendReport,
+			})
+		}
+
+		processMsgOut, processMsgErr := h.Config.MsgServiceProcessor.Process(ctx, processMsgIn)
+		if writeErr != nil {
+			ylog.Error(ctx, "cannot write progress event", ylog.KV("error", writeErr))
+			return
+		}
+
+		if processMsgErr != nil {
+			errKind, _ := processErrKind(processMsgErr)
+			resp := respbuilder.Error(ctx, errKind, processMsgErr)
+			if _err := events.Write(eventError, resp); _err != nil {
+				ylog.Error(ctx, "cannot write error event", ylog.KV("error", _err))
+			}
+
+			return
+		}
+
+		respBody := SendMessageResp{
+			TaskID:  processMsgOut.TaskID,
+			App:     httptyped.AppEntityFromSvc(processMsgOut.App),
+			Errors:  processMsgOut.Errors,
+			Reports: processMsgOut.ReportGroup,
+		}
+
+		if _err := events.Write(eventSummary, respbuilder.Success(ctx, respBody)); _err != nil {
+			ylog.Error(ctx, "cannot write summary event", ylog.KV("error", _err))
+		}
+	}
+}
+
+// processErrKind return respbuilder.ErrAppSuspended with status forbidden when the app is suspended,
+// the app settings violation with its own error kind, and invalid input as validation error with status bad request.
+// Other error is unhandled with status OK, so the client read the error from the response body.
+func processErrKind(err error) (respbuilder.ErrKind, int) {
+	switch {
+	case errors.Is(err, msgsvc.ErrValidation):
+		return respbuilder.ErrValidation, http.StatusBadRequest
+	case errors.Is(err, appsvc.ErrAppSuspended):
+		return respbuilder.ErrAppSuspended, http.StatusForbidden
+	case errors.Is(err, appsvc.ErrProviderNotAllowed):
+		return respbuilder.ErrProviderNotAllowed, http.StatusForbidden
+	case errors.Is(err, appsvc.ErrQuotaExceeded):
+		return respbuilder.ErrQuotaExceeded, http.StatusTooManyRequests
+	}
+
+	return respbuilder.ErrUnhandled, http.StatusOK
+}
+
+// decodeSendMessageReq decode SendMessageReq from request body into msgsvc.InputProcess.
+func decodeSendMessageReq(r *http.Request) (*msgsvc.InputProcess, error) {
+	var reqBody SendMessageReq
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, topics, err := msgsvc.ParseTargets(reqBody.UserIDs, reqBody.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &msgsvc.InputProcess{
+		TaskID:   reqBody.TaskID,
+		ClientID: reqBody.ClientID,
+		Label:    reqBody.Label,
+		Payloads: reqBody.Payloads,
+
+		TemplateID:   reqBody.TemplateID,
+		TemplateVars: reqBody.Variables,
+		Locale:       reqBody.Locale,
+		UserIDs:      userIDs,
+		Topics:       topics,
+	}, nil
+}
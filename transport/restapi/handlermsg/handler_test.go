@@ -0,0 +1,124 @@
+package handlermsg
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/ngendika/backend"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/pnpsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+)
+
+const testProvider = "noop_handlermsg_test"
+
+// stubAppSvc only implement the methods called by msgsvc.Process, other methods panic.
+type stubAppSvc struct {
+	appsvc.Service
+	app appsvc.App
+}
+
+func (s *stubAppSvc) GetApp(_ context.Context, _ appsvc.InputGetApp) (appsvc.OutGetApp, error) {
+	return appsvc.OutGetApp{App: s.app}, nil
+}
+
+func (s *stubAppSvc) UseDailyQuota(_ context.Context, in appsvc.InputUseDailyQuota) (appsvc.OutUseDailyQuota, error) {
+	return appsvc.OutUseDailyQuota{Used: in.Count}, nil
+}
+
+type stubPNProviderSvc struct {
+	pnpsvc.Service
+	pnps []backend.PushNotificationProvider
+}
+
+func (s *stubPNProviderSvc) GetByLabels(_ context.Context, _ pnpsvc.InGetByLabels) (pnpsvc.OutGetByLabels, error) {
+	return pnpsvc.OutGetByLabels{PnProviders: s.pnps}, nil
+}
+
+type stubCallbackSvc struct {
+	callbacksvc.Service
+}
+
+func (s *stubCallbackSvc) Emit(_ context.Context, in callbacksvc.InEmit) (callbacksvc.OutEmit, error) {
+	return callbacksvc.OutEmit{Queued: len(in.Events)}, nil
+}
+
+// stubTemplateSvc, stubDeviceSvc and stubTopicSvc is not called since the request has no template and targets.
+type stubTemplateSvc struct{ templatesvc.Service }
+type stubDeviceSvc struct{ devicesvc.Service }
+type stubTopicSvc struct{ topicsvc.Service }
+
+func TestHandler_SendMessageStream(t *testing.T) {
+	// the mux is global, the provider may already registered by the previous run of -count
+	if err := backend.Register(testProvider, backend.NewNoopSender()); err != nil {
+		assert.ErrorIs(t, err, backend.ErrProviderAlreadyRegistered)
+	}
+
+	msgSvc, err := msgsvc.New(msgsvc.SvcSyncConfig{
+		AppSvc: &stubAppSvc{app: appsvc.App{ID: 1, ClientID: "app1", Name: "app1", Enabled: true}},
+		PNProviderSvc: &stubPNProviderSvc{pnps: []backend.PushNotificationProvider{
+			{ID: 1, AppID: 1, Provider: testProvider, Label: "default", CredentialJSON: "{}"},
+		}},
+		TemplateSvc: &stubTemplateSvc{},
+		DeviceSvc:   &stubDeviceSvc{},
+		TopicSvc:    &stubTopicSvc{},
+		CallbackSvc: &stubCallbackSvc{},
+		PNSender:    backend.MuxBackend(),
+		MaxBuffer:   1, // smaller than the number of messages, so the progress must not block the worker
+		MaxWorker:   1,
+	})
+	assert.NoError(t, err)
+
+	h, err := NewHandler(HandlerConfig{MsgServiceProcessor: msgSvc})
+	assert.NoError(t, err)
+
+	reqBody := `{"task_id":"task-1","client_id":"app1","label":"default","payloads":{"` + testProvider + `":[{"a":1},{"a":2},{"a":3}]}}`
+
+	// request without deadline, the same as the stream route which is exempted from the request timeout
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/messages/stream", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	h.SendMessageStream()(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	type line struct {
+		Event string          `json:"event"`
+		Data  json.RawMessage `json:"data"`
+	}
+
+	lines := make([]line, 0)
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var l line
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &l))
+		lines = append(lines, l)
+	}
+
+	if !assert.Len(t, lines, 4) {
+		return
+	}
+
+	for _, l := range lines[:3] {
+		assert.Equal(t, eventProgress, l.Event)
+
+		var progress ProgressEvent
+		assert.NoError(t, json.Unmarshal(l.Data, &progress))
+		assert.Empty(t, progress.BackendError)
+		if assert.NotNil(t, progress.BackendReport) {
+			assert.Equal(t, 1, progress.BackendReport.SuccessCount)
+		}
+	}
+
+	assert.Equal(t, eventSummary, lines[3].Event)
+}
@@ -0,0 +1,89 @@
+package handlermsg
+
+import (
+	"fmt"
+	"github.com/segmentio/encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	eventProgress = "progress"
+	eventSummary  = "summary"
+	eventError    = "error"
+)
+
+// eventWriter write one event and flush it immediately to the client.
+type eventWriter interface {
+	Write(event string, data interface{}) error
+}
+
+// newEventWriter write the response header, then return Server-Sent Events writer when client accept text/event-stream,
+// otherwise newline delimited JSON writer.
+func newEventWriter(w http.ResponseWriter, r *http.Request) (eventWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming response is not supported")
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disable buffering on nginx reverse proxy
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		return &sseWriter{w: w, flusher: flusher}, nil
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &ndjsonWriter{w: w, flusher: flusher}, nil
+}
+
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *sseWriter) Write(event string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cannot marshal event '%s': %w", event, err)
+	}
+
+	if _, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+		return fmt.Errorf("cannot write event '%s': %w", event, err)
+	}
+
+	s.flusher.Flush()
+	return nil
+}
+
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (n *ndjsonWriter) Write(event string, data interface{}) error {
+	line := struct {
+		Event string      `json:"event"`
+		Data  interface{} `json:"data"`
+	}{
+		Event: event,
+		Data:  data,
+	}
+
+	b, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("cannot marshal event '%s': %w", event, err)
+	}
+
+	if _, err = n.w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("cannot write event '%s': %w", event, err)
+	}
+
+	n.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,37 @@
+package handlermsg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEventWriter(t *testing.T) {
+	t.Run("server-sent events", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/messages/stream", nil)
+		r.Header.Set("Accept", "text/event-stream")
+		w := httptest.NewRecorder()
+
+		events, err := newEventWriter(w, r)
+		assert.NoError(t, err)
+		assert.NoError(t, events.Write(eventProgress, map[string]int{"pnp_id": 1}))
+
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "event: progress\ndata: {\"pnp_id\":1}\n\n", w.Body.String())
+	})
+
+	t.Run("newline delimited json", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/messages/stream", nil)
+		w := httptest.NewRecorder()
+
+		events, err := newEventWriter(w, r)
+		assert.NoError(t, err)
+		assert.NoError(t, events.Write(eventProgress, map[string]int{"pnp_id": 1}))
+		assert.NoError(t, events.Write(eventSummary, map[string]string{"task_id": "a"}))
+
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"event\":\"progress\",\"data\":{\"pnp_id\":1}}\n{\"event\":\"summary\",\"data\":{\"task_id\":\"a\"}}\n", w.Body.String())
+	})
+}
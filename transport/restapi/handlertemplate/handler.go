@@ -0,0 +1,335 @@
+package handlertemplate
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/schema"
+	"github.com/segmentio/encoding/json"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
+	"github.com/yusufsyaifudin/ylog"
+	"net/http"
+	"strconv"
+)
+
+type HandlerConfig struct {
+	AppService      appsvc.Service      `validate:"required"`
+	TemplateService templatesvc.Service `validate:"required"`
+}
+
+type Handler struct {
+	Config HandlerConfig
+}
+
+func NewHandler(conf HandlerConfig) (*Handler, error) {
+	err := validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{Config: conf}, nil
+}
+
+type ReqQueryParam struct {
+	ClientID string `schema:"client_id"`
+}
+
+type TemplateReq struct {
+	Name          string                    `json:"name"`
+	Engine        string                    `json:"engine"`         // text or html
+	DefaultLocale string                    `json:"default_locale"` // i.e: en
+	Variants      map[string]map[string]any `json:"variants"`       // locale => provider => payload skeleton
+}
+
+type TemplateResp struct {
+	App      httptyped.AppEntity      `json:"app"`
+	Template httptyped.TemplateEntity `json:"template"`
+}
+
+// Create new message template under the app.
+// Path          : POST /api/v1/templates?client_id={client_id}
+// Request Body  : TemplateReq
+// Response      : TemplateResp
+func (h *Handler) Create() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, code, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(code, w, r, resp)
+			return
+		}
+
+		reqBody, err := decodeBody(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outCreate, err := h.Config.TemplateService.Create(ctx, templatesvc.InCreate{
+			AppID:    app.ID,
+			Template: templatesvc.InTemplate(reqBody),
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respData := TemplateResp{
+			App:      httptyped.AppEntityFromSvc(app),
+			Template: httptyped.TemplateEntityFromSvc(outCreate.Template),
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusCreated, w, r, resp)
+	}
+
+	return fn
+}
+
+// Update replace entire message template.
+// Path          : PUT /api/v1/templates/{template_id}?client_id={client_id}
+// Request Body  : TemplateReq
+// Response      : TemplateResp
+func (h *Handler) Update() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, code, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(code, w, r, resp)
+			return
+		}
+
+		templateID, err := templateIDParam(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		reqBody, err := decodeBody(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outUpdate, err := h.Config.TemplateService.Update(ctx, templatesvc.InUpdate{
+			AppID:    app.ID,
+			ID:       templateID,
+			Template: templatesvc.InTemplate(reqBody),
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respData := TemplateResp{
+			App:      httptyped.AppEntityFromSvc(app),
+			Template: httptyped.TemplateEntityFromSvc(outUpdate.Template),
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+// Get one message template.
+// Path          : GET /api/v1/templates/{template_id}?client_id={client_id}
+// Response      : TemplateResp
+func (h *Handler) Get() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, code, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(code, w, r, resp)
+			return
+		}
+
+		templateID, err := templateIDParam(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outGet, err := h.Config.TemplateService.Get(ctx, templatesvc.InGet{
+			AppID: app.ID,
+			ID:    templateID,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrResourceNotFound, err)
+			respbuilder.WriteJSON(http.StatusNotFound, w, r, resp)
+			return
+		}
+
+		respData := TemplateResp{
+			App:      httptyped.AppEntityFromSvc(app),
+			Template: httptyped.TemplateEntityFromSvc(outGet.Template),
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type ListResp struct {
+	App   httptyped.AppEntity        `json:"app"`
+	Items []httptyped.TemplateEntity `json:"items"`
+}
+
+// List all message templates under the app.
+// Path          : GET /api/v1/templates?client_id={client_id}
+// Response      : ListResp
+func (h *Handler) List() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, code, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(code, w, r, resp)
+			return
+		}
+
+		outList, err := h.Config.TemplateService.List(ctx, templatesvc.InList{
+			AppID: app.ID,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		items := make([]httptyped.TemplateEntity, 0)
+		for _, template := range outList.Templates {
+			items = append(items, httptyped.TemplateEntityFromSvc(template))
+		}
+
+		respData := ListResp{
+			App:   httptyped.AppEntityFromSvc(app),
+			Items: items,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type DeleteResp struct {
+	Success bool `json:"success"`
+}
+
+// Delete one message template.
+// Path          : DELETE /api/v1/templates/{template_id}?client_id={client_id}
+// Response      : DeleteResp
+func (h *Handler) Delete() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, code, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(code, w, r, resp)
+			return
+		}
+
+		templateID, err := templateIDParam(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outDel, err := h.Config.TemplateService.Delete(ctx, templatesvc.InDelete{
+			AppID: app.ID,
+			ID:    templateID,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		resp := respbuilder.Success(ctx, DeleteResp{Success: outDel.Success})
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+// getApp return the app from client_id query param and the HTTP status code to use when error.
+func (h *Handler) getApp(r *http.Request) (app appsvc.App, code int, err error) {
+	code = http.StatusBadRequest
+
+	err = r.ParseForm()
+	if err != nil {
+		err = fmt.Errorf("failed parse form: %w", err)
+		return
+	}
+
+	query := ReqQueryParam{}
+	queryDec := schema.NewDecoder()
+	queryDec.IgnoreUnknownKeys(true)
+	err = queryDec.Decode(&query, r.Form)
+	if err != nil {
+		err = fmt.Errorf("failed decode query params: %w", err)
+		return
+	}
+
+	enabled := true
+	getAppOut, err := h.Config.AppService.GetApp(r.Context(), appsvc.InputGetApp{
+		ClientID: query.ClientID,
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		return
+	}
+
+	app = getAppOut.App
+	return
+}
+
+func templateIDParam(r *http.Request) (int64, error) {
+	templateID, err := strconv.ParseInt(chi.URLParam(r, "template_id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("template id must be integer: %w", err)
+	}
+
+	return templateID, nil
+}
+
+func decodeBody(r *http.Request) (reqBody TemplateReq, err error) {
+	if r.Body == nil {
+		err = fmt.Errorf("request body is nil")
+		return
+	}
+
+	defer func() {
+		if _err := r.Body.Close(); _err != nil {
+			ylog.Error(r.Context(), "cannot close request body", ylog.KV("error", _err))
+		}
+	}()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&reqBody)
+	return
+}
@@ -0,0 +1,247 @@
+package handlertopic
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/schema"
+	"github.com/segmentio/encoding/json"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/respbuilder"
+	"github.com/yusufsyaifudin/ngendika/pkg/validator"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/httptyped"
+	"github.com/yusufsyaifudin/ylog"
+	"net/http"
+)
+
+type HandlerConfig struct {
+	AppService   appsvc.Service   `validate:"required"`
+	TopicService topicsvc.Service `validate:"required"`
+}
+
+type Handler struct {
+	Config HandlerConfig
+}
+
+func NewHandler(conf HandlerConfig) (*Handler, error) {
+	err := validator.Validate(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{Config: conf}, nil
+}
+
+type ReqQueryParam struct {
+	ClientID string `schema:"client_id"`
+}
+
+type MemberRecipientReq struct {
+	Provider  string `json:"provider"`
+	Recipient string `json:"recipient"`
+}
+
+type MembersReq struct {
+	UserIDs    []string             `json:"user_ids"`
+	Recipients []MemberRecipientReq `json:"recipients"`
+}
+
+type SubscribeResp struct {
+	Topic      string `json:"topic"`
+	Subscribed int64  `json:"subscribed"`
+}
+
+// Subscribe users or recipients to the topic.
+// Path          : POST /api/v1/topics/{topic}/subscribe?client_id={client_id}
+// Request Body  : MembersReq
+// Response      : SubscribeResp
+func (h *Handler) Subscribe() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		members, err := decodeMembers(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		topic := chi.URLParam(r, "topic")
+		outSubscribe, err := h.Config.TopicService.Subscribe(ctx, topicsvc.InSubscribe{
+			AppID:   app.ID,
+			Topic:   topic,
+			Members: members,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respData := SubscribeResp{
+			Topic:      topicsvc.TrimTopic(topic),
+			Subscribed: outSubscribe.Subscribed,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type UnsubscribeResp struct {
+	Topic        string `json:"topic"`
+	Unsubscribed int64  `json:"unsubscribed"`
+}
+
+// Unsubscribe users or recipients from the topic.
+// Path          : POST /api/v1/topics/{topic}/unsubscribe?client_id={client_id}
+// Request Body  : MembersReq
+// Response      : UnsubscribeResp
+func (h *Handler) Unsubscribe() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		members, err := decodeMembers(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrValidation, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		topic := chi.URLParam(r, "topic")
+		outUnsubscribe, err := h.Config.TopicService.Unsubscribe(ctx, topicsvc.InUnsubscribe{
+			AppID:   app.ID,
+			Topic:   topic,
+			Members: members,
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		respData := UnsubscribeResp{
+			Topic:        topicsvc.TrimTopic(topic),
+			Unsubscribed: outUnsubscribe.Unsubscribed,
+		}
+
+		resp := respbuilder.Success(ctx, respData)
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+type ListMembersResp struct {
+	Items []httptyped.TopicMemberEntity `json:"items"`
+}
+
+// ListMembers list all members of the topic.
+// Path          : GET /api/v1/topics/{topic}/members?client_id={client_id}
+// Response      : ListMembersResp
+func (h *Handler) ListMembers() func(http.ResponseWriter, *http.Request) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		app, err := h.getApp(r)
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		outList, err := h.Config.TopicService.ListMembers(ctx, topicsvc.InListMembers{
+			AppID: app.ID,
+			Topic: chi.URLParam(r, "topic"),
+		})
+		if err != nil {
+			resp := respbuilder.Error(ctx, respbuilder.ErrUnhandled, err)
+			respbuilder.WriteJSON(http.StatusBadRequest, w, r, resp)
+			return
+		}
+
+		items := make([]httptyped.TopicMemberEntity, 0)
+		for _, member := range outList.Members {
+			items = append(items, httptyped.TopicMemberEntityFromSvc(member))
+		}
+
+		resp := respbuilder.Success(ctx, ListMembersResp{Items: items})
+		respbuilder.WriteJSON(http.StatusOK, w, r, resp)
+	}
+
+	return fn
+}
+
+// getApp return the app using client_id query param.
+func (h *Handler) getApp(r *http.Request) (app appsvc.App, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		err = fmt.Errorf("failed parse form: %w", err)
+		return
+	}
+
+	query := ReqQueryParam{}
+	queryDec := schema.NewDecoder()
+	queryDec.IgnoreUnknownKeys(true)
+	err = queryDec.Decode(&query, r.Form)
+	if err != nil {
+		err = fmt.Errorf("failed decode query params: %w", err)
+		return
+	}
+
+	enabled := true
+	getAppOut, err := h.Config.AppService.GetApp(r.Context(), appsvc.InputGetApp{
+		ClientID: query.ClientID,
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		return
+	}
+
+	app = getAppOut.App
+	return
+}
+
+func decodeMembers(r *http.Request) (members topicsvc.InMembers, err error) {
+	if r.Body == nil {
+		err = fmt.Errorf("request body is nil")
+		return
+	}
+
+	defer func() {
+		if _err := r.Body.Close(); _err != nil {
+			ylog.Error(r.Context(), "cannot close request body", ylog.KV("error", _err))
+		}
+	}()
+
+	var reqBody MembersReq
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&reqBody)
+	if err != nil {
+		return
+	}
+
+	members.UserIDs = reqBody.UserIDs
+	for _, recipient := range reqBody.Recipients {
+		members.Recipients = append(members.Recipients, topicsvc.InRecipient(recipient))
+	}
+
+	return
+}
@@ -2,23 +2,226 @@ package httptyped
 
 import (
 	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+	"net/http"
 	"time"
 )
 
 type AppEntity struct {
+	ID              int64             `json:"id"`
+	ClientID        string            `json:"client_id"`
+	Name            string            `json:"name"`
+	Enabled         bool              `json:"enabled"`
+	SuspendedReason string            `json:"suspended_reason,omitempty"`
+	SuspendedAt     *time.Time        `json:"suspended_at,omitempty"`
+	Settings        AppSettingsEntity `json:"settings"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+func AppEntityFromSvc(app appsvc.App) AppEntity {
+	e := AppEntity{
+		ID:              app.ID,
+		ClientID:        app.ClientID,
+		Name:            app.Name,
+		Enabled:         app.Enabled,
+		SuspendedReason: app.SuspendedReason,
+		Settings:        AppSettingsEntityFromSvc(app.Settings),
+		CreatedAt:       app.CreatedAt,
+		UpdatedAt:       app.UpdatedAt,
+	}
+
+	if !app.SuspendedAt.IsZero() {
+		suspendedAt := app.SuspendedAt
+		e.SuspendedAt = &suspendedAt
+	}
+
+	return e
+}
+
+// AppSettingsEntity DefaultTTLs is in seconds per provider, i.e: {"fcm": 3600}
+type AppSettingsEntity struct {
+	DefaultLabel     string           `json:"default_label,omitempty"`
+	AllowedProviders []string         `json:"allowed_providers,omitempty"`
+	DailySendQuota   int64            `json:"daily_send_quota,omitempty"`
+	DefaultTTLs      map[string]int64 `json:"default_ttls,omitempty"`
+	ContactEmail     string           `json:"contact_email,omitempty"`
+}
+
+func AppSettingsEntityFromSvc(settings appsvc.AppSettings) AppSettingsEntity {
+	e := AppSettingsEntity{
+		DefaultLabel:     settings.DefaultLabel,
+		AllowedProviders: settings.AllowedProviders,
+		DailySendQuota:   settings.DailySendQuota,
+		ContactEmail:     settings.ContactEmail,
+	}
+
+	if len(settings.DefaultTTLs) > 0 {
+		e.DefaultTTLs = make(map[string]int64, len(settings.DefaultTTLs))
+		for provider, ttl := range settings.DefaultTTLs {
+			e.DefaultTTLs[provider] = int64(ttl / time.Second)
+		}
+	}
+
+	return e
+}
+
+func (e AppSettingsEntity) ToSvc() appsvc.AppSettings {
+	settings := appsvc.AppSettings{
+		DefaultLabel:     e.DefaultLabel,
+		AllowedProviders: e.AllowedProviders,
+		DailySendQuota:   e.DailySendQuota,
+		ContactEmail:     e.ContactEmail,
+	}
+
+	if len(e.DefaultTTLs) > 0 {
+		settings.DefaultTTLs = make(map[string]time.Duration, len(e.DefaultTTLs))
+		for provider, ttl := range e.DefaultTTLs {
+			settings.DefaultTTLs[provider] = time.Duration(ttl) * time.Second
+		}
+	}
+
+	return settings
+}
+
+// PageLinks is relative URL of the next and previous page, empty when there is no more page.
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// PageLinksFromReq return the request URL with the cursor query replaced, so the other query (filter, sort, etc) is kept.
+func PageLinksFromReq(r *http.Request, nextCursor, prevCursor string) PageLinks {
+	link := func(cursor string) string {
+		if cursor == "" {
+			return ""
+		}
+
+		query := r.URL.Query()
+		query.Set("cursor", cursor)
+		return r.URL.Path + "?" + query.Encode()
+	}
+
+	return PageLinks{
+		Next: link(nextCursor),
+		Prev: link(prevCursor),
+	}
+}
+
+type TemplateEntity struct {
+	ID            int64                     `json:"id"`
+	Name          string                    `json:"name"`
+	Engine        string                    `json:"engine"`
+	DefaultLocale string                    `json:"default_locale"`
+	Variants      map[string]map[string]any `json:"variants"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+}
+
+func TemplateEntityFromSvc(t templatesvc.Template) TemplateEntity {
+	return TemplateEntity{
+		ID:            t.ID,
+		Name:          t.Name,
+		Engine:        t.Engine,
+		DefaultLocale: t.DefaultLocale,
+		Variants:      t.Variants,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+}
+
+type DeviceEntity struct {
+	ID         int64     `json:"id"`
+	UserID     string    `json:"user_id"`
+	Provider   string    `json:"provider"`
+	Token      string    `json:"token"`
+	Platform   string    `json:"platform"`
+	Locale     string    `json:"locale"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func DeviceEntityFromSvc(d devicesvc.Device) DeviceEntity {
+	return DeviceEntity{
+		ID:         d.ID,
+		UserID:     d.UserID,
+		Provider:   d.Provider,
+		Token:      d.Token,
+		Platform:   d.Platform,
+		Locale:     d.Locale,
+		LastSeenAt: d.LastSeenAt,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+type TopicMemberEntity struct {
+	Topic      string    `json:"topic"`
+	MemberType string    `json:"member_type"`
+	Provider   string    `json:"provider,omitempty"`
+	Member     string    `json:"member"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func TopicMemberEntityFromSvc(m topicsvc.Member) TopicMemberEntity {
+	return TopicMemberEntity{
+		Topic:      m.Topic,
+		MemberType: m.MemberType,
+		Provider:   m.Provider,
+		Member:     m.Member,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// CallbackEntity never contains the secret, it is only shown once when the callback is created.
+type CallbackEntity struct {
 	ID        int64     `json:"id"`
-	ClientID  string    `json:"client_id"`
-	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func AppEntityFromSvc(app appsvc.App) AppEntity {
-	return AppEntity{
-		ID:        app.ID,
-		ClientID:  app.ClientID,
-		Name:      app.Name,
-		CreatedAt: app.CreatedAt,
-		UpdatedAt: app.UpdatedAt,
+func CallbackEntityFromSvc(c callbacksvc.Callback) CallbackEntity {
+	return CallbackEntity{
+		ID:        c.ID,
+		URL:       c.URL,
+		Events:    c.Events,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+type CallbackDeliveryEntity struct {
+	ID           int64     `json:"id"`
+	CallbackID   int64     `json:"callback_id"`
+	Event        string    `json:"event"`
+	TaskID       string    `json:"task_id"`
+	Payload      string    `json:"payload"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	ResponseCode int       `json:"response_code"`
+	LastError    string    `json:"last_error"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func CallbackDeliveryEntityFromSvc(d callbacksvc.Delivery) CallbackDeliveryEntity {
+	return CallbackDeliveryEntity{
+		ID:           d.ID,
+		CallbackID:   d.CallbackID,
+		Event:        d.Event,
+		TaskID:       d.TaskID,
+		Payload:      d.Payload,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		LastError:    d.LastError,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
 	}
 }
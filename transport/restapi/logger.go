@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/satori/uuid"
+	"github.com/yusufsyaifudin/ngendika/pkg/httplog"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"go.uber.org/multierr"
 	"io"
@@ -27,7 +28,9 @@ func toSimpleMap(h http.Header) map[string]string {
 	return out
 }
 
-func requestLogger(skipFunc func(r *http.Request) bool, next http.Handler) http.HandlerFunc {
+// requestLogger log the request and response body, except when streamFunc return true:
+// the response is written directly to the client and only the response header is logged.
+func requestLogger(skipFunc, streamFunc func(r *http.Request) bool, next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		if skipFunc(r) {
@@ -42,8 +45,13 @@ func requestLogger(skipFunc func(r *http.Request) bool, next http.Handler) http.
 			ctx = context.Background()
 		}
 
-		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		defer cancel()
+		// streamed response is written as long as the process runs, so it is only bounded by the client connection
+		stream := streamFunc(r)
+		if !stream {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+		}
 
 		traceID := uuid.NewV4().String()
 
@@ -95,6 +103,32 @@ func requestLogger(skipFunc func(r *http.Request) bool, next http.Handler) http.
 			reqBodyStr = "" // set to empty string if valid json payload
 		}
 
+		if stream {
+			sw := &httplog.StatusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			errStr := ""
+			if globalErr != nil {
+				errStr = globalErr.Error()
+			}
+
+			ylog.Access(ctx, ylog.AccessLogData{
+				Path: r.RequestURI,
+				Request: ylog.HTTPData{
+					Header:     toSimpleMap(r.Header),
+					DataObject: reqBodyObj,
+					DataString: reqBodyStr,
+				},
+				Response: ylog.HTTPData{
+					Header:     toSimpleMap(w.Header()),
+					DataString: fmt.Sprintf("streamed response with status %d is not logged", sw.Code()),
+				},
+				Error:       errStr,
+				ElapsedTime: time.Since(t1).Milliseconds(),
+			})
+			return
+		}
+
 		// continue serve, and record the response
 		rec := httptest.NewRecorder()
 		next.ServeHTTP(rec, r)
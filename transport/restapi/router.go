@@ -7,26 +7,46 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/yusufsyaifudin/ngendika/assets"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/appsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/callbacksvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/devicesvc"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/msgsvc"
 	"github.com/yusufsyaifudin/ngendika/internal/svc/pnpsvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/templatesvc"
+	"github.com/yusufsyaifudin/ngendika/internal/svc/topicsvc"
+	"github.com/yusufsyaifudin/ngendika/pkg/apikey"
+	"github.com/yusufsyaifudin/ngendika/pkg/health"
+	"github.com/yusufsyaifudin/ngendika/pkg/metric"
 	"github.com/yusufsyaifudin/ngendika/pkg/tracer"
 	"github.com/yusufsyaifudin/ngendika/pkg/validator"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlerapp"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlercallback"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlerdevice"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlerhealth"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlermsg"
 	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlerpnp"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlertemplate"
+	"github.com/yusufsyaifudin/ngendika/transport/restapi/handlertopic"
 	"go.opentelemetry.io/otel"
 	"io/fs"
 	"net/http"
 	"path"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	AppServiceName string         `validate:"required"`
-	AppVersion     string         `validate:"required"`
-	AppService     appsvc.Service `validate:"required"`
-	PNPService     pnpsvc.Service `validate:"required"`
-	MsgService     msgsvc.Service `validate:"required"`
+	AppServiceName  string              `validate:"required"`
+	AppVersion      string              `validate:"required"`
+	AppService      appsvc.Service      `validate:"required"`
+	PNPService      pnpsvc.Service      `validate:"required"`
+	TemplateService templatesvc.Service `validate:"required"`
+	DeviceService   devicesvc.Service   `validate:"required"`
+	TopicService    topicsvc.Service    `validate:"required"`
+	CallbackService callbacksvc.Service `validate:"required"`
+	MsgService      msgsvc.Service      `validate:"required"`
+	Health          *health.Health      `validate:"required"`
+	APIKeys         []apikey.APIKey     `validate:"dive"`  // empty means no auth
+	StreamTimeout   time.Duration       `validate:"min=0"` // deadline of message stream, default handlermsg.DefaultStreamTimeout
 }
 
 type DefaultHTTP struct {
@@ -58,9 +78,46 @@ func NewHTTPTransport(cfg Config) (*DefaultHTTP, error) {
 		return nil, err
 	}
 
+	// ** Message template handler
+	handlerTemplate, err := handlertemplate.NewHandler(handlertemplate.HandlerConfig{
+		AppService:      cfg.AppService,
+		TemplateService: cfg.TemplateService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ** Device registry handler
+	handlerDevice, err := handlerdevice.NewHandler(handlerdevice.HandlerConfig{
+		AppService:    cfg.AppService,
+		DeviceService: cfg.DeviceService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ** Topic subscription handler
+	handlerTopic, err := handlertopic.NewHandler(handlertopic.HandlerConfig{
+		AppService:   cfg.AppService,
+		TopicService: cfg.TopicService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ** Callback handler
+	handlerCallback, err := handlercallback.NewHandler(handlercallback.HandlerConfig{
+		AppService:      cfg.AppService,
+		CallbackService: cfg.CallbackService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// ** Messaging service handler
 	handlerMsgCfg := handlermsg.HandlerConfig{
 		MsgServiceProcessor: cfg.MsgService,
+		StreamTimeout:       cfg.StreamTimeout,
 	}
 	handlerMessage, err := handlermsg.NewHandler(handlerMsgCfg)
 
@@ -68,19 +125,35 @@ func NewHTTPTransport(cfg Config) (*DefaultHTTP, error) {
 		return nil, err
 	}
 
+	// ** Health check handler
+	handlerHealth, err := handlerhealth.NewHandler(handlerhealth.HandlerConfig{
+		Health: cfg.Health,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	auth := newAuthenticator(cfg.APIKeys)
+
 	router := chi.NewRouter()
 
 	skip := func(r *http.Request) bool {
 		switch strings.TrimSpace(path.Clean(r.URL.Path)) {
 		case "/swaggerui",
-			"/health",
-			"/ping":
+			"/health/live",
+			"/health/ready",
+			"/metrics":
 			return true
 		}
 
 		return false
 	}
 
+	// streaming response must be flushed directly, so it is not buffered by the tracer and logger middleware
+	stream := func(r *http.Request) bool {
+		return strings.TrimSpace(path.Clean(r.URL.Path)) == "/api/v1/messages/stream"
+	}
+
 	router.Use(middleware.StripSlashes)
 
 	router.Use(cors.Handler(cors.Options{
@@ -88,7 +161,7 @@ func NewHTTPTransport(cfg Config) (*DefaultHTTP, error) {
 		AllowedOrigins: []string{"https://*", "http://*"},
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
@@ -99,6 +172,7 @@ func NewHTTPTransport(cfg Config) (*DefaultHTTP, error) {
 			TracerName:     "github.com/yusufsyaifudin/ngendika",
 			ServiceName:    assets.ServiceName,
 			SkipFunc:       skip,
+			StreamFunc:     stream,
 			TracerProvider: otel.GetTracerProvider(),    // global tracer provider
 			TextPropagator: otel.GetTextMapPropagator(), // use global text map propagator
 		}, next)
@@ -106,7 +180,7 @@ func NewHTTPTransport(cfg Config) (*DefaultHTTP, error) {
 
 	// add trace id and also log request response
 	router.Use(func(next http.Handler) http.Handler {
-		return requestLogger(skip, next)
+		return requestLogger(skip, stream, next)
 	})
 
 	todoHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -114,31 +188,74 @@ func NewHTTPTransport(cfg Config) (*DefaultHTTP, error) {
 		_, _ = w.Write([]byte(`{"todo": true}`))
 	}
 
+	// health check for orchestrator probes, i.e: kubernetes liveness and readiness probe
+	router.Get("/health/live", handlerHealth.Live())
+	router.Get("/health/ready", handlerHealth.Ready())
+
+	// prometheus metrics
+	router.Handle("/metrics", metric.Handler())
+
 	swaggerDir, _ := fs.Sub(assets.SwaggerUI, ".")
 	router.Mount("/", http.FileServer(http.FS(swaggerDir)))
 
 	// Resource: apps
 	router.Route("/api/v1/apps", func(r chi.Router) {
-		r.Post("/", handlerApp.CreateApp())                     // create apps
-		r.Get("/", handlerApp.ListApps())                       // list of apps
-		r.Get("/{client_id}", handlerApp.GetByClientID())       // list of apps
-		r.Put("/{client_id}", handlerApp.PutApp())              // replace all existing field in apps (does not support patching)
-		r.Delete("/{client_id}", handlerApp.DelAppByClientID()) // delete apps
+		r.With(auth.Require(apikey.ScopeAppsWrite)).Post("/", handlerApp.CreateApp())                               // create apps
+		r.With(auth.Require(apikey.ScopeAppsRead)).Get("/", handlerApp.ListApps())                                  // list of apps
+		r.With(auth.Require(apikey.ScopeAppsRead)).Get("/{client_id}", handlerApp.GetByClientID())                  // list of apps
+		r.With(auth.Require(apikey.ScopeAppsWrite)).Put("/{client_id}", handlerApp.PutApp())                        // replace all existing field in apps (does not support patching)
+		r.With(auth.Require(apikey.ScopeAppsAdmin)).Delete("/{client_id}", handlerApp.DelAppByClientID())           // delete apps
+		r.With(auth.Require(apikey.ScopeAppsAdmin)).Post("/{client_id}/restore", handlerApp.RestoreAppByClientID()) // restore deleted apps within retention
+		r.With(auth.Require(apikey.ScopeAppsAdmin)).Post("/{client_id}/suspend", handlerApp.SuspendApp())           // suspend apps with reason, message is refused
+		r.With(auth.Require(apikey.ScopeAppsAdmin)).Post("/{client_id}/resume", handlerApp.ResumeApp())             // resume suspended apps
+		r.With(auth.Require(apikey.ScopeAppsAdmin)).Put("/{client_id}/settings", handlerApp.PutAppSettings())       // replace all app settings, including quota
 	})
 
 	// Resource: service providers
 	router.Route("/api/v1/pnp", func(r chi.Router) {
-		r.Post("/", handlSvcProvider.Create())                        // create new
-		r.Put("/{label}", todoHandler)                                // create or replace entirely
-		r.Get("/examples", handlSvcProvider.Examples())               // create or replace entirely
-		r.Get("/list/by-provider", handlSvcProvider.ListByProvider()) // get list under this client_id
-		r.Get("/{label}", todoHandler)                                // get one
-		r.Delete("/{label}", todoHandler)                             // delete one
+		r.With(auth.Require(apikey.ScopePnpWrite)).Post("/", handlSvcProvider.Create())                       // create new
+		r.With(auth.Require(apikey.ScopePnpWrite)).Put("/{label}", todoHandler)                               // create or replace entirely
+		r.With(auth.Require(apikey.ScopePnpRead)).Get("/examples", handlSvcProvider.Examples())               // create or replace entirely
+		r.With(auth.Require(apikey.ScopePnpRead)).Get("/list/by-provider", handlSvcProvider.ListByProvider()) // get list under this client_id
+		r.With(auth.Require(apikey.ScopePnpRead)).Get("/{label}", todoHandler)                                // get one
+		r.With(auth.Require(apikey.ScopePnpWrite)).Delete("/{label}", todoHandler)                            // delete one
+	})
+
+	// Resource: message templates
+	router.Route("/api/v1/templates", func(r chi.Router) {
+		r.With(auth.Require(apikey.ScopeTemplatesWrite)).Post("/", handlerTemplate.Create())                // create new
+		r.With(auth.Require(apikey.ScopeTemplatesRead)).Get("/", handlerTemplate.List())                    // list under client_id
+		r.With(auth.Require(apikey.ScopeTemplatesRead)).Get("/{template_id}", handlerTemplate.Get())        // get one
+		r.With(auth.Require(apikey.ScopeTemplatesWrite)).Put("/{template_id}", handlerTemplate.Update())    // replace entirely
+		r.With(auth.Require(apikey.ScopeTemplatesWrite)).Delete("/{template_id}", handlerTemplate.Delete()) // delete one
+	})
+
+	// Resource: devices
+	router.Route("/api/v1/devices", func(r chi.Router) {
+		r.With(auth.Require(apikey.ScopeDevicesWrite)).Post("/", handlerDevice.Register())     // register device token to user
+		r.With(auth.Require(apikey.ScopeDevicesRead)).Get("/", handlerDevice.ListByUser())     // list devices of user
+		r.With(auth.Require(apikey.ScopeDevicesWrite)).Delete("/", handlerDevice.Unregister()) // unregister device token
+	})
+
+	// Resource: topics
+	router.Route("/api/v1/topics/{topic}", func(r chi.Router) {
+		r.With(auth.Require(apikey.ScopeTopicsWrite)).Post("/subscribe", handlerTopic.Subscribe())     // subscribe members
+		r.With(auth.Require(apikey.ScopeTopicsWrite)).Post("/unsubscribe", handlerTopic.Unsubscribe()) // unsubscribe members
+		r.With(auth.Require(apikey.ScopeTopicsRead)).Get("/members", handlerTopic.ListMembers())       // list members
+	})
+
+	// Resource: callbacks
+	router.Route("/api/v1/callbacks", func(r chi.Router) {
+		r.With(auth.Require(apikey.ScopeCallbacksWrite)).Post("/", handlerCallback.Register())               // register callback url
+		r.With(auth.Require(apikey.ScopeCallbacksRead)).Get("/", handlerCallback.List())                     // list under client_id
+		r.With(auth.Require(apikey.ScopeCallbacksRead)).Get("/deliveries", handlerCallback.ListDeliveries()) // delivery log
+		r.With(auth.Require(apikey.ScopeCallbacksWrite)).Delete("/{callback_id}", handlerCallback.Delete())  // delete one
 	})
 
 	// Resource: messages
 	router.Route("/api/v1/messages", func(r chi.Router) {
-		r.Post("/", handlerMessage.SendMessage()) // send message
+		r.With(auth.Require(apikey.ScopeMessagesSend)).Post("/", handlerMessage.SendMessage())             // send message
+		r.With(auth.Require(apikey.ScopeMessagesSend)).Post("/stream", handlerMessage.SendMessageStream()) // send message and stream the progress
 	})
 
 	instance := &DefaultHTTP{